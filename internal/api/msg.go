@@ -15,6 +15,10 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
+
 	"github.com/OpenIMSDK/tools/mcontext"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -34,6 +38,7 @@ import (
 
 	"github.com/openimsdk/open-im-server/v3/pkg/apistruct"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
 )
 
 type MessageApi struct {
@@ -127,6 +132,90 @@ func (m *MessageApi) RevokeMsg(c *gin.Context) {
 	a2r.Call(msg.MsgClient.RevokeMsg, m.Client, c)
 }
 
+func (m *MessageApi) EditMsg(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.EditMsg, m.ExtClient, c)
+}
+
+func (m *MessageApi) AddMsgReaction(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.AddMsgReaction, m.ExtClient, c)
+}
+
+func (m *MessageApi) DeleteMsgReaction(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.DeleteMsgReaction, m.ExtClient, c)
+}
+
+func (m *MessageApi) GetMsgReactions(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.GetMsgReactions, m.ExtClient, c)
+}
+
+func (m *MessageApi) SendThreadReply(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.SendThreadReply, m.ExtClient, c)
+}
+
+func (m *MessageApi) PullThreadMsgs(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.PullThreadMsgs, m.ExtClient, c)
+}
+
+func (m *MessageApi) GetThreadSummaries(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.GetThreadSummaries, m.ExtClient, c)
+}
+
+func (m *MessageApi) PinMsg(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.PinMsg, m.ExtClient, c)
+}
+
+func (m *MessageApi) UnpinMsg(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.UnpinMsg, m.ExtClient, c)
+}
+
+func (m *MessageApi) GetPinnedMsgs(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.GetPinnedMsgs, m.ExtClient, c)
+}
+
+func (m *MessageApi) GetMsgReaders(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.GetMsgReaders, m.ExtClient, c)
+}
+
+func (m *MessageApi) GetMsgUnreadMembers(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.GetMsgUnreadMembers, m.ExtClient, c)
+}
+
+func (m *MessageApi) ForwardMsgs(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.ForwardMsgs, m.ExtClient, c)
+}
+
+func (m *MessageApi) TranslateMsg(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.TranslateMsg, m.ExtClient, c)
+}
+
+func (m *MessageApi) AddSensitiveWords(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.AddSensitiveWords, m.ExtClient, c)
+}
+
+func (m *MessageApi) DeleteSensitiveWords(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.DeleteSensitiveWords, m.ExtClient, c)
+}
+
+func (m *MessageApi) SearchSensitiveWords(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.SearchSensitiveWords, m.ExtClient, c)
+}
+
+func (m *MessageApi) ScheduleMsg(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.ScheduleMsg, m.ExtClient, c)
+}
+
+func (m *MessageApi) GetScheduledMsgs(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.GetScheduledMsgs, m.ExtClient, c)
+}
+
+func (m *MessageApi) CancelScheduledMsg(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.CancelScheduledMsg, m.ExtClient, c)
+}
+
+func (m *MessageApi) RescheduleMsg(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.RescheduleMsg, m.ExtClient, c)
+}
+
 func (m *MessageApi) MarkMsgsAsRead(c *gin.Context) {
 	a2r.Call(msg.MsgClient.MarkMsgsAsRead, m.Client, c)
 }
@@ -220,6 +309,15 @@ func (m *MessageApi) SendMessage(c *gin.Context) {
 		return
 	}
 	sendMsgReq.MsgData.RecvID = req.RecvID
+	if req.SendTime != 0 {
+		resp, err := m.ExtClient.ScheduleMsg(c, &msgext.ScheduleMsgReq{SendTime: req.SendTime, MsgData: sendMsgReq.MsgData})
+		if err != nil {
+			apiresp.GinError(c, err)
+			return
+		}
+		apiresp.GinSuccess(c, resp)
+		return
+	}
 	var status int
 	respPb, err := m.Client.SendMsg(c, sendMsgReq)
 	if err != nil {
@@ -359,8 +457,24 @@ func (m *MessageApi) GetActiveGroup(c *gin.Context) {
 	a2r.Call(msg.MsgClient.GetActiveGroup, m.Client, c)
 }
 
+// SearchMsg runs a keyword search over the user's own conversations when the
+// request has a keyword, otherwise the admin search by sender, receiver and time.
 func (m *MessageApi) SearchMsg(c *gin.Context) {
-	a2r.Call(msg.MsgClient.SearchMessage, m.Client, c)
+	body, err := c.GetRawData()
+	if err != nil {
+		apiresp.GinError(c, errs.ErrArgs.WithDetail(err.Error()).Wrap())
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	var keyword struct {
+		Keyword string `json:"keyword"`
+	}
+	_ = json.Unmarshal(body, &keyword)
+	if keyword.Keyword == "" {
+		a2r.Call(msg.MsgClient.SearchMessage, m.Client, c)
+		return
+	}
+	a2r.Call(msgext.MsgExtClient.SearchMsg, m.ExtClient, c)
 }
 
 func (m *MessageApi) GetServerTime(c *gin.Context) {
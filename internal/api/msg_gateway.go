@@ -0,0 +1,145 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"github.com/OpenIMSDK/tools/apiresp"
+	"github.com/OpenIMSDK/tools/discoveryregistry"
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/mw/specialerror"
+	"github.com/gin-gonic/gin"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/gatewayext"
+)
+
+type MsgGatewayApi struct {
+	discov discoveryregistry.SvcDiscoveryRegistry
+}
+
+func NewMsgGatewayApi(discov discoveryregistry.SvcDiscoveryRegistry) MsgGatewayApi {
+	return MsgGatewayApi{discov: discov}
+}
+
+// Drain asks one msg gateway node to move its connections to the other nodes and exit.
+func (m *MsgGatewayApi) Drain(c *gin.Context) {
+	var req gatewayext.DrainReq
+	if err := c.BindJSON(&req); err != nil {
+		apiresp.GinError(c, errs.ErrArgs.WithDetail(err.Error()).Wrap())
+		return
+	}
+	if err := req.Check(); err != nil {
+		apiresp.GinError(c, errs.ErrArgs.Wrap(err.Error()))
+		return
+	}
+	conns, err := m.discov.GetConns(c, config.Config.RpcRegisterName.OpenImMessageGatewayName)
+	if err != nil {
+		apiresp.GinError(c, err)
+		return
+	}
+	for _, conn := range conns {
+		if conn.Target() != req.Node {
+			continue
+		}
+		resp, err := gatewayext.NewGatewayExtClient(conn).Drain(c, &req)
+		if err != nil {
+			apiresp.GinError(c, err)
+			return
+		}
+		apiresp.GinSuccess(c, resp)
+		return
+	}
+	apiresp.GinError(c, errs.ErrRecordNotFound.Wrap("msg gateway node not found "+req.Node))
+}
+
+// GetUserConns lists the connections of the users on every msg gateway node.
+func (m *MsgGatewayApi) GetUserConns(c *gin.Context) {
+	var req gatewayext.GetUserConnsReq
+	if err := c.BindJSON(&req); err != nil {
+		apiresp.GinError(c, errs.ErrArgs.WithDetail(err.Error()).Wrap())
+		return
+	}
+	if err := req.Check(); err != nil {
+		apiresp.GinError(c, errs.ErrArgs.Wrap(err.Error()))
+		return
+	}
+	conns, err := m.discov.GetConns(c, config.Config.RpcRegisterName.OpenImMessageGatewayName)
+	if err != nil {
+		apiresp.GinError(c, err)
+		return
+	}
+	resp := gatewayext.GetUserConnsResp{Conns: []*gatewayext.ConnInfo{}}
+	for _, conn := range conns {
+		nodeResp, err := gatewayext.NewGatewayExtClient(conn).GetUserConns(c, &req)
+		if err != nil {
+			apiresp.GinError(c, err)
+			return
+		}
+		resp.Conns = append(resp.Conns, nodeResp.Conns...)
+	}
+	apiresp.GinSuccess(c, &resp)
+}
+
+// GetConnCount returns the number of online users and connections per msg gateway node.
+func (m *MsgGatewayApi) GetConnCount(c *gin.Context) {
+	conns, err := m.discov.GetConns(c, config.Config.RpcRegisterName.OpenImMessageGatewayName)
+	if err != nil {
+		apiresp.GinError(c, err)
+		return
+	}
+	resp := gatewayext.GetClusterConnCountResp{Nodes: []*gatewayext.GetConnCountResp{}}
+	for _, conn := range conns {
+		nodeResp, err := gatewayext.NewGatewayExtClient(conn).GetConnCount(c, &gatewayext.GetConnCountReq{})
+		if err != nil {
+			apiresp.GinError(c, err)
+			return
+		}
+		resp.UserNum += nodeResp.UserNum
+		resp.ConnNum += nodeResp.ConnNum
+		resp.Nodes = append(resp.Nodes, nodeResp)
+	}
+	apiresp.GinSuccess(c, &resp)
+}
+
+// CloseConn closes a single connection on whichever msg gateway node holds it.
+func (m *MsgGatewayApi) CloseConn(c *gin.Context) {
+	var req gatewayext.CloseConnReq
+	if err := c.BindJSON(&req); err != nil {
+		apiresp.GinError(c, errs.ErrArgs.WithDetail(err.Error()).Wrap())
+		return
+	}
+	if err := req.Check(); err != nil {
+		apiresp.GinError(c, errs.ErrArgs.Wrap(err.Error()))
+		return
+	}
+	conns, err := m.discov.GetConns(c, config.Config.RpcRegisterName.OpenImMessageGatewayName)
+	if err != nil {
+		apiresp.GinError(c, err)
+		return
+	}
+	for _, conn := range conns {
+		resp, err := gatewayext.NewGatewayExtClient(conn).CloseConn(c, &req)
+		if err != nil {
+			if errs.ErrRecordNotFound.Is(specialerror.ErrCode(errs.Unwrap(err))) {
+				continue
+			}
+			apiresp.GinError(c, err)
+			return
+		}
+		apiresp.GinSuccess(c, resp)
+		return
+	}
+	apiresp.GinError(c, errs.ErrRecordNotFound.Wrap("conn not found "+req.ConnID))
+}
@@ -0,0 +1,61 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"github.com/OpenIMSDK/tools/a2r"
+	"github.com/gin-gonic/gin"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/pushext"
+)
+
+type PushApi rpcclient.Push
+
+func NewPushApi(client rpcclient.Push) PushApi {
+	return PushApi(client)
+}
+
+func (o *PushApi) GetPushDeadLetters(c *gin.Context) {
+	a2r.Call(pushext.PushExtClient.GetPushDeadLetters, o.ExtClient, c)
+}
+
+func (o *PushApi) ReplayPushDeadLetters(c *gin.Context) {
+	a2r.Call(pushext.PushExtClient.ReplayPushDeadLetters, o.ExtClient, c)
+}
+
+func (o *PushApi) DeletePushDeadLetters(c *gin.Context) {
+	a2r.Call(pushext.PushExtClient.DeletePushDeadLetters, o.ExtClient, c)
+}
+
+func (o *PushApi) SetPushTemplates(c *gin.Context) {
+	a2r.Call(pushext.PushExtClient.SetPushTemplates, o.ExtClient, c)
+}
+
+func (o *PushApi) GetPushTemplates(c *gin.Context) {
+	a2r.Call(pushext.PushExtClient.GetPushTemplates, o.ExtClient, c)
+}
+
+func (o *PushApi) DeletePushTemplates(c *gin.Context) {
+	a2r.Call(pushext.PushExtClient.DeletePushTemplates, o.ExtClient, c)
+}
+
+func (o *PushApi) SetUserPushSetting(c *gin.Context) {
+	a2r.Call(pushext.PushExtClient.SetUserPushSetting, o.ExtClient, c)
+}
+
+func (o *PushApi) GetUserPushSetting(c *gin.Context) {
+	a2r.Call(pushext.PushExtClient.GetUserPushSetting, o.ExtClient, c)
+}
@@ -60,6 +60,7 @@ func NewGinRouter(discov discoveryregistry.SvcDiscoveryRegistry, rdb redis.Unive
 	conversationRpc := rpcclient.NewConversation(discov)
 	authRpc := rpcclient.NewAuth(discov)
 	thirdRpc := rpcclient.NewThird(discov)
+	pushRpc := rpcclient.NewPush(discov)
 
 	u := NewUserApi(*userRpc)
 	m := NewMessageApi(messageRpc, userRpc)
@@ -154,6 +155,9 @@ func NewGinRouter(discov discoveryregistry.SvcDiscoveryRegistry, rdb redis.Unive
 	{
 		t := NewThirdApi(*thirdRpc)
 		thirdGroup.POST("/fcm_update_token", t.FcmUpdateToken)
+		thirdGroup.POST("/apns_update_token", t.ApnsUpdateToken)
+		thirdGroup.POST("/register_push_device", t.RegisterPushDevice)
+		thirdGroup.POST("/unregister_push_device", t.UnregisterPushDevice)
 		thirdGroup.POST("/set_app_badge", t.SetAppBadge)
 
 		objectGroup := r.Group("/object", ParseToken)
@@ -166,6 +170,19 @@ func NewGinRouter(discov discoveryregistry.SvcDiscoveryRegistry, rdb redis.Unive
 		objectGroup.POST("/access_url", t.AccessURL)
 		objectGroup.GET("/*name", t.ObjectRedirect)
 	}
+	// Push
+	pushGroup := r.Group("/push", ParseToken)
+	{
+		p := NewPushApi(*pushRpc)
+		pushGroup.POST("/get_dead_letters", p.GetPushDeadLetters)
+		pushGroup.POST("/replay_dead_letters", p.ReplayPushDeadLetters)
+		pushGroup.POST("/delete_dead_letters", p.DeletePushDeadLetters)
+		pushGroup.POST("/set_templates", p.SetPushTemplates)
+		pushGroup.POST("/get_templates", p.GetPushTemplates)
+		pushGroup.POST("/delete_templates", p.DeletePushTemplates)
+		pushGroup.POST("/set_user_setting", p.SetUserPushSetting)
+		pushGroup.POST("/get_user_setting", p.GetUserPushSetting)
+	}
 	// Message
 	msgGroup := r.Group("/msg", ParseToken)
 	{
@@ -175,6 +192,27 @@ func NewGinRouter(discov discoveryregistry.SvcDiscoveryRegistry, rdb redis.Unive
 		msgGroup.POST("/send_business_notification", m.SendBusinessNotification)
 		msgGroup.POST("/pull_msg_by_seq", m.PullMsgBySeqs)
 		msgGroup.POST("/revoke_msg", m.RevokeMsg)
+		msgGroup.POST("/edit_msg", m.EditMsg)
+		msgGroup.POST("/add_msg_reaction", m.AddMsgReaction)
+		msgGroup.POST("/delete_msg_reaction", m.DeleteMsgReaction)
+		msgGroup.POST("/get_msg_reactions", m.GetMsgReactions)
+		msgGroup.POST("/send_thread_reply", m.SendThreadReply)
+		msgGroup.POST("/pull_thread_msgs", m.PullThreadMsgs)
+		msgGroup.POST("/get_thread_summaries", m.GetThreadSummaries)
+		msgGroup.POST("/pin_msg", m.PinMsg)
+		msgGroup.POST("/unpin_msg", m.UnpinMsg)
+		msgGroup.POST("/get_pinned_msgs", m.GetPinnedMsgs)
+		msgGroup.POST("/get_msg_readers", m.GetMsgReaders)
+		msgGroup.POST("/get_msg_unread_members", m.GetMsgUnreadMembers)
+		msgGroup.POST("/forward_msgs", m.ForwardMsgs)
+		msgGroup.POST("/translate_msg", m.TranslateMsg)
+		msgGroup.POST("/add_sensitive_words", m.AddSensitiveWords)
+		msgGroup.POST("/delete_sensitive_words", m.DeleteSensitiveWords)
+		msgGroup.POST("/search_sensitive_words", m.SearchSensitiveWords)
+		msgGroup.POST("/schedule_msg", m.ScheduleMsg)
+		msgGroup.POST("/get_scheduled_msgs", m.GetScheduledMsgs)
+		msgGroup.POST("/cancel_scheduled_msg", m.CancelScheduledMsg)
+		msgGroup.POST("/reschedule_msg", m.RescheduleMsg)
 		msgGroup.POST("/mark_msgs_as_read", m.MarkMsgsAsRead)
 		msgGroup.POST("/mark_conversation_as_read", m.MarkConversationAsRead)
 		msgGroup.POST("/get_conversations_has_read_and_max_seq", m.GetConversationsHasReadAndMaxSeq)
@@ -201,6 +239,15 @@ func NewGinRouter(discov discoveryregistry.SvcDiscoveryRegistry, rdb redis.Unive
 		conversationGroup.POST("/get_conversation_offline_push_user_ids", c.GetConversationOfflinePushUserIDs)
 	}
 
+	msgGatewayGroup := r.Group("/msg_gateway", ParseToken)
+	{
+		gw := NewMsgGatewayApi(discov)
+		msgGatewayGroup.POST("/drain", gw.Drain)
+		msgGatewayGroup.POST("/get_user_conns", gw.GetUserConns)
+		msgGatewayGroup.POST("/get_conn_count", gw.GetConnCount)
+		msgGatewayGroup.POST("/close_conn", gw.CloseConn)
+	}
+
 	statisticsGroup := r.Group("/statistics", ParseToken)
 	{
 		statisticsGroup.POST("/user/register", u.UserRegisterCount)
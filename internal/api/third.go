@@ -27,6 +27,7 @@ import (
 	"github.com/OpenIMSDK/tools/mcontext"
 
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/thirdext"
 )
 
 type ThirdApi rpcclient.Third
@@ -39,6 +40,18 @@ func (o *ThirdApi) FcmUpdateToken(c *gin.Context) {
 	a2r.Call(third.ThirdClient.FcmUpdateToken, o.Client, c)
 }
 
+func (o *ThirdApi) ApnsUpdateToken(c *gin.Context) {
+	a2r.Call(thirdext.ThirdExtClient.ApnsUpdateToken, o.ExtClient, c)
+}
+
+func (o *ThirdApi) RegisterPushDevice(c *gin.Context) {
+	a2r.Call(thirdext.ThirdExtClient.RegisterPushDevice, o.ExtClient, c)
+}
+
+func (o *ThirdApi) UnregisterPushDevice(c *gin.Context) {
+	a2r.Call(thirdext.ThirdExtClient.UnregisterPushDevice, o.ExtClient, c)
+}
+
 func (o *ThirdApi) SetAppBadge(c *gin.Context) {
 	a2r.Call(third.ThirdClient.SetAppBadge, o.Client, c)
 }
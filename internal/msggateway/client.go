@@ -20,10 +20,14 @@ import (
 	"fmt"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/openimsdk/open-im-server/v3/pkg/common/prome"
 	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/gatewayext"
 
-	"google.golang.org/protobuf/proto"
+	"golang.org/x/time/rate"
 
 	"github.com/OpenIMSDK/protocol/constant"
 	"github.com/OpenIMSDK/protocol/sdkws"
@@ -38,6 +42,9 @@ var (
 	ErrNotSupportMessageProtocol = errors.New("not support message protocol")
 	ErrClientClosed              = errors.New("client actively close the connection")
 	ErrPanic                     = errors.New("panic error")
+	ErrIdleTimeout               = errors.New("conn idle timeout")
+	ErrDrained                   = errors.New("msg gateway drained")
+	ErrClosedByAdmin             = errors.New("conn closed by admin")
 )
 
 const (
@@ -73,6 +80,35 @@ type Client struct {
 	closed         bool
 	closedErr      error
 	token          string
+	encoder        Encoder
+	compressor     Compressor
+	// compression and encoding names negotiated by the current conn
+	compression string
+	encoding    string
+	// connectTime unix milli of the first connect, kept across resumes
+	connectTime int64
+	// textFrame json clients without compression exchange text frames
+	textFrame bool
+	// resumeToken lets a reconnecting client take over this client within the resume grace period
+	resumeToken string
+	// detached the conn is gone but the client waits for a resume, pushes go to replay
+	detached bool
+	// dropSession is set on kick and logout, such a client is never resumed
+	dropSession bool
+	// migrating the client was asked to reconnect to another node, its offline status is
+	// only reported if it does not show up there, see WsServer.migrateClient
+	migrating bool
+	replay    *replayBuffer
+	// rateLimiters per ReqIdentifier token buckets of this conn
+	rateLimiters        map[int32]*rate.Limiter
+	rateLimitViolations int
+	// ephemerals the verified conversations this conn sends ephemeral signals to
+	ephemerals map[string]*ephemeralConversation
+	// lastActive unix nano of the last frame or pong read from the conn
+	lastActive   int64
+	pingInterval time.Duration
+	pongWait     time.Duration
+	writeWait    time.Duration
 }
 
 func newClient(ctx *UserConnContext, conn LongConn, isCompress bool) *Client {
@@ -83,20 +119,23 @@ func newClient(ctx *UserConnContext, conn LongConn, isCompress bool) *Client {
 		IsCompress: isCompress,
 		UserID:     ctx.GetUserID(),
 		ctx:        ctx,
+		encoder:    NewGobEncoder(),
+		compressor: NewGzipCompressor(),
 	}
 }
 
 func (c *Client) ResetClient(
 	ctx *UserConnContext,
 	conn LongConn,
-	isBackground, isCompress bool,
+	isBackground bool,
+	compression string,
 	longConnServer LongConnServer,
 	token string,
+	encoding string,
 ) {
 	c.w = new(sync.Mutex)
 	c.conn = conn
 	c.PlatformID = utils.StringToInt(ctx.GetPlatformID())
-	c.IsCompress = isCompress
 	c.IsBackground = isBackground
 	c.UserID = ctx.GetUserID()
 	c.ctx = ctx
@@ -105,30 +144,176 @@ func (c *Client) ResetClient(
 	c.closed = false
 	c.closedErr = nil
 	c.token = token
+	c.connectTime = time.Now().UnixMilli()
+	c.setCodec(compression, encoding)
+	c.resumeToken = ""
+	c.detached = false
+	c.dropSession = false
+	c.migrating = false
+	c.replay = nil
+	c.rateLimiters = nil
+	c.rateLimitViolations = 0
+	c.ephemerals = nil
+	c.pingInterval, c.pongWait, c.writeWait = longConnServer.heartbeat()
+	c.touch()
+}
+
+// touch records that the peer is alive.
+func (c *Client) touch() {
+	atomic.StoreInt64(&c.lastActive, time.Now().UnixNano())
+}
+
+// isStale reports whether nothing was read from an attached conn for longer than idle.
+func (c *Client) isStale(now time.Time, idle time.Duration) bool {
+	c.w.Lock()
+	defer c.w.Unlock()
+	if c.closed || c.detached {
+		return false
+	}
+	return now.Sub(time.Unix(0, atomic.LoadInt64(&c.lastActive))) > idle
+}
+
+// shutdown closes the conn as if its read had failed with err.
+func (c *Client) shutdown(err error) {
+	c.w.Lock()
+	conn := c.conn
+	c.closedErr = err
+	c.w.Unlock()
+	c.close(conn)
+}
+
+// migrate asks the client to reconnect to another node, the session is not resumable here.
+func (c *Client) migrate() error {
+	c.w.Lock()
+	c.dropSession = true
+	c.migrating = true
+	c.w.Unlock()
+	return c.writeBinaryMsg(Resp{ReqIdentifier: WsReconnect})
+}
+
+// closeByAdmin closes the conn for good, the session is not resumable.
+func (c *Client) closeByAdmin() {
+	c.w.Lock()
+	c.dropSession = true
+	c.w.Unlock()
+	c.shutdown(ErrClosedByAdmin)
+}
+
+func (c *Client) setCodec(compression, encoding string) {
+	c.compression, c.encoding = compression, encoding
+	c.compressor = c.longConnServer.GetCompressor(compression)
+	c.IsCompress = c.compressor != nil
+	c.encoder = c.longConnServer.GetEncoder(encoding)
+	c.textFrame = encoding == JsonEncoding && !c.IsCompress
+}
+
+// enableResume issues the first resume token of a new client.
+func (c *Client) enableResume(resumeToken string, bufferSize int) error {
+	c.w.Lock()
+	defer c.w.Unlock()
+	c.resumeToken = resumeToken
+	c.replay = newReplayBuffer(bufferSize)
+	return c.writeResp(Resp{ReqIdentifier: WsResumeToken, Data: []byte(resumeToken)})
+}
+
+// resume attaches a new conn to a detached client, sends the rotated resume token
+// and replays the pushes buffered while detached.
+func (c *Client) resume(ctx *UserConnContext, conn LongConn, compression, encoding, resumeToken string) bool {
+	c.w.Lock()
+	defer c.w.Unlock()
+	if !c.detached || c.dropSession {
+		return false
+	}
+	c.conn = conn
+	c.ctx = ctx
+	c.setCodec(compression, encoding)
+	c.closed = false
+	c.closedErr = nil
+	c.detached = false
+	c.resumeToken = resumeToken
+	c.touch()
+	if err := c.writeResp(Resp{ReqIdentifier: WsResumeToken, Data: []byte(resumeToken)}); err != nil {
+		log.ZWarn(c.ctx, "write resume token", err)
+	}
+	for _, resp := range c.replay.drain() {
+		if err := c.writeResp(resp); err != nil {
+			log.ZWarn(c.ctx, "replay push", err, "operationID", resp.OperationID)
+		}
+	}
+	return true
+}
+
+// expire ends the resume grace period of a detached client.
+func (c *Client) expire() {
+	c.w.Lock()
+	defer c.w.Unlock()
+	c.detached = false
+	c.replay.drain()
+}
+
+func (c *Client) isDetached() bool {
+	c.w.Lock()
+	defer c.w.Unlock()
+	return c.detached
+}
+
+// connID returns the conn id of the current conn, it changes on resume.
+func (c *Client) connID() string {
+	c.w.Lock()
+	defer c.w.Unlock()
+	return c.ctx.GetConnID()
+}
+
+// connInfo describes the client for the admin rpcs.
+func (c *Client) connInfo(node string) *gatewayext.ConnInfo {
+	c.w.Lock()
+	defer c.w.Unlock()
+	return &gatewayext.ConnInfo{
+		UserID:       c.UserID,
+		PlatformID:   int32(c.PlatformID),
+		Platform:     constant.PlatformIDToName(c.PlatformID),
+		RemoteAddr:   c.ctx.GetRemoteAddr(),
+		ConnID:       c.ctx.GetConnID(),
+		ConnectTime:  c.connectTime,
+		IsBackground: c.IsBackground,
+		Compression:  c.compression,
+		Encoding:     c.encoding,
+		Detached:     c.detached,
+		Node:         node,
+	}
 }
 
 func (c *Client) pongHandler(_ string) error {
-	c.conn.SetReadDeadline(pongWait)
+	c.touch()
+	c.conn.SetReadDeadline(c.pongWait)
 	return nil
 }
 
 func (c *Client) readMessage() {
+	// conn is fixed for this goroutine, a resumed client reads its new conn in a new goroutine
+	conn := c.conn
+	done := make(chan struct{})
 	defer func() {
+		close(done)
 		if r := recover(); r != nil {
 			c.closedErr = ErrPanic
 			fmt.Println("socket have panic err:", r, string(debug.Stack()))
 		}
-		c.close()
+		c.close(conn)
 	}()
-	c.conn.SetReadLimit(maxMessageSize)
-	_ = c.conn.SetReadDeadline(pongWait)
-	c.conn.SetPongHandler(c.pongHandler)
+	conn.SetReadLimit(maxMessageSize)
+	_ = conn.SetReadDeadline(c.pongWait)
+	conn.SetPongHandler(c.pongHandler)
+	if c.pingInterval > 0 {
+		go c.heartbeat(conn, done)
+	}
 	for {
-		messageType, message, returnErr := c.conn.ReadMessage()
+		messageType, message, returnErr := conn.ReadMessage()
 		if returnErr != nil {
 			c.closedErr = returnErr
 			return
 		}
+		c.touch()
 		log.ZDebug(c.ctx, "readMessage", "messageType", messageType)
 		if c.closed == true { // 连接刚置位已经关闭，但是协程还没退出的场景
 			c.closedErr = ErrConnClosed
@@ -136,15 +321,23 @@ func (c *Client) readMessage() {
 		}
 		switch messageType {
 		case MessageBinary:
-			_ = c.conn.SetReadDeadline(pongWait)
+			_ = conn.SetReadDeadline(c.pongWait)
 			parseDataErr := c.handleMessage(message)
 			if parseDataErr != nil {
 				c.closedErr = parseDataErr
 				return
 			}
 		case MessageText:
-			c.closedErr = ErrNotSupportMessageProtocol
-			return
+			if !c.textFrame {
+				c.closedErr = ErrNotSupportMessageProtocol
+				return
+			}
+			_ = conn.SetReadDeadline(c.pongWait)
+			parseDataErr := c.handleMessage(message)
+			if parseDataErr != nil {
+				c.closedErr = parseDataErr
+				return
+			}
 		case PingMessage:
 			err := c.writePongMsg()
 			log.ZError(c.ctx, "writePongMsg", err)
@@ -156,19 +349,39 @@ func (c *Client) readMessage() {
 	}
 }
 
+// heartbeat pings conn every pingInterval until the read goroutine of conn exits,
+// the pong extends the read deadline in pongHandler.
+func (c *Client) heartbeat(conn LongConn, done chan struct{}) {
+	ticker := time.NewTicker(c.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := c.writePingMsg(conn); err != nil {
+				log.ZDebug(c.ctx, "writePingMsg", "err", err)
+				return
+			}
+			prome.Inc(prome.MsgGatewayPingCounter)
+		}
+	}
+}
+
 func (c *Client) handleMessage(message []byte) error {
 	if c.IsCompress {
 		var decompressErr error
-		message, decompressErr = c.longConnServer.DeCompress(message)
+		message, decompressErr = c.compressor.DeCompress(message)
 		if decompressErr != nil {
 			return utils.Wrap(decompressErr, "")
 		}
 	}
 	var binaryReq Req
-	err := c.longConnServer.Decode(message, &binaryReq)
+	err := c.encoder.Decode(message, &binaryReq)
 	if err != nil {
 		return utils.Wrap(err, "")
 	}
+	binaryReq.encoder = c.encoder
 	if err := c.longConnServer.Validate(binaryReq); err != nil {
 		return utils.Wrap(err, "")
 	}
@@ -179,6 +392,14 @@ func (c *Client) handleMessage(message []byte) error {
 		[]string{binaryReq.OperationID, binaryReq.SendID, constant.PlatformIDToName(c.PlatformID), c.ctx.GetConnID()},
 	)
 	log.ZDebug(ctx, "gateway req message", "req", binaryReq.String())
+	if allowed, disconnect := c.longConnServer.checkRateLimit(ctx, c, binaryReq.ReqIdentifier); !allowed {
+		log.ZDebug(ctx, "gateway req rate limited", "reqIdentifier", binaryReq.ReqIdentifier, "disconnect", disconnect)
+		c.replyMessage(ctx, &binaryReq, ErrRateLimited.Wrap(), nil)
+		if disconnect {
+			return ErrTooManyRequests
+		}
+		return nil
+	}
 	var messageErr error
 	var resp []byte
 	switch binaryReq.ReqIdentifier {
@@ -188,10 +409,15 @@ func (c *Client) handleMessage(message []byte) error {
 		resp, messageErr = c.longConnServer.SendMessage(ctx, binaryReq)
 	case WSSendSignalMsg:
 		resp, messageErr = c.longConnServer.SendSignalMessage(ctx, binaryReq)
+	case WSSendEphemeral:
+		resp, messageErr = c.longConnServer.sendEphemeral(ctx, c, binaryReq)
 	case WSPullMsgBySeqList:
 		resp, messageErr = c.longConnServer.PullMessageBySeqList(ctx, binaryReq)
 	case WsLogoutMsg:
 		resp, messageErr = c.longConnServer.UserLogout(ctx, binaryReq)
+		c.w.Lock()
+		c.dropSession = true
+		c.w.Unlock()
 	case WsSetBackgroundStatus:
 		resp, messageErr = c.setAppBackgroundStatus(ctx, binaryReq)
 	default:
@@ -216,10 +442,15 @@ func (c *Client) setAppBackgroundStatus(ctx context.Context, req Req) ([]byte, e
 	return resp, nil
 }
 
-func (c *Client) close() {
+func (c *Client) close(conn LongConn) {
 	c.w.Lock()
 	defer c.w.Unlock()
+	// already closed by the reaper, or resumed on another conn
+	if c.closed || c.conn != conn {
+		return
+	}
 	c.closed = true
+	c.detached = c.resumeToken != "" && !c.dropSession && c.closedErr != ErrClientClosed
 	c.conn.Close()
 	c.longConnServer.UnRegister(c)
 }
@@ -251,7 +482,7 @@ func (c *Client) PushMessage(ctx context.Context, msgData *sdkws.MsgData) error
 		msg.Msgs = m
 	}
 	log.ZDebug(ctx, "PushMessage", "msg", &msg)
-	data, err := proto.Marshal(&msg)
+	data, err := marshalPayload(c.encoder, &msg)
 	if err != nil {
 		return err
 	}
@@ -267,6 +498,9 @@ func (c *Client) KickOnlineMessage() error {
 	resp := Resp{
 		ReqIdentifier: WSKickOnlineMsg,
 	}
+	c.w.Lock()
+	c.dropSession = true
+	c.w.Unlock()
 	return c.writeBinaryMsg(resp)
 }
 
@@ -274,22 +508,32 @@ func (c *Client) writeBinaryMsg(resp Resp) error {
 	c.w.Lock()
 	defer c.w.Unlock()
 	if c.closed == true {
+		if c.detached && resp.ReqIdentifier == WSPushMsg {
+			c.replay.push(resp)
+		}
 		return nil
 	}
+	return c.writeResp(resp)
+}
+
+// writeResp writes resp to the conn, c.w must be held.
+func (c *Client) writeResp(resp Resp) error {
 	encodedBuf := bufferPool.Get().([]byte)
 	resultBuf := bufferPool.Get().([]byte)
-	encodedBuf, err := c.longConnServer.Encode(resp)
+	encodedBuf, err := c.encoder.Encode(resp)
 	if err != nil {
 		return utils.Wrap(err, "")
 	}
-	_ = c.conn.SetWriteDeadline(writeWait)
+	_ = c.conn.SetWriteDeadline(c.writeWait)
 	if c.IsCompress {
 		var compressErr error
-		resultBuf, compressErr = c.longConnServer.Compress(encodedBuf)
+		resultBuf, compressErr = c.compressor.Compress(encodedBuf)
 		if compressErr != nil {
 			return utils.Wrap(compressErr, "")
 		}
 		return c.conn.WriteMessage(MessageBinary, resultBuf)
+	} else if c.textFrame {
+		return c.conn.WriteMessage(MessageText, encodedBuf)
 	} else {
 		return c.conn.WriteMessage(MessageBinary, encodedBuf)
 	}
@@ -301,6 +545,16 @@ func (c *Client) writePongMsg() error {
 	if c.closed == true {
 		return nil
 	}
-	_ = c.conn.SetWriteDeadline(writeWait)
+	_ = c.conn.SetWriteDeadline(c.writeWait)
 	return c.conn.WriteMessage(PongMessage, nil)
 }
+
+func (c *Client) writePingMsg(conn LongConn) error {
+	c.w.Lock()
+	defer c.w.Unlock()
+	if c.closed || c.conn != conn {
+		return ErrConnClosed
+	}
+	_ = conn.SetWriteDeadline(c.writeWait)
+	return conn.WriteMessage(PingMessage, nil)
+}
@@ -16,8 +16,13 @@ package msggateway
 
 import (
 	"bytes"
+	"compress/flate"
 	"compress/gzip"
+	"errors"
 	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
 
 	"github.com/OpenIMSDK/tools/utils"
 )
@@ -26,17 +31,27 @@ type Compressor interface {
 	Compress(rawData []byte) ([]byte, error)
 	DeCompress(compressedData []byte) ([]byte, error)
 }
+
 type GzipCompressor struct {
 	compressProtocol string
+	writers          sync.Pool
+	readers          sync.Pool
 }
 
 func NewGzipCompressor() *GzipCompressor {
-	return &GzipCompressor{compressProtocol: "gzip"}
+	return &GzipCompressor{
+		compressProtocol: GzipCompressionProtocol,
+		writers: sync.Pool{New: func() interface{} {
+			return gzip.NewWriter(nil)
+		}},
+	}
 }
 
 func (g *GzipCompressor) Compress(rawData []byte) ([]byte, error) {
 	gzipBuffer := bytes.Buffer{}
-	gz := gzip.NewWriter(&gzipBuffer)
+	gz := g.writers.Get().(*gzip.Writer)
+	defer g.writers.Put(gz)
+	gz.Reset(&gzipBuffer)
 	if _, err := gz.Write(rawData); err != nil {
 		return nil, utils.Wrap(err, "")
 	}
@@ -48,14 +63,155 @@ func (g *GzipCompressor) Compress(rawData []byte) ([]byte, error) {
 
 func (g *GzipCompressor) DeCompress(compressedData []byte) ([]byte, error) {
 	buff := bytes.NewBuffer(compressedData)
-	reader, err := gzip.NewReader(buff)
+	var reader *gzip.Reader
+	if r, ok := g.readers.Get().(*gzip.Reader); ok {
+		reader = r
+		if err := reader.Reset(buff); err != nil {
+			return nil, utils.Wrap(err, "Reset failed")
+		}
+	} else {
+		var err error
+		reader, err = gzip.NewReader(buff)
+		if err != nil {
+			return nil, utils.Wrap(err, "NewReader failed")
+		}
+	}
+	defer g.readers.Put(reader)
+	data, err := readAllLimited(reader)
 	if err != nil {
-		return nil, utils.Wrap(err, "NewReader failed")
+		return nil, err
 	}
-	compressedData, err = io.ReadAll(reader)
+	_ = reader.Close()
+	return data, nil
+}
+
+// DeflateCompressor raw deflate without the zlib or gzip header.
+type DeflateCompressor struct {
+	writers sync.Pool
+	readers sync.Pool
+}
+
+func NewDeflateCompressor() *DeflateCompressor {
+	return &DeflateCompressor{
+		writers: sync.Pool{New: func() interface{} {
+			w, _ := flate.NewWriter(nil, flate.DefaultCompression)
+			return w
+		}},
+		readers: sync.Pool{New: func() interface{} {
+			return flate.NewReader(nil)
+		}},
+	}
+}
+
+func (d *DeflateCompressor) Compress(rawData []byte) ([]byte, error) {
+	buff := bytes.Buffer{}
+	w := d.writers.Get().(*flate.Writer)
+	defer d.writers.Put(w)
+	w.Reset(&buff)
+	if _, err := w.Write(rawData); err != nil {
+		return nil, utils.Wrap(err, "")
+	}
+	if err := w.Close(); err != nil {
+		return nil, utils.Wrap(err, "")
+	}
+	return buff.Bytes(), nil
+}
+
+func (d *DeflateCompressor) DeCompress(compressedData []byte) ([]byte, error) {
+	r := d.readers.Get().(io.ReadCloser)
+	defer d.readers.Put(r)
+	if err := r.(flate.Resetter).Reset(bytes.NewReader(compressedData), nil); err != nil {
+		return nil, utils.Wrap(err, "Reset failed")
+	}
+	return readAllLimited(r)
+}
+
+var errDecompressedTooLarge = errors.New("decompressed data is too large")
+
+// readAllLimited reads r up to maxDecompressedSize, so that a small frame can not
+// inflate into an unbounded buffer.
+func readAllLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
 	if err != nil {
 		return nil, utils.Wrap(err, "ReadAll failed")
 	}
-	_ = reader.Close()
-	return compressedData, nil
+	if len(data) > maxDecompressedSize {
+		return nil, utils.Wrap(errDecompressedTooLarge, "")
+	}
+	return data, nil
+}
+
+// ZstdCompressor shares one encoder and decoder, EncodeAll and DecodeAll are
+// safe for concurrent use and reuse their internal state between calls.
+type ZstdCompressor struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+func NewZstdCompressor() (*ZstdCompressor, error) {
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	if err != nil {
+		return nil, utils.Wrap(err, "")
+	}
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize))
+	if err != nil {
+		return nil, utils.Wrap(err, "")
+	}
+	return &ZstdCompressor{encoder: encoder, decoder: decoder}, nil
+}
+
+func (z *ZstdCompressor) Compress(rawData []byte) ([]byte, error) {
+	return z.encoder.EncodeAll(rawData, nil), nil
+}
+
+func (z *ZstdCompressor) DeCompress(compressedData []byte) ([]byte, error) {
+	data, err := z.decoder.DecodeAll(compressedData, nil)
+	if err != nil {
+		return nil, utils.Wrap(err, "")
+	}
+	return data, nil
+}
+
+const (
+	frameRaw        byte = 0
+	frameCompressed byte = 1
+)
+
+var errInvalidFrame = errors.New("invalid compressed frame")
+
+// thresholdCompressor prefixes each frame with a flag byte so frames shorter
+// than threshold can be sent without compression. Gzip keeps the unprefixed
+// format for compatibility with existing clients.
+type thresholdCompressor struct {
+	Compressor
+	threshold int
+}
+
+func newThresholdCompressor(compressor Compressor, threshold int) *thresholdCompressor {
+	return &thresholdCompressor{Compressor: compressor, threshold: threshold}
+}
+
+func (t *thresholdCompressor) Compress(rawData []byte) ([]byte, error) {
+	if len(rawData) < t.threshold {
+		return append([]byte{frameRaw}, rawData...), nil
+	}
+	data, err := t.Compressor.Compress(rawData)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{frameCompressed}, data...), nil
+}
+
+func (t *thresholdCompressor) DeCompress(compressedData []byte) ([]byte, error) {
+	if len(compressedData) == 0 {
+		return nil, utils.Wrap(errInvalidFrame, "")
+	}
+	switch compressedData[0] {
+	case frameRaw:
+		return compressedData[1:], nil
+	case frameCompressed:
+		return t.Compressor.DeCompress(compressedData[1:])
+	default:
+		return nil, utils.Wrap(errInvalidFrame, "")
+	}
 }
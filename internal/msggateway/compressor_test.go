@@ -0,0 +1,82 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCompressors(t *testing.T) {
+	zstdCompressor, err := NewZstdCompressor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressors := map[string]Compressor{
+		GzipCompressionProtocol:    NewGzipCompressor(),
+		ZstdCompressionProtocol:    newThresholdCompressor(zstdCompressor, 64),
+		DeflateCompressionProtocol: newThresholdCompressor(NewDeflateCompressor(), 64),
+	}
+	for name, compressor := range compressors {
+		for _, data := range [][]byte{[]byte("tiny"), bytes.Repeat([]byte("openim"), 100)} {
+			b, err := compressor.Compress(data)
+			if err != nil {
+				t.Fatal(name, err)
+			}
+			got, err := compressor.DeCompress(b)
+			if err != nil {
+				t.Fatal(name, err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatalf("%s: got %q, want %q", name, got, data)
+			}
+		}
+	}
+	b, _ := compressors[ZstdCompressionProtocol].Compress([]byte("tiny"))
+	if b[0] != frameRaw {
+		t.Fatal("frames under the threshold must not be compressed")
+	}
+	if _, err := compressors[DeflateCompressionProtocol].DeCompress([]byte{9, 1}); err == nil {
+		t.Fatal("expected error for invalid frame flag")
+	}
+}
+
+func TestDecompressLimit(t *testing.T) {
+	zstdCompressor, err := NewZstdCompressor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, maxDecompressedSize+1)
+	for name, compressor := range map[string]Compressor{
+		GzipCompressionProtocol:    NewGzipCompressor(),
+		ZstdCompressionProtocol:    zstdCompressor,
+		DeflateCompressionProtocol: NewDeflateCompressor(),
+	} {
+		b, err := compressor.Compress(data)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		if _, err := compressor.DeCompress(b); err == nil {
+			t.Fatalf("%s: data over the limit decompressed", name)
+		}
+		b, err = compressor.Compress(data[:maxDecompressedSize])
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		if got, err := compressor.DeCompress(b); err != nil || len(got) != maxDecompressedSize {
+			t.Fatalf("%s: data at the limit: %d %v", name, len(got), err)
+		}
+	}
+}
@@ -25,11 +25,29 @@ const (
 	OperationID             = "operationID"
 	Compression             = "compression"
 	GzipCompressionProtocol = "gzip"
-	BackgroundStatus        = "isBackground"
+	// ZstdCompressionProtocol and DeflateCompressionProtocol frames start with a
+	// flag byte, 0 for raw and 1 for compressed, see thresholdCompressor.
+	ZstdCompressionProtocol    = "zstd"
+	DeflateCompressionProtocol = "deflate"
+	Encoding                   = "encoding"
+	GobEncoding                = "gob"
+	JsonEncoding               = "json"
+	ProtobufEncoding           = "protobuf"
+	BackgroundStatus           = "isBackground"
+	LastEventID                = "lastEventID"
+	ResumeToken                = "resumeToken"
+	Resume                     = "resume"
 )
 
 const (
 	WebSocket = iota + 1
+	ServerSentEvents
+)
+
+const (
+	// Paths of the sse fallback transport, see SSEConn.
+	ssePath     = "/sse"
+	sseSendPath = "/sse/send"
 )
 
 const (
@@ -38,20 +56,36 @@ const (
 	WSPullMsgBySeqList    = 1002
 	WSSendMsg             = 1003
 	WSSendSignalMsg       = 1004
+	WSSendEphemeral       = 1005
 	WSPushMsg             = 2001
 	WSKickOnlineMsg       = 2002
 	WsLogoutMsg           = 2003
 	WsSetBackgroundStatus = 2004
+	WsResumeToken         = 2005
+	WsReconnect           = 2006
+	WSPushEphemeral       = 2007
 	WSDataError           = 3001
 )
 
 const (
-	// Time allowed to write a message to the peer.
+	// Time allowed to write a message to the peer, when no websocket timeout is configured.
 	writeWait = 10 * time.Second
 
-	// Time allowed to read the next pong message from the peer.
+	// Time allowed to read the next pong message from the peer, when server pings are off.
 	pongWait = 30 * time.Second
 
+	// Time a migrated client has to connect to another node before it is reported offline.
+	migrateReconnectWait = 30 * time.Second
+
 	// Maximum message size allowed from peer.
 	maxMessageSize = 51200
+
+	// Frames kept per sse conn for replay after the client reconnects.
+	sseReplayBuffer = 256
+
+	// Frames posted by an sse client not yet read by the client goroutine.
+	sseInboundBuffer = 16
+
+	// Maximum size of a decompressed frame.
+	maxDecompressedSize = 16 << 20
 )
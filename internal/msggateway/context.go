@@ -135,6 +135,16 @@ func (c *UserConnContext) SetToken(token string) {
 	c.Req.URL.RawQuery = Token + "=" + token
 }
 
+// GetResume reports whether the client asked for resume tokens, clients that do not
+// understand the WsResumeToken frame never get one.
+func (c *UserConnContext) GetResume() bool {
+	b, err := strconv.ParseBool(c.Req.URL.Query().Get(Resume))
+	if err != nil {
+		return false
+	}
+	return b
+}
+
 func (c *UserConnContext) GetBackground() bool {
 	b, err := strconv.ParseBool(c.Req.URL.Query().Get(BackgroundStatus))
 	if err != nil {
@@ -0,0 +1,192 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	"github.com/OpenIMSDK/protocol/msggateway"
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/mcontext"
+	"github.com/OpenIMSDK/tools/utils"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/gatewayext"
+)
+
+// Drain takes this node out of service in the background, the process exits when it is done.
+func (s *Server) Drain(ctx context.Context, req *gatewayext.DrainReq) (*gatewayext.DrainResp, error) {
+	if err := authverify.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	resp := &gatewayext.DrainResp{ConnNum: int64(len(s.LongConnServer.GetAllClients()))}
+	go s.drain(mcontext.NewCtx("@@@" + mcontext.GetOperationID(ctx)))
+	return resp, nil
+}
+
+// drain deregisters the node, refuses new conns, asks the connected clients to
+// reconnect elsewhere in batches so they do not all hit the other nodes at once,
+// waits for running pushes and finally shuts the ws server down.
+func (s *Server) drain(ctx context.Context) {
+	if !s.LongConnServer.StartDrain() {
+		log.ZInfo(ctx, "msg gateway already draining")
+		return
+	}
+	conf := config.Config.LongConnSvr
+	batchSize := conf.DrainBatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	interval := time.Duration(conf.DrainBatchInterval) * time.Millisecond
+	timeout := time.Duration(conf.DrainTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+	deadline := time.Now().Add(timeout)
+	if s.disCov != nil {
+		if err := s.disCov.UnRegister(); err != nil {
+			log.ZWarn(ctx, "unregister msg gateway", err)
+		}
+	}
+	clients := s.LongConnServer.GetAllClients()
+	log.ZInfo(ctx, "msg gateway drain start", "conn num", len(clients), "batch size", batchSize)
+	for i, client := range clients {
+		if err := s.LongConnServer.migrateClient(ctx, client); err != nil {
+			log.ZWarn(ctx, "migrate client", err, "userID", client.UserID, "platformID", client.PlatformID)
+		}
+		if (i+1)%batchSize == 0 && i+1 < len(clients) {
+			time.Sleep(interval)
+		}
+	}
+	// clients close their conns once they are connected elsewhere
+	for time.Now().Before(deadline) &&
+		(atomic.LoadInt64(&s.pushing) > 0 || len(s.LongConnServer.GetAllClients()) > 0) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	for _, client := range s.LongConnServer.GetAllClients() {
+		client.shutdown(ErrDrained)
+	}
+	s.LongConnServer.waitMigrations(ctx)
+	shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := s.LongConnServer.Shutdown(shutdownCtx); err != nil {
+		log.ZWarn(ctx, "shutdown ws server", err)
+	}
+	log.ZInfo(ctx, "msg gateway drained", "pushing", atomic.LoadInt64(&s.pushing))
+}
+
+// migrateClient asks the client to reconnect to another node. Its offline status is not
+// reported when its conn closes, so if it has not shown up on another node after
+// migrateReconnectWait it is reported offline then.
+func (ws *WsServer) migrateClient(ctx context.Context, client *Client) error {
+	userID, platformID, connID := client.UserID, client.PlatformID, client.ctx.GetConnID()
+	ws.migrations.Add(1)
+	time.AfterFunc(migrateReconnectWait, func() {
+		defer ws.migrations.Done()
+		ws.checkMigrated(ctx, userID, platformID, connID)
+	})
+	return client.migrate()
+}
+
+// waitMigrations waits until every migrated client was found on another node or reported offline.
+func (ws *WsServer) waitMigrations(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		ws.migrations.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(migrateReconnectWait + 10*time.Second):
+		log.ZWarn(ctx, "wait for migrated clients timeout", nil)
+	}
+}
+
+// checkMigrated reports a migrated client offline unless another node holds a conn of its platform.
+func (ws *WsServer) checkMigrated(ctx context.Context, userID string, platformID int, connID string) {
+	if ws.onlineElsewhere(ctx, userID, platformID) {
+		return
+	}
+	if err := ws.userClient.SetUserStatus(ctx, userID, constant.Offline, platformID); err != nil {
+		log.ZWarn(ctx, "SetUserStatus err", err, "userID", userID, "platformID", platformID)
+	}
+	if err := CallbackUserOffline(ctx, userID, platformID, connID); err != nil {
+		log.ZWarn(ctx, "CallbackUserOffline err", err, "userID", userID, "platformID", platformID)
+	}
+	log.ZInfo(ctx, "migrated user did not reconnect, offline", "userID", userID, "platformID", platformID)
+}
+
+// onlineElsewhere asks the other msg gateway nodes whether userID is connected on platformID.
+// Nodes that cannot be asked are skipped.
+func (ws *WsServer) onlineElsewhere(ctx context.Context, userID string, platformID int) bool {
+	conns, err := ws.disCov.GetConns(ctx, config.Config.RpcRegisterName.OpenImMessageGatewayName)
+	if err != nil {
+		log.ZWarn(ctx, "get msg gateway conns", err, "userID", userID)
+		return false
+	}
+	conns = ws.userNodeConns(ctx, conns, userID)
+	if len(config.Config.Manager.UserID) > 0 {
+		ctx = mcontext.WithOpUserIDContext(ctx, config.Config.Manager.UserID[0])
+	}
+	platform := constant.PlatformIDToName(platformID)
+	var (
+		mu     sync.Mutex
+		online bool
+		wg     sync.WaitGroup
+	)
+	for _, conn := range conns {
+		if conn.Target() == ws.disCov.GetSelfConnTarget() {
+			continue
+		}
+		wg.Add(1)
+		go func(target string, client msggateway.MsgGatewayClient) {
+			defer wg.Done()
+			resp, err := client.GetUsersOnlineStatus(ctx, &msggateway.GetUsersOnlineStatusReq{UserIDs: []string{userID}})
+			if err != nil {
+				log.ZWarn(ctx, "GetUsersOnlineStatus err", err, "node", target, "userID", userID)
+				return
+			}
+			for _, result := range resp.SuccessResult {
+				for _, detail := range result.DetailPlatformStatus {
+					if detail.Platform == platform {
+						mu.Lock()
+						online = true
+						mu.Unlock()
+					}
+				}
+			}
+		}(conn.Target(), msggateway.NewMsgGatewayClient(conn))
+	}
+	wg.Wait()
+	return online
+}
+
+// drainOnSignal drains the node on SIGTERM or SIGINT, so a rolling deploy does not drop every conn at once.
+func (s *Server) drainOnSignal() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT)
+	sig := <-sigs
+	ctx := mcontext.NewCtx(utils.GetSelfFuncName())
+	log.ZInfo(ctx, "msg gateway received signal", "signal", sig.String())
+	s.drain(ctx)
+}
@@ -17,6 +17,13 @@ package msggateway
 import (
 	"bytes"
 	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/OpenIMSDK/tools/utils"
 )
@@ -26,6 +33,27 @@ type Encoder interface {
 	Decode(encodeData []byte, decodeData interface{}) error
 }
 
+// PayloadEncoder is implemented by the encoders that also choose how the protobuf Data
+// of Req and Resp is encoded, which is protobuf binary otherwise.
+type PayloadEncoder interface {
+	MarshalPayload(m proto.Message) ([]byte, error)
+	UnmarshalPayload(b []byte, m proto.Message) error
+}
+
+func marshalPayload(encoder Encoder, m proto.Message) ([]byte, error) {
+	if p, ok := encoder.(PayloadEncoder); ok {
+		return p.MarshalPayload(m)
+	}
+	return proto.Marshal(m)
+}
+
+func unmarshalPayload(encoder Encoder, b []byte, m proto.Message) error {
+	if p, ok := encoder.(PayloadEncoder); ok {
+		return p.UnmarshalPayload(b, m)
+	}
+	return proto.Unmarshal(b, m)
+}
+
 type GobEncoder struct{}
 
 func NewGobEncoder() *GobEncoder {
@@ -51,3 +79,312 @@ func (g *GobEncoder) Decode(encodeData []byte, decodeData interface{}) error {
 	}
 	return nil
 }
+
+// JsonEncoder encodes Req and Resp as json objects. The protobuf Data of requests and
+// responses is encoded with protojson and embedded as is, other Data is embedded as is
+// when it is json and as a string otherwise.
+type JsonEncoder struct{}
+
+func NewJsonEncoder() *JsonEncoder {
+	return &JsonEncoder{}
+}
+
+type (
+	reqAlias  Req
+	respAlias Resp
+)
+
+// jsonData is Data embedded in the json of Req and Resp.
+type jsonData []byte
+
+func (d jsonData) MarshalJSON() ([]byte, error) {
+	if len(d) == 0 {
+		return []byte("null"), nil
+	}
+	if json.Valid(d) {
+		return d, nil
+	}
+	return json.Marshal(string(d))
+}
+
+func (d *jsonData) UnmarshalJSON(b []byte) error {
+	switch {
+	case string(b) == "null":
+		*d = nil
+	case len(b) > 0 && b[0] == '"':
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*d = jsonData(s)
+	default:
+		*d = append(jsonData(nil), b...)
+	}
+	return nil
+}
+
+type jsonReq struct {
+	*reqAlias
+	Data jsonData `json:"data"`
+}
+
+type jsonResp struct {
+	*respAlias
+	Data jsonData `json:"data"`
+}
+
+func (j *JsonEncoder) Encode(data interface{}) ([]byte, error) {
+	switch v := data.(type) {
+	case Req:
+		data = &jsonReq{reqAlias: (*reqAlias)(&v), Data: v.Data}
+	case *Req:
+		data = &jsonReq{reqAlias: (*reqAlias)(v), Data: v.Data}
+	case Resp:
+		data = &jsonResp{respAlias: (*respAlias)(&v), Data: v.Data}
+	case *Resp:
+		data = &jsonResp{respAlias: (*respAlias)(v), Data: v.Data}
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, utils.Wrap(err, "")
+	}
+	return b, nil
+}
+
+func (j *JsonEncoder) Decode(encodeData []byte, decodeData interface{}) error {
+	switch v := decodeData.(type) {
+	case *Req:
+		req := jsonReq{reqAlias: (*reqAlias)(v)}
+		if err := json.Unmarshal(encodeData, &req); err != nil {
+			return utils.Wrap(err, "")
+		}
+		v.Data = req.Data
+		return nil
+	case *Resp:
+		resp := jsonResp{respAlias: (*respAlias)(v)}
+		if err := json.Unmarshal(encodeData, &resp); err != nil {
+			return utils.Wrap(err, "")
+		}
+		v.Data = resp.Data
+		return nil
+	}
+	if err := json.Unmarshal(encodeData, decodeData); err != nil {
+		return utils.Wrap(err, "")
+	}
+	return nil
+}
+
+func (j *JsonEncoder) MarshalPayload(m proto.Message) ([]byte, error) {
+	return protojson.Marshal(m)
+}
+
+func (j *JsonEncoder) UnmarshalPayload(b []byte, m proto.Message) error {
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, m)
+}
+
+// ProtobufEncoder encodes Req and Resp as the protobuf messages below, so that
+// clients can use generated code of any language.
+//
+//	message Req {
+//	  int32 reqIdentifier = 1;
+//	  string token = 2;
+//	  string sendID = 3;
+//	  string operationID = 4;
+//	  string msgIncr = 5;
+//	  bytes data = 6;
+//	}
+//
+//	message Resp {
+//	  int32 reqIdentifier = 1;
+//	  string msgIncr = 2;
+//	  string operationID = 3;
+//	  int32 errCode = 4;
+//	  string errMsg = 5;
+//	  bytes data = 6;
+//	}
+type ProtobufEncoder struct{}
+
+func NewProtobufEncoder() *ProtobufEncoder {
+	return &ProtobufEncoder{}
+}
+
+func (p *ProtobufEncoder) Encode(data interface{}) ([]byte, error) {
+	var b []byte
+	switch v := data.(type) {
+	case Resp:
+		b = appendResp(b, &v)
+	case *Resp:
+		b = appendResp(b, v)
+	case Req:
+		b = appendReq(b, &v)
+	case *Req:
+		b = appendReq(b, v)
+	default:
+		return nil, utils.Wrap(ErrNotSupportMessageProtocol, fmt.Sprintf("protobuf encode %T", data))
+	}
+	return b, nil
+}
+
+func (p *ProtobufEncoder) Decode(encodeData []byte, decodeData interface{}) error {
+	var err error
+	switch v := decodeData.(type) {
+	case *Req:
+		err = consumeFields(encodeData, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+			return consumeReqField(v, num, typ, b)
+		})
+	case *Resp:
+		err = consumeFields(encodeData, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+			return consumeRespField(v, num, typ, b)
+		})
+	default:
+		return utils.Wrap(ErrNotSupportMessageProtocol, fmt.Sprintf("protobuf decode %T", decodeData))
+	}
+	return utils.Wrap(err, "")
+}
+
+func appendInt32(b []byte, num protowire.Number, v int32) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(int64(v)))
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendReq(b []byte, r *Req) []byte {
+	b = appendInt32(b, 1, r.ReqIdentifier)
+	b = appendString(b, 2, r.Token)
+	b = appendString(b, 3, r.SendID)
+	b = appendString(b, 4, r.OperationID)
+	b = appendString(b, 5, r.MsgIncr)
+	return appendBytes(b, 6, r.Data)
+}
+
+func appendResp(b []byte, r *Resp) []byte {
+	b = appendInt32(b, 1, r.ReqIdentifier)
+	b = appendString(b, 2, r.MsgIncr)
+	b = appendString(b, 3, r.OperationID)
+	b = appendInt32(b, 4, int32(r.ErrCode))
+	b = appendString(b, 5, r.ErrMsg)
+	return appendBytes(b, 6, r.Data)
+}
+
+// consumeFields walks the fields of a message, fn returns the length it consumed
+// or 0 for fields it does not know, which are skipped.
+func consumeFields(b []byte, fn func(num protowire.Number, typ protowire.Type, b []byte) (int, error)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		n, err := fn(num, typ, b)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+func consumeInt32(typ protowire.Type, b []byte, v *int32) (int, error) {
+	if typ != protowire.VarintType {
+		return 0, errors.New("unexpected wire type")
+	}
+	x, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	*v = int32(x)
+	return n, nil
+}
+
+func consumeBytes(typ protowire.Type, b []byte) ([]byte, int, error) {
+	if typ != protowire.BytesType {
+		return nil, 0, errors.New("unexpected wire type")
+	}
+	v, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return nil, 0, protowire.ParseError(n)
+	}
+	return v, n, nil
+}
+
+func consumeString(typ protowire.Type, b []byte, v *string) (int, error) {
+	s, n, err := consumeBytes(typ, b)
+	if err != nil {
+		return 0, err
+	}
+	*v = string(s)
+	return n, nil
+}
+
+func consumeReqField(r *Req, num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+	switch num {
+	case 1:
+		return consumeInt32(typ, b, &r.ReqIdentifier)
+	case 2:
+		return consumeString(typ, b, &r.Token)
+	case 3:
+		return consumeString(typ, b, &r.SendID)
+	case 4:
+		return consumeString(typ, b, &r.OperationID)
+	case 5:
+		return consumeString(typ, b, &r.MsgIncr)
+	case 6:
+		data, n, err := consumeBytes(typ, b)
+		if err != nil {
+			return 0, err
+		}
+		r.Data = append([]byte(nil), data...)
+		return n, nil
+	}
+	return 0, nil
+}
+
+func consumeRespField(r *Resp, num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+	switch num {
+	case 1:
+		return consumeInt32(typ, b, &r.ReqIdentifier)
+	case 2:
+		return consumeString(typ, b, &r.MsgIncr)
+	case 3:
+		return consumeString(typ, b, &r.OperationID)
+	case 4:
+		var code int32
+		n, err := consumeInt32(typ, b, &code)
+		r.ErrCode = int(code)
+		return n, err
+	case 5:
+		return consumeString(typ, b, &r.ErrMsg)
+	case 6:
+		data, n, err := consumeBytes(typ, b)
+		if err != nil {
+			return 0, err
+		}
+		r.Data = append([]byte(nil), data...)
+		return n, nil
+	}
+	return 0, nil
+}
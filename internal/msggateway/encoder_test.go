@@ -0,0 +1,109 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/OpenIMSDK/protocol/sdkws"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestEncoders(t *testing.T) {
+	req := Req{ReqIdentifier: WSSendMsg, Token: "t", SendID: "u1", OperationID: "op", MsgIncr: "1", Data: []byte{0, 1, 2}}
+	resp := Resp{ReqIdentifier: WSSendMsg, MsgIncr: "1", OperationID: "op", ErrCode: 1001, ErrMsg: "ArgsError", Data: []byte{3}}
+	for name, encoder := range map[string]Encoder{
+		GobEncoding:      NewGobEncoder(),
+		JsonEncoding:     NewJsonEncoder(),
+		ProtobufEncoding: NewProtobufEncoder(),
+	} {
+		b, err := encoder.Encode(req)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		var gotReq Req
+		if err := encoder.Decode(b, &gotReq); err != nil {
+			t.Fatal(name, err)
+		}
+		if !reflect.DeepEqual(req, gotReq) {
+			t.Fatalf("%s: got %+v, want %+v", name, gotReq, req)
+		}
+		b, err = encoder.Encode(resp)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		var gotResp Resp
+		if err := encoder.Decode(b, &gotResp); err != nil {
+			t.Fatal(name, err)
+		}
+		if !reflect.DeepEqual(resp, gotResp) {
+			t.Fatalf("%s: got %+v, want %+v", name, gotResp, resp)
+		}
+	}
+}
+
+func TestProtobufEncoderSkipsUnknownFields(t *testing.T) {
+	b := protowire.AppendTag(nil, 100, protowire.BytesType)
+	b = protowire.AppendString(b, "future")
+	b = appendReq(b, &Req{SendID: "u1"})
+	var req Req
+	if err := NewProtobufEncoder().Decode(b, &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.SendID != "u1" {
+		t.Fatalf("unexpected req %+v", req)
+	}
+}
+
+func TestJsonEncoderPayload(t *testing.T) {
+	encoder := NewJsonEncoder()
+	data, err := marshalPayload(encoder, &sdkws.GetMaxSeqReq{UserID: "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := encoder.Encode(Req{ReqIdentifier: WSGetNewestSeq, SendID: "u1", Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(b, &envelope); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(envelope["data"], []byte("{")) {
+		t.Fatalf("payload not embedded as json: %s", b)
+	}
+	req := Req{encoder: encoder}
+	if err := encoder.Decode(b, &req); err != nil {
+		t.Fatal(err)
+	}
+	var got sdkws.GetMaxSeqReq
+	if err := req.unmarshalData(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.UserID != "u1" {
+		t.Fatalf("unexpected payload %+v", &got)
+	}
+
+	b, err = encoder.Encode(Resp{ReqIdentifier: WsResumeToken, Data: []byte("token")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(b, []byte(`"data":"token"`)) {
+		t.Fatalf("text data not embedded as a string: %s", b)
+	}
+}
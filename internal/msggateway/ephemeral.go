@@ -0,0 +1,322 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/mcontext"
+	"github.com/OpenIMSDK/tools/mw/specialerror"
+	"github.com/OpenIMSDK/tools/utils"
+	"golang.org/x/time/rate"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/gatewayext"
+)
+
+const (
+	EphemeralTyping    = "typing"
+	EphemeralRecording = "recording"
+
+	maxEphemeralContentLen = 1024
+
+	// How long a passed verification of a conversation is trusted, typing signals
+	// come in bursts and must not hit the friend and group rpc every time.
+	ephemeralVerifyTTL = 10 * time.Second
+
+	// Verified conversations kept per conn, the expired ones are dropped first,
+	// then the one expiring soonest.
+	maxEphemeralConversations = 256
+)
+
+// EphemeralSignal is a transient event between online users. It is relayed from
+// gateway to gateway and never stored, offline recipients do not get it.
+// It is the json Data of a WSSendEphemeral request and of a WSPushEphemeral push.
+type EphemeralSignal struct {
+	SendID           string `json:"sendID"`
+	SenderPlatformID int32  `json:"senderPlatformID"`
+	SessionType      int32  `json:"sessionType"`
+	RecvID           string `json:"recvID"`
+	GroupID          string `json:"groupID"`
+	// Type is typing, recording or any custom type agreed by the clients.
+	Type     string `json:"type"`
+	Content  string `json:"content"`
+	SendTime int64  `json:"sendTime"`
+}
+
+func (x *EphemeralSignal) Check() error {
+	if x.Type == "" {
+		return errs.ErrArgs.Wrap("type is empty")
+	}
+	if len(x.Content) > maxEphemeralContentLen {
+		return errs.ErrArgs.Wrap("content is too long")
+	}
+	switch x.SessionType {
+	case constant.SingleChatType:
+		if x.RecvID == "" {
+			return errs.ErrArgs.Wrap("recvID is empty")
+		}
+	case constant.GroupChatType, constant.SuperGroupChatType:
+		if x.GroupID == "" {
+			return errs.ErrArgs.Wrap("groupID is empty")
+		}
+	default:
+		return errs.ErrArgs.Wrap("sessionType is invalid")
+	}
+	return nil
+}
+
+func (x *EphemeralSignal) conversationID() string {
+	if x.SessionType == constant.SingleChatType {
+		return msgprocessor.GetConversationIDBySessionType(int(x.SessionType), x.SendID, x.RecvID)
+	}
+	return msgprocessor.GetConversationIDBySessionType(int(x.SessionType), x.GroupID)
+}
+
+// sendEphemeral relays a signal of client to the online conns of its recipients,
+// this node directly and the other gateway nodes over rpc, bypassing msg rpc and kafka.
+func (ws *WsServer) sendEphemeral(ctx context.Context, client *Client, req Req) ([]byte, error) {
+	var signal EphemeralSignal
+	if err := json.Unmarshal(req.Data, &signal); err != nil {
+		return nil, errs.ErrArgs.Wrap(err.Error())
+	}
+	signal.SendID = client.UserID
+	signal.SenderPlatformID = int32(client.PlatformID)
+	signal.SendTime = time.Now().UnixMilli()
+	if err := signal.Check(); err != nil {
+		return nil, err
+	}
+	// conversations are only tracked once verified, so random ids cannot grow the state of a conn
+	if err := ws.verifyEphemeral(ctx, client, &signal); err != nil {
+		return nil, err
+	}
+	if !client.allowEphemeral(signal.conversationID(), ws.ephemeralRate, ws.ephemeralBurst) {
+		return nil, ErrRateLimited.Wrap("ephemeral signals of " + signal.conversationID())
+	}
+	var userIDs []string
+	if signal.SessionType == constant.SingleChatType {
+		userIDs = []string{signal.RecvID}
+	} else {
+		memberIDs, err := ws.groupClient.GetGroupMemberIDs(ctx, signal.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		if !utils.IsContain(client.UserID, memberIDs) {
+			return nil, errs.ErrNoPermission.Wrap("not in group " + signal.GroupID)
+		}
+		userIDs = make([]string, 0, len(memberIDs)-1)
+		for _, memberID := range memberIDs {
+			if memberID != client.UserID {
+				userIDs = append(userIDs, memberID)
+			}
+		}
+	}
+	data, err := json.Marshal(&signal)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	ws.PushEphemeral(ctx, userIDs, data)
+	go ws.pushEphemeralToOtherNode(mcontext.NewCtx("@@@"+mcontext.GetOperationID(ctx)), userIDs, data)
+	return nil, nil
+}
+
+// verifyEphemeral applies the checks msg rpc applies to messages of the same conversation,
+// blacklist and friendship for single chats, membership and mutes for groups.
+// Passed checks are cached per conversation for ephemeralVerifyTTL.
+func (ws *WsServer) verifyEphemeral(ctx context.Context, client *Client, signal *EphemeralSignal) error {
+	conversationID := signal.conversationID()
+	now := time.Now()
+	if client.ephemeralVerifiedAt(conversationID, now) {
+		return nil
+	}
+	if !utils.IsContain(signal.SendID, config.Config.Manager.UserID) {
+		var err error
+		if signal.SessionType == constant.SingleChatType {
+			err = ws.verifySingleEphemeral(ctx, signal)
+		} else {
+			err = ws.verifyGroupEphemeral(ctx, signal)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	client.setEphemeralVerified(conversationID, now)
+	return nil
+}
+
+func (ws *WsServer) verifySingleEphemeral(ctx context.Context, signal *EphemeralSignal) error {
+	black, err := ws.friendClient.IsBlocked(ctx, signal.SendID, signal.RecvID)
+	if err != nil {
+		return err
+	}
+	if black {
+		return errs.ErrBlockedByPeer.Wrap()
+	}
+	if *config.Config.MessageVerify.FriendVerify {
+		friend, err := ws.friendClient.IsFriend(ctx, signal.SendID, signal.RecvID)
+		if err != nil {
+			return err
+		}
+		if !friend {
+			return errs.ErrNotPeersFriend.Wrap()
+		}
+	}
+	return nil
+}
+
+func (ws *WsServer) verifyGroupEphemeral(ctx context.Context, signal *EphemeralSignal) error {
+	groupInfo, err := ws.groupClient.GetGroupInfoCache(ctx, signal.GroupID)
+	if err != nil {
+		return err
+	}
+	if groupInfo.Status == constant.GroupStatusDismissed {
+		return errs.ErrDismissedAlready.Wrap()
+	}
+	member, err := ws.groupClient.GetGroupMemberCache(ctx, signal.GroupID, signal.SendID)
+	if err != nil {
+		if errs.ErrRecordNotFound.Is(specialerror.ErrCode(errs.Unwrap(err))) {
+			return errs.ErrNotInGroupYet.Wrap(err.Error())
+		}
+		return err
+	}
+	if member.RoleLevel == constant.GroupOwner {
+		return nil
+	}
+	if member.MuteEndTime >= time.Now().UnixMilli() {
+		return errs.ErrMutedInGroup.Wrap()
+	}
+	if groupInfo.Status == constant.GroupStatusMuted && member.RoleLevel != constant.GroupAdmin {
+		return errs.ErrMutedGroup.Wrap()
+	}
+	return nil
+}
+
+// PushEphemeral pushes an encoded EphemeralSignal to the conns of userIDs on this node.
+func (ws *WsServer) PushEphemeral(ctx context.Context, userIDs []string, data []byte) {
+	for _, userID := range userIDs {
+		clients, ok := ws.clients.GetAll(userID)
+		if !ok {
+			continue
+		}
+		for _, client := range clients {
+			if err := client.PushEphemeral(ctx, data); err != nil {
+				log.ZDebug(ctx, "PushEphemeral", "err", err, "userID", userID, "platformID", client.PlatformID)
+			}
+		}
+	}
+}
+
+func (ws *WsServer) pushEphemeralToOtherNode(ctx context.Context, userIDs []string, data []byte) {
+	conns, err := ws.disCov.GetConns(ctx, config.Config.RpcRegisterName.OpenImMessageGatewayName)
+	if err != nil {
+		log.ZWarn(ctx, "get msg gateway conns", err)
+		return
+	}
+	req := &gatewayext.PushEphemeralReq{UserIDs: userIDs, Data: data}
+	for _, v := range conns {
+		if v.Target() == ws.disCov.GetSelfConnTarget() {
+			continue
+		}
+		if _, err := gatewayext.NewGatewayExtClient(v).PushEphemeral(ctx, req); err != nil {
+			log.ZWarn(ctx, "PushEphemeral err", err, "node", v.Target())
+		}
+	}
+}
+
+// PushEphemeral relays a signal sent to a conn of another node.
+func (s *Server) PushEphemeral(ctx context.Context, req *gatewayext.PushEphemeralReq) (*gatewayext.PushEphemeralResp, error) {
+	s.LongConnServer.PushEphemeral(ctx, req.UserIDs, req.Data)
+	return &gatewayext.PushEphemeralResp{}, nil
+}
+
+// ephemeralConversation is the state of a verified conversation of a conn.
+type ephemeralConversation struct {
+	limiter *rate.Limiter
+	// verifiedUntil the last passed verification is trusted until
+	verifiedUntil time.Time
+}
+
+// allowEphemeral takes a token from the bucket of the verified conversationID, a rate of 0 is unlimited.
+// The methods on the ephemeral conversations are only called from the read goroutine of c.
+func (c *Client) allowEphemeral(conversationID string, r float64, burst int) bool {
+	if r <= 0 {
+		return true
+	}
+	conversation, ok := c.ephemerals[conversationID]
+	if !ok {
+		return false
+	}
+	if conversation.limiter == nil {
+		conversation.limiter = rate.NewLimiter(rate.Limit(r), burst)
+	}
+	return conversation.limiter.Allow()
+}
+
+// ephemeralVerifiedAt reports whether the verification of conversationID is still trusted at now.
+func (c *Client) ephemeralVerifiedAt(conversationID string, now time.Time) bool {
+	conversation, ok := c.ephemerals[conversationID]
+	return ok && now.Before(conversation.verifiedUntil)
+}
+
+// setEphemeralVerified trusts conversationID for ephemeralVerifyTTL after now, its token bucket is kept.
+func (c *Client) setEphemeralVerified(conversationID string, now time.Time) {
+	if c.ephemerals == nil {
+		c.ephemerals = make(map[string]*ephemeralConversation)
+	}
+	conversation, ok := c.ephemerals[conversationID]
+	if !ok {
+		if len(c.ephemerals) >= maxEphemeralConversations {
+			c.pruneEphemerals(now)
+		}
+		conversation = &ephemeralConversation{}
+		c.ephemerals[conversationID] = conversation
+	}
+	conversation.verifiedUntil = now.Add(ephemeralVerifyTTL)
+}
+
+// pruneEphemerals drops the conversations whose verification expired, or the one
+// expiring soonest if none did.
+func (c *Client) pruneEphemerals(now time.Time) {
+	var (
+		oldestID string
+		oldest   time.Time
+	)
+	for conversationID, conversation := range c.ephemerals {
+		if !now.Before(conversation.verifiedUntil) {
+			delete(c.ephemerals, conversationID)
+			continue
+		}
+		if oldestID == "" || conversation.verifiedUntil.Before(oldest) {
+			oldestID, oldest = conversationID, conversation.verifiedUntil
+		}
+	}
+	if len(c.ephemerals) >= maxEphemeralConversations {
+		delete(c.ephemerals, oldestID)
+	}
+}
+
+func (c *Client) PushEphemeral(ctx context.Context, data []byte) error {
+	return c.writeBinaryMsg(Resp{
+		ReqIdentifier: WSPushEphemeral,
+		OperationID:   mcontext.GetOperationID(ctx),
+		Data:          data,
+	})
+}
@@ -0,0 +1,91 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/OpenIMSDK/protocol/constant"
+)
+
+func TestEphemeralSignal(t *testing.T) {
+	single := EphemeralSignal{SendID: "u1", SessionType: constant.SingleChatType, RecvID: "u2", Type: EphemeralTyping}
+	if err := single.Check(); err != nil {
+		t.Fatal(err)
+	}
+	group := EphemeralSignal{SendID: "u1", SessionType: constant.SuperGroupChatType, Type: EphemeralRecording}
+	if err := group.Check(); err == nil {
+		t.Fatal("group signal without groupID must be rejected")
+	}
+
+	client := &Client{UserID: "u1"}
+	conversationID := single.conversationID()
+	if client.allowEphemeral(conversationID, 0.001, 1) {
+		t.Fatal("signals to unverified conversations must be rejected")
+	}
+	client.setEphemeralVerified(conversationID, time.Now())
+	client.setEphemeralVerified("sg_g1", time.Now())
+	if !client.allowEphemeral(conversationID, 0.001, 1) {
+		t.Fatal("first signal must be allowed")
+	}
+	if client.allowEphemeral(conversationID, 0.001, 1) {
+		t.Fatal("second signal must be rate limited")
+	}
+	if !client.allowEphemeral("sg_g1", 0.001, 1) {
+		t.Fatal("other conversations have their own bucket")
+	}
+}
+
+func TestEphemeralVerifyCache(t *testing.T) {
+	client := &Client{UserID: "u1"}
+	now := time.Now()
+	if client.ephemeralVerifiedAt("si_u1_u2", now) {
+		t.Fatal("unverified conversation must be checked")
+	}
+	client.setEphemeralVerified("si_u1_u2", now)
+	if !client.ephemeralVerifiedAt("si_u1_u2", now) {
+		t.Fatal("verification must be trusted within the ttl")
+	}
+	if client.ephemeralVerifiedAt("si_u1_u2", now.Add(ephemeralVerifyTTL)) {
+		t.Fatal("verification must expire after the ttl")
+	}
+	if client.ephemeralVerifiedAt("sg_g1", now) {
+		t.Fatal("other conversations are verified on their own")
+	}
+}
+
+func TestEphemeralConversationsBounded(t *testing.T) {
+	client := &Client{UserID: "u1"}
+	now := time.Now()
+	for i := 0; i < maxEphemeralConversations*2; i++ {
+		client.setEphemeralVerified("si_u1_"+strconv.Itoa(i), now.Add(time.Duration(i)*time.Millisecond))
+	}
+	if len(client.ephemerals) != maxEphemeralConversations {
+		t.Fatal("ephemeral conversations not bounded", len(client.ephemerals))
+	}
+	if !client.ephemeralVerifiedAt("si_u1_"+strconv.Itoa(maxEphemeralConversations*2-1), now) {
+		t.Fatal("the latest conversation must be kept")
+	}
+	if _, ok := client.ephemerals["si_u1_0"]; ok {
+		t.Fatal("the conversation expiring soonest must be dropped")
+	}
+	// once expired, all of them make room at once
+	client.setEphemeralVerified("sg_g1", now.Add(time.Hour))
+	if len(client.ephemerals) != 1 {
+		t.Fatal("expired conversations must be pruned", len(client.ephemerals))
+	}
+}
@@ -16,6 +16,7 @@ package msggateway
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/OpenIMSDK/tools/mcontext"
 
@@ -35,6 +36,7 @@ import (
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/prome"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/startrpc"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/gatewayext"
 )
 
 func (s *Server) InitServer(disCov discoveryregistry.SvcDiscoveryRegistry, server *grpc.Server) error {
@@ -45,7 +47,11 @@ func (s *Server) InitServer(disCov discoveryregistry.SvcDiscoveryRegistry, serve
 	msgModel := cache.NewMsgCacheModel(rdb)
 	s.LongConnServer.SetDiscoveryRegistry(disCov)
 	s.LongConnServer.SetCacheHandler(msgModel)
+	s.LongConnServer.SetRateLimitCache(cache.NewRateLimitCacheRedis(rdb))
+	s.LongConnServer.SetUserRouteCache(cache.NewUserRouteCacheRedis(rdb))
+	s.disCov = disCov
 	msggateway.RegisterMsgGatewayServer(server, s)
+	gatewayext.RegisterGatewayExtServer(server, s)
 	return nil
 }
 
@@ -63,15 +69,19 @@ type Server struct {
 	prometheusPort int
 	LongConnServer LongConnServer
 	pushTerminal   []int
+	disCov         discoveryregistry.SvcDiscoveryRegistry
+	// pushing number of push rpcs in progress, a drain waits for them
+	pushing int64
 }
 
 func (s *Server) SetLongConnServer(LongConnServer LongConnServer) {
 	s.LongConnServer = LongConnServer
 }
 
-func NewServer(rpcPort int, longConnServer LongConnServer) *Server {
+func NewServer(rpcPort int, prometheusPort int, longConnServer LongConnServer) *Server {
 	return &Server{
 		rpcPort:        rpcPort,
+		prometheusPort: prometheusPort,
 		LongConnServer: longConnServer,
 		pushTerminal:   []int{constant.IOSPlatformID, constant.AndroidPlatformID},
 	}
@@ -118,6 +128,57 @@ func (s *Server) GetUsersOnlineStatus(
 	return &resp, nil
 }
 
+// GetUserConns lists the conns of the users on this node.
+func (s *Server) GetUserConns(ctx context.Context, req *gatewayext.GetUserConnsReq) (*gatewayext.GetUserConnsResp, error) {
+	if err := authverify.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	node := s.disCov.GetSelfConnTarget()
+	var resp gatewayext.GetUserConnsResp
+	for _, userID := range req.UserIDs {
+		clients, ok := s.LongConnServer.GetUserAllCons(userID)
+		if !ok {
+			continue
+		}
+		for _, client := range clients {
+			if client == nil {
+				continue
+			}
+			resp.Conns = append(resp.Conns, client.connInfo(node))
+		}
+	}
+	return &resp, nil
+}
+
+// GetConnCount returns the number of online users and conns on this node.
+func (s *Server) GetConnCount(ctx context.Context, req *gatewayext.GetConnCountReq) (*gatewayext.GetConnCountResp, error) {
+	if err := authverify.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	userNum, connNum := s.LongConnServer.OnlineNum()
+	return &gatewayext.GetConnCountResp{
+		Node:    s.disCov.GetSelfConnTarget(),
+		UserNum: userNum,
+		ConnNum: connNum,
+	}, nil
+}
+
+// CloseConn closes a single conn, unlike KickUserOffline the other conns of the user stay.
+func (s *Server) CloseConn(ctx context.Context, req *gatewayext.CloseConnReq) (*gatewayext.CloseConnResp, error) {
+	if err := authverify.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	for _, client := range s.LongConnServer.GetAllClients() {
+		if client.connID() != req.ConnID {
+			continue
+		}
+		log.ZInfo(ctx, "close conn by admin", "userID", client.UserID, "connID", req.ConnID)
+		client.closeByAdmin()
+		return &gatewayext.CloseConnResp{Node: s.disCov.GetSelfConnTarget()}, nil
+	}
+	return nil, errs.ErrRecordNotFound.Wrap("conn not found " + req.ConnID)
+}
+
 func (s *Server) OnlineBatchPushOneMsg(
 	ctx context.Context,
 	req *msggateway.OnlineBatchPushOneMsgReq,
@@ -129,6 +190,8 @@ func (s *Server) SuperGroupOnlineBatchPushOneMsg(
 	ctx context.Context,
 	req *msggateway.OnlineBatchPushOneMsgReq,
 ) (*msggateway.OnlineBatchPushOneMsgResp, error) {
+	atomic.AddInt64(&s.pushing, 1)
+	defer atomic.AddInt64(&s.pushing, -1)
 	var singleUserResult []*msggateway.SingleMsgToUserResults
 	for _, v := range req.PushToUserIDs {
 		var resp []*msggateway.SingleMsgToUserPlatform
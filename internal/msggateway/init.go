@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/prome"
 )
 
 func RunWsAndServer(rpcPort, wsPort, prometheusPort int) error {
@@ -30,15 +31,41 @@ func RunWsAndServer(rpcPort, wsPort, prometheusPort int) error {
 		", OpenIM version: ",
 		config.Version,
 	)
+	var rateLimitRules []RateLimitRule
+	if config.Config.LongConnSvr.RateLimit.Enable {
+		for _, rule := range config.Config.LongConnSvr.RateLimit.Rules {
+			rateLimitRules = append(rateLimitRules, RateLimitRule{
+				ReqIdentifier: rule.ReqIdentifier,
+				ConnRate:      rule.ConnRate,
+				ConnBurst:     rule.ConnBurst,
+				UserRate:      rule.UserRate,
+				UserBurst:     rule.UserBurst,
+			})
+		}
+	}
 	longServer, err := NewWsServer(
 		WithPort(wsPort),
 		WithMaxConnNum(int64(config.Config.LongConnSvr.WebsocketMaxConnNum)),
 		WithHandshakeTimeout(time.Duration(config.Config.LongConnSvr.WebsocketTimeout)*time.Second),
-		WithMessageMaxMsgLength(config.Config.LongConnSvr.WebsocketMaxMsgLen))
+		WithMessageMaxMsgLength(config.Config.LongConnSvr.WebsocketMaxMsgLen),
+		WithCompressThreshold(config.Config.LongConnSvr.CompressThreshold),
+		WithPerMessageDeflate(config.Config.LongConnSvr.PerMessageDeflate),
+		WithResumeGracePeriod(time.Duration(config.Config.LongConnSvr.ResumeGracePeriod)*time.Second),
+		WithResumeBufferSize(config.Config.LongConnSvr.ResumeBufferSize),
+		WithPingInterval(time.Duration(config.Config.LongConnSvr.PingInterval)*time.Second),
+		WithUserRouteTTL(time.Duration(config.Config.LongConnSvr.UserRouteTTL)*time.Second),
+		WithRateLimit(config.Config.LongConnSvr.RateLimit.MaxViolations, rateLimitRules...),
+		WithEphemeralRateLimit(config.Config.LongConnSvr.Ephemeral.Rate, config.Config.LongConnSvr.Ephemeral.Burst))
 	if err != nil {
 		return err
 	}
-	hubServer := NewServer(rpcPort, longServer)
+	prome.NewMsgGatewayConnGauge()
+	prome.NewMsgGatewayPingCounter()
+	prome.NewMsgGatewayReapedConnCounter()
+	prome.NewMsgGatewayRateLimitedCounter()
+	prome.NewMsgGatewayAbuserDisconnectCounter()
+	hubServer := NewServer(rpcPort, prometheusPort, longServer)
+	go hubServer.drainOnSignal()
 	go func() {
 		err := hubServer.Start()
 		if err != nil {
@@ -49,10 +49,18 @@ type GWebSocket struct {
 	protocolType     int
 	conn             *websocket.Conn
 	handshakeTimeout time.Duration
+	// perMessageDeflate offer permessage-deflate to the client during the upgrade
+	perMessageDeflate bool
+	compressThreshold int
 }
 
-func newGWebSocket(protocolType int, handshakeTimeout time.Duration) *GWebSocket {
-	return &GWebSocket{protocolType: protocolType, handshakeTimeout: handshakeTimeout}
+func newGWebSocket(protocolType int, handshakeTimeout time.Duration, perMessageDeflate bool, compressThreshold int) *GWebSocket {
+	return &GWebSocket{
+		protocolType:      protocolType,
+		handshakeTimeout:  handshakeTimeout,
+		perMessageDeflate: perMessageDeflate,
+		compressThreshold: compressThreshold,
+	}
 }
 
 func (d *GWebSocket) Close() error {
@@ -61,8 +69,9 @@ func (d *GWebSocket) Close() error {
 
 func (d *GWebSocket) GenerateLongConn(w http.ResponseWriter, r *http.Request) error {
 	upgrader := &websocket.Upgrader{
-		HandshakeTimeout: d.handshakeTimeout,
-		CheckOrigin:      func(r *http.Request) bool { return true },
+		HandshakeTimeout:  d.handshakeTimeout,
+		CheckOrigin:       func(r *http.Request) bool { return true },
+		EnableCompression: d.perMessageDeflate,
 	}
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -74,6 +83,10 @@ func (d *GWebSocket) GenerateLongConn(w http.ResponseWriter, r *http.Request) er
 
 func (d *GWebSocket) WriteMessage(messageType int, message []byte) error {
 	// d.setSendConn(d.conn)
+	if d.perMessageDeflate {
+		// only takes effect when the client accepted permessage-deflate
+		d.conn.EnableWriteCompression(len(message) >= d.compressThreshold)
+	}
 	return d.conn.WriteMessage(messageType, message)
 }
 
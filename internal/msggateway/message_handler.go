@@ -37,6 +37,16 @@ type Req struct {
 	OperationID   string `json:"operationID"   validate:"required"`
 	MsgIncr       string `json:"msgIncr"       validate:"required"`
 	Data          []byte `json:"data"`
+	// encoder of the conn, it decides how Data is encoded
+	encoder Encoder
+}
+
+func (r *Req) unmarshalData(m proto.Message) error {
+	return unmarshalPayload(r.encoder, r.Data, m)
+}
+
+func (r *Req) marshalData(m proto.Message) ([]byte, error) {
+	return marshalPayload(r.encoder, m)
 }
 
 func (r *Req) String() string {
@@ -96,7 +106,7 @@ func NewGrpcHandler(validate *validator.Validate, client discoveryregistry.SvcDi
 
 func (g GrpcHandler) GetSeq(context context.Context, data Req) ([]byte, error) {
 	req := sdkws.GetMaxSeqReq{}
-	if err := proto.Unmarshal(data.Data, &req); err != nil {
+	if err := data.unmarshalData(&req); err != nil {
 		return nil, err
 	}
 	if err := g.validate.Struct(&req); err != nil {
@@ -106,7 +116,7 @@ func (g GrpcHandler) GetSeq(context context.Context, data Req) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	c, err := proto.Marshal(resp)
+	c, err := data.marshalData(resp)
 	if err != nil {
 		return nil, err
 	}
@@ -115,7 +125,7 @@ func (g GrpcHandler) GetSeq(context context.Context, data Req) ([]byte, error) {
 
 func (g GrpcHandler) SendMessage(context context.Context, data Req) ([]byte, error) {
 	msgData := sdkws.MsgData{}
-	if err := proto.Unmarshal(data.Data, &msgData); err != nil {
+	if err := data.unmarshalData(&msgData); err != nil {
 		return nil, err
 	}
 	if err := g.validate.Struct(&msgData); err != nil {
@@ -126,7 +136,7 @@ func (g GrpcHandler) SendMessage(context context.Context, data Req) ([]byte, err
 	if err != nil {
 		return nil, err
 	}
-	c, err := proto.Marshal(resp)
+	c, err := data.marshalData(resp)
 	if err != nil {
 		return nil, err
 	}
@@ -138,7 +148,7 @@ func (g GrpcHandler) SendSignalMessage(context context.Context, data Req) ([]byt
 	if err != nil {
 		return nil, err
 	}
-	c, err := proto.Marshal(resp)
+	c, err := data.marshalData(resp)
 	if err != nil {
 		return nil, err
 	}
@@ -147,7 +157,7 @@ func (g GrpcHandler) SendSignalMessage(context context.Context, data Req) ([]byt
 
 func (g GrpcHandler) PullMessageBySeqList(context context.Context, data Req) ([]byte, error) {
 	req := sdkws.PullMessageBySeqsReq{}
-	if err := proto.Unmarshal(data.Data, &req); err != nil {
+	if err := data.unmarshalData(&req); err != nil {
 		return nil, err
 	}
 	if err := g.validate.Struct(data); err != nil {
@@ -157,7 +167,7 @@ func (g GrpcHandler) PullMessageBySeqList(context context.Context, data Req) ([]
 	if err != nil {
 		return nil, err
 	}
-	c, err := proto.Marshal(resp)
+	c, err := data.marshalData(resp)
 	if err != nil {
 		return nil, err
 	}
@@ -166,14 +176,14 @@ func (g GrpcHandler) PullMessageBySeqList(context context.Context, data Req) ([]
 
 func (g GrpcHandler) UserLogout(context context.Context, data Req) ([]byte, error) {
 	req := push.DelUserPushTokenReq{}
-	if err := proto.Unmarshal(data.Data, &req); err != nil {
+	if err := data.unmarshalData(&req); err != nil {
 		return nil, err
 	}
 	resp, err := g.pushClient.DelUserPushToken(context, &req)
 	if err != nil {
 		return nil, err
 	}
-	c, err := proto.Marshal(resp)
+	c, err := data.marshalData(resp)
 	if err != nil {
 		return nil, err
 	}
@@ -182,7 +192,7 @@ func (g GrpcHandler) UserLogout(context context.Context, data Req) ([]byte, erro
 
 func (g GrpcHandler) SetUserDeviceBackground(_ context.Context, data Req) ([]byte, bool, error) {
 	req := sdkws.SetAppBackgroundStatusReq{}
-	if err := proto.Unmarshal(data.Data, &req); err != nil {
+	if err := data.unmarshalData(&req); err != nil {
 		return nil, false, err
 	}
 	if err := g.validate.Struct(data); err != nil {
@@ -32,6 +32,7 @@ import (
 
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/db/cache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/prome"
 
 	"github.com/redis/go-redis/v9"
 
@@ -55,6 +56,20 @@ type LongConnServer interface {
 	KickUserConn(client *Client) error
 	UnRegister(c *Client)
 	SetKickHandlerInfo(i *kickHandler)
+	heartbeat() (pingInterval, pongWait, writeWait time.Duration)
+	GetEncoder(encoding string) Encoder
+	GetCompressor(compression string) Compressor
+	GetAllClients() []*Client
+	OnlineNum() (userNum int64, connNum int64)
+	StartDrain() bool
+	migrateClient(ctx context.Context, client *Client) error
+	waitMigrations(ctx context.Context)
+	Shutdown(ctx context.Context) error
+	SetRateLimitCache(rateLimitCache cache.RateLimitCache)
+	SetUserRouteCache(userRouteCache cache.UserRouteCache)
+	checkRateLimit(ctx context.Context, client *Client, reqIdentifier int32) (allowed bool, disconnect bool)
+	PushEphemeral(ctx context.Context, userIDs []string, data []byte)
+	sendEphemeral(ctx context.Context, client *Client, req Req) ([]byte, error)
 	Compressor
 	Encoder
 	MessageHandler
@@ -67,21 +82,44 @@ var bufferPool = sync.Pool{
 }
 
 type WsServer struct {
-	port              int
-	wsMaxConnNum      int64
-	registerChan      chan *Client
-	unregisterChan    chan *Client
-	kickHandlerChan   chan *kickHandler
-	clients           *UserMap
-	clientPool        sync.Pool
-	onlineUserNum     int64
-	onlineUserConnNum int64
-	handshakeTimeout  time.Duration
-	hubServer         *Server
-	validate          *validator.Validate
-	cache             cache.MsgModel
-	userClient        *rpcclient.UserRpcClient
-	disCov            discoveryregistry.SvcDiscoveryRegistry
+	port                   int
+	wsMaxConnNum           int64
+	registerChan           chan *Client
+	unregisterChan         chan *Client
+	kickHandlerChan        chan *kickHandler
+	clients                *UserMap
+	clientPool             sync.Pool
+	onlineUserNum          int64
+	onlineUserConnNum      int64
+	handshakeTimeout       time.Duration
+	hubServer              *Server
+	validate               *validator.Validate
+	cache                  cache.MsgModel
+	userClient             *rpcclient.UserRpcClient
+	groupClient            *rpcclient.GroupRpcClient
+	friendClient           *rpcclient.FriendRpcClient
+	disCov                 discoveryregistry.SvcDiscoveryRegistry
+	encoders               map[string]Encoder
+	compressors            map[string]Compressor
+	perMessageDeflate      bool
+	compressThreshold      int
+	sseConns               sync.Map // connID -> *SSEConn
+	sessions               sync.Map // resume token -> detached *Client
+	resumeGracePeriod      time.Duration
+	resumeBufferSize       int
+	pingInterval           time.Duration
+	pongWait               time.Duration
+	writeWait              time.Duration
+	draining               int32
+	migrations             sync.WaitGroup
+	httpServer             *http.Server
+	rateLimitRules         map[int32]RateLimitRule
+	maxRateLimitViolations int
+	rateLimitCache         cache.RateLimitCache
+	ephemeralRate          float64
+	ephemeralBurst         int
+	userRouteTTL           time.Duration
+	userRouteCache         cache.UserRouteCache
 	Compressor
 	Encoder
 	MessageHandler
@@ -96,6 +134,10 @@ func (ws *WsServer) SetDiscoveryRegistry(disCov discoveryregistry.SvcDiscoveryRe
 	ws.MessageHandler = NewGrpcHandler(ws.validate, disCov)
 	u := rpcclient.NewUserRpcClient(disCov)
 	ws.userClient = &u
+	g := rpcclient.NewGroupRpcClient(disCov)
+	ws.groupClient = &g
+	f := rpcclient.NewFriendRpcClient(disCov)
+	ws.friendClient = &f
 	ws.disCov = disCov
 }
 
@@ -147,6 +189,23 @@ func NewWsServer(opts ...Option) (*WsServer, error) {
 		return nil, errors.New("port not allow to listen")
 	}
 	v := validator.New()
+	zstdCompressor, err := NewZstdCompressor()
+	if err != nil {
+		return nil, err
+	}
+	gzipCompressor := NewGzipCompressor()
+	connWriteWait, connPongWait := writeWait, pongWait
+	if config.handshakeTimeout > 0 {
+		connWriteWait = config.handshakeTimeout
+	}
+	if config.pingInterval > 0 {
+		// the pong of a ping must arrive within the write timeout
+		connPongWait = config.pingInterval + connWriteWait
+	}
+	rateLimitRules := make(map[int32]RateLimitRule, len(config.rateLimitRules))
+	for _, rule := range config.rateLimitRules {
+		rateLimitRules[rule.ReqIdentifier] = rule
+	}
 	return &WsServer{
 		port:             config.port,
 		wsMaxConnNum:     config.maxConnNum,
@@ -161,11 +220,74 @@ func NewWsServer(opts ...Option) (*WsServer, error) {
 		kickHandlerChan: make(chan *kickHandler, 1000),
 		validate:        v,
 		clients:         newUserMap(),
-		Compressor:      NewGzipCompressor(),
+		Compressor:      gzipCompressor,
 		Encoder:         NewGobEncoder(),
+		encoders: map[string]Encoder{
+			JsonEncoding:     NewJsonEncoder(),
+			ProtobufEncoding: NewProtobufEncoder(),
+		},
+		compressors: map[string]Compressor{
+			GzipCompressionProtocol:    gzipCompressor,
+			ZstdCompressionProtocol:    newThresholdCompressor(zstdCompressor, config.compressThreshold),
+			DeflateCompressionProtocol: newThresholdCompressor(NewDeflateCompressor(), config.compressThreshold),
+		},
+		perMessageDeflate:      config.perMessageDeflate,
+		compressThreshold:      config.compressThreshold,
+		resumeGracePeriod:      config.resumeGracePeriod,
+		resumeBufferSize:       config.resumeBufferSize,
+		pingInterval:           config.pingInterval,
+		pongWait:               connPongWait,
+		writeWait:              connWriteWait,
+		rateLimitRules:         rateLimitRules,
+		maxRateLimitViolations: config.maxRateLimitViolations,
+		ephemeralRate:          config.ephemeralRate,
+		ephemeralBurst:         config.ephemeralBurst,
+		userRouteTTL:           config.userRouteTTL,
 	}, nil
 }
 
+func (ws *WsServer) heartbeat() (pingInterval, pongWait, writeWait time.Duration) {
+	return ws.pingInterval, ws.pongWait, ws.writeWait
+}
+
+// reapStaleClients closes conns that read nothing, not even a pong, for longer than
+// the read deadline allows. Their read goroutine is expected to fail first, the reaper
+// only catches conns whose reads never return so they stop counting as online.
+func (ws *WsServer) reapStaleClients() {
+	interval := ws.pingInterval
+	if interval <= 0 {
+		interval = ws.pongWait
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		var stale []*Client
+		for _, client := range ws.clients.All() {
+			if client.isStale(now, ws.pongWait+ws.writeWait) {
+				stale = append(stale, client)
+			}
+		}
+		for _, client := range stale {
+			log.ZInfo(client.ctx, "reap stale conn", "userID", client.UserID, "platformID", client.PlatformID)
+			prome.Inc(prome.MsgGatewayReapedConnCounter)
+			client.shutdown(ErrIdleTimeout)
+		}
+	}
+}
+
+// GetCompressor returns the compressor negotiated by a client, nil for no compression.
+func (ws *WsServer) GetCompressor(compression string) Compressor {
+	return ws.compressors[compression]
+}
+
+// GetEncoder returns the encoder negotiated by a client, gob for unknown encodings.
+func (ws *WsServer) GetEncoder(encoding string) Encoder {
+	if encoder, ok := ws.encoders[encoding]; ok {
+		return encoder
+	}
+	return ws.Encoder
+}
+
 func (ws *WsServer) Run() error {
 	var client *Client
 	go func() {
@@ -180,9 +302,44 @@ func (ws *WsServer) Run() error {
 			}
 		}
 	}()
+	go ws.reapStaleClients()
 	http.HandleFunc("/", ws.wsHandler)
+	http.HandleFunc(ssePath, ws.sseHandler)
+	http.HandleFunc(sseSendPath, ws.sseSendHandler)
 	// http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {})
-	return http.ListenAndServe(":"+utils.IntToString(ws.port), nil) // Start listening
+	ws.httpServer = &http.Server{Addr: ":" + utils.IntToString(ws.port)}
+	err := ws.httpServer.ListenAndServe() // Start listening
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// GetAllClients returns the clients of every user on this node.
+func (ws *WsServer) GetAllClients() []*Client {
+	return ws.clients.All()
+}
+
+// OnlineNum returns the number of online users and conns on this node.
+func (ws *WsServer) OnlineNum() (userNum int64, connNum int64) {
+	return atomic.LoadInt64(&ws.onlineUserNum), atomic.LoadInt64(&ws.onlineUserConnNum)
+}
+
+// StartDrain stops accepting new conns, it returns false if the node is already draining.
+func (ws *WsServer) StartDrain() bool {
+	return atomic.CompareAndSwapInt32(&ws.draining, 0, 1)
+}
+
+func (ws *WsServer) isDraining() bool {
+	return atomic.LoadInt32(&ws.draining) == 1
+}
+
+// Shutdown stops the http server, Run returns once it is closed.
+func (ws *WsServer) Shutdown(ctx context.Context) error {
+	if ws.httpServer == nil {
+		return nil
+	}
+	return ws.httpServer.Shutdown(ctx)
 }
 
 func (ws *WsServer) sendUserOnlineInfoToOtherNode(ctx context.Context, client *Client) error {
@@ -190,6 +347,7 @@ func (ws *WsServer) sendUserOnlineInfoToOtherNode(ctx context.Context, client *C
 	if err != nil {
 		return err
 	}
+	conns = ws.userNodeConns(ctx, conns, client.UserID)
 	// Online push user online message to other node
 	for _, v := range conns {
 		if v.Target() == ws.disCov.GetSelfConnTarget() {
@@ -244,7 +402,9 @@ func (ws *WsServer) registerClient(client *Client) {
 			atomic.AddInt64(&ws.onlineUserConnNum, 1)
 		}
 	}
+	prome.GaugeInc(prome.MsgGatewayConnGauge)
 	ws.sendUserOnlineInfoToOtherNode(client.ctx, client)
+	ws.setUserRoute(client.ctx, client.UserID)
 	ws.SetUserOnlineStatus(client.ctx, client, constant.Online)
 	log.ZInfo(
 		client.ctx,
@@ -350,12 +510,23 @@ func (ws *WsServer) multiTerminalLoginChecker(clientOK bool, oldClients []*Clien
 }
 
 func (ws *WsServer) unregisterClient(client *Client) {
+	if client.isDetached() {
+		ws.detachClient(client)
+		return
+	}
 	defer ws.clientPool.Put(client)
 	isDeleteUser := ws.clients.delete(client.UserID, client.ctx.GetRemoteAddr())
 	if isDeleteUser {
 		atomic.AddInt64(&ws.onlineUserNum, -1)
+		ws.delUserRoute(client.ctx, client.UserID)
 	}
 	atomic.AddInt64(&ws.onlineUserConnNum, -1)
+	prome.GaugeDec(prome.MsgGatewayConnGauge)
+	if client.migrating {
+		// the client reconnects to another node, which reports it online, or migrateClient reports it offline
+		log.ZInfo(client.ctx, "user migrated", "online user Num", ws.onlineUserNum, "online user conn Num", ws.onlineUserConnNum)
+		return
+	}
 	ws.SetUserOnlineStatus(client.ctx, client, constant.Offline)
 	log.ZInfo(client.ctx, "user offline", "close reason", client.closedErr, "online user Num", ws.onlineUserNum, "online user conn Num",
 		ws.onlineUserConnNum,
@@ -364,81 +535,169 @@ func (ws *WsServer) unregisterClient(client *Client) {
 
 func (ws *WsServer) wsHandler(w http.ResponseWriter, r *http.Request) {
 	connContext := newContext(w, r)
+	if ws.isDraining() {
+		connContext.ErrReturn("msg gateway is draining", http.StatusServiceUnavailable)
+		return
+	}
 	if ws.onlineUserConnNum >= ws.wsMaxConnNum {
 		httpError(connContext, errs.ErrConnOverMaxNumLimit)
 		return
 	}
+	token, encoding, compression, err := ws.verifyConnArgs(connContext)
+	if err != nil {
+		httpError(connContext, err)
+		return
+	}
+	// permessage-deflate is only offered to clients without application level compression
+	wsLongConn := newGWebSocket(WebSocket, ws.handshakeTimeout, ws.perMessageDeflate && compression == "", ws.compressThreshold)
+	err = wsLongConn.GenerateLongConn(w, r)
+	if err != nil {
+		httpError(connContext, err)
+		return
+	}
+	ws.attachClient(connContext, wsLongConn, token, encoding, compression)
+}
+
+// verifyConnArgs checks the token of a connecting client and negotiates its encoding and compression.
+func (ws *WsServer) verifyConnArgs(connContext *UserConnContext) (token, encoding, compression string, err error) {
 	var (
-		token         string
 		userID        string
 		platformIDStr string
 		exists        bool
-		compression   bool
 	)
-
+	encoding = GobEncoding
 	token, exists = connContext.Query(Token)
 	if !exists {
-		httpError(connContext, errs.ErrConnArgsErr)
-		return
+		return "", "", "", errs.ErrConnArgsErr
 	}
 	userID, exists = connContext.Query(WsUserID)
 	if !exists {
-		httpError(connContext, errs.ErrConnArgsErr)
-		return
+		return "", "", "", errs.ErrConnArgsErr
 	}
 	platformIDStr, exists = connContext.Query(PlatformID)
 	if !exists {
-		httpError(connContext, errs.ErrConnArgsErr)
-		return
+		return "", "", "", errs.ErrConnArgsErr
 	}
 	platformID, err := strconv.Atoi(platformIDStr)
 	if err != nil {
-		httpError(connContext, errs.ErrConnArgsErr)
-		return
+		return "", "", "", errs.ErrConnArgsErr
 	}
 	if err := authverify.WsVerifyToken(token, userID, platformID); err != nil {
-		httpError(connContext, err)
-		return
+		return "", "", "", err
 	}
 	m, err := ws.cache.GetTokensWithoutError(context.Background(), userID, platformID)
 	if err != nil {
-		httpError(connContext, err)
-		return
+		return "", "", "", err
 	}
 	if v, ok := m[token]; ok {
 		switch v {
 		case constant.NormalToken:
 		case constant.KickedToken:
-			httpError(connContext, errs.ErrTokenKicked.Wrap())
-			return
+			return "", "", "", errs.ErrTokenKicked.Wrap()
 		default:
-			httpError(connContext, errs.ErrTokenUnknown.Wrap())
-			return
+			return "", "", "", errs.ErrTokenUnknown.Wrap()
 		}
 	} else {
-		httpError(connContext, errs.ErrTokenNotExist.Wrap())
-		return
+		return "", "", "", errs.ErrTokenNotExist.Wrap()
 	}
-	wsLongConn := newGWebSocket(WebSocket, ws.handshakeTimeout)
-	err = wsLongConn.GenerateLongConn(w, r)
+	if v, ok := connContext.Query(Encoding); ok {
+		encoding = v
+	} else if v, ok := connContext.GetHeader(Encoding); ok {
+		encoding = v
+	}
+	switch encoding {
+	case GobEncoding, JsonEncoding, ProtobufEncoding:
+	default:
+		return "", "", "", errs.ErrConnArgsErr.Wrap("unsupported encoding " + encoding)
+	}
+	if compressProtoc, exists := connContext.Query(Compression); exists && ws.GetCompressor(compressProtoc) != nil {
+		compression = compressProtoc
+	}
+	if compressProtoc, exists := connContext.GetHeader(Compression); exists && ws.GetCompressor(compressProtoc) != nil {
+		compression = compressProtoc
+	}
+	return token, encoding, compression, nil
+}
+
+// sseHandler opens an sse stream. Without connID a new client is registered,
+// with connID the stream resumes the existing session of that conn.
+func (ws *WsServer) sseHandler(w http.ResponseWriter, r *http.Request) {
+	connContext := newContext(w, r)
+	token, encoding, compression, err := ws.verifyConnArgs(connContext)
 	if err != nil {
 		httpError(connContext, err)
 		return
 	}
-	compressProtoc, exists := connContext.Query(Compression)
-	if exists {
-		if compressProtoc == GzipCompressionProtocol {
-			compression = true
-		}
+	var lastEventID uint64
+	if v, ok := connContext.GetHeader("Last-Event-ID"); ok {
+		lastEventID, _ = strconv.ParseUint(v, 10, 64)
+	} else if v, ok := connContext.Query(LastEventID); ok {
+		lastEventID, _ = strconv.ParseUint(v, 10, 64)
 	}
-	compressProtoc, exists = connContext.GetHeader(Compression)
-	if exists {
-		if compressProtoc == GzipCompressionProtocol {
-			compression = true
+	if connID, ok := connContext.Query(ConnID); ok {
+		conn, err := ws.getSSEConn(connID, token)
+		if err != nil {
+			httpError(connContext, err)
+			return
+		}
+		if err := conn.serve(w, r, lastEventID); err != nil {
+			log.ZDebug(connContext, "sse stream closed", "connID", connID, "err", err)
 		}
+		return
+	}
+	if ws.isDraining() {
+		connContext.ErrReturn("msg gateway is draining", http.StatusServiceUnavailable)
+		return
+	}
+	if ws.onlineUserConnNum >= ws.wsMaxConnNum {
+		httpError(connContext, errs.ErrConnOverMaxNumLimit)
+		return
+	}
+	connID := connContext.GetConnID()
+	sseConn := newSSEConn(connID, token, func() { ws.sseConns.Delete(connID) })
+	if err := sseConn.GenerateLongConn(w, r); err != nil {
+		httpError(connContext, err)
+		return
+	}
+	ws.sseConns.Store(connID, sseConn)
+	ws.attachClient(connContext, sseConn, token, encoding, compression)
+	if err := sseConn.serve(w, r, 0); err != nil {
+		log.ZDebug(connContext, "sse stream closed", "connID", connID, "err", err)
+	}
+}
+
+// sseSendHandler delivers one frame posted by an sse client to its conn.
+func (ws *WsServer) sseSendHandler(w http.ResponseWriter, r *http.Request) {
+	connContext := newContext(w, r)
+	if r.Method != http.MethodPost {
+		httpError(connContext, errs.ErrConnArgsErr.Wrap("method not allowed"))
+		return
+	}
+	connID, ok := connContext.Query(ConnID)
+	if !ok {
+		httpError(connContext, errs.ErrConnArgsErr)
+		return
+	}
+	conn, err := ws.getSSEConn(connID, connContext.GetToken())
+	if err != nil {
+		httpError(connContext, err)
+		return
+	}
+	if err := conn.receive(w, r); err != nil {
+		httpError(connContext, errs.ErrConnArgsErr.Wrap(err.Error()))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ws *WsServer) getSSEConn(connID, token string) (*SSEConn, error) {
+	v, ok := ws.sseConns.Load(connID)
+	if !ok {
+		return nil, errs.ErrConnArgsErr.Wrap("sse conn not found " + connID)
+	}
+	conn := v.(*SSEConn)
+	if conn.token != token {
+		return nil, errs.ErrTokenNotExist.Wrap()
 	}
-	client := ws.clientPool.Get().(*Client)
-	client.ResetClient(connContext, wsLongConn, connContext.GetBackground(), compression, ws, token)
-	ws.registerChan <- client
-	go client.readMessage()
+	return conn, nil
 }
@@ -27,6 +27,25 @@ type (
 		handshakeTimeout time.Duration
 		// 允许消息最大长度
 		messageMaxMsgLength int
+		// 小于该长度的消息不压缩
+		compressThreshold int
+		// 是否协商websocket permessage-deflate
+		perMessageDeflate bool
+		// 断线后保留会话等待重连的时间，0为不开启
+		resumeGracePeriod time.Duration
+		// 断线期间每个连接缓存的推送条数
+		resumeBufferSize int
+		// 服务端主动ping的间隔，0为不开启
+		pingInterval time.Duration
+		// 用户所在节点路由表的过期时间，0为不开启
+		userRouteTTL time.Duration
+		// 按请求类型限流
+		rateLimitRules []RateLimitRule
+		// 连续被限流多少次后断开连接，0为不断开
+		maxRateLimitViolations int
+		// 每个会话每秒允许的临时信令数，0为不限制
+		ephemeralRate  float64
+		ephemeralBurst int
 	}
 )
 
@@ -53,3 +72,53 @@ func WithMessageMaxMsgLength(length int) Option {
 		opt.messageMaxMsgLength = length
 	}
 }
+
+func WithCompressThreshold(threshold int) Option {
+	return func(opt *configs) {
+		opt.compressThreshold = threshold
+	}
+}
+
+func WithPerMessageDeflate(enable bool) Option {
+	return func(opt *configs) {
+		opt.perMessageDeflate = enable
+	}
+}
+
+func WithResumeGracePeriod(period time.Duration) Option {
+	return func(opt *configs) {
+		opt.resumeGracePeriod = period
+	}
+}
+
+func WithResumeBufferSize(size int) Option {
+	return func(opt *configs) {
+		opt.resumeBufferSize = size
+	}
+}
+
+func WithUserRouteTTL(ttl time.Duration) Option {
+	return func(opt *configs) {
+		opt.userRouteTTL = ttl
+	}
+}
+
+func WithPingInterval(interval time.Duration) Option {
+	return func(opt *configs) {
+		opt.pingInterval = interval
+	}
+}
+
+func WithRateLimit(maxViolations int, rules ...RateLimitRule) Option {
+	return func(opt *configs) {
+		opt.maxRateLimitViolations = maxViolations
+		opt.rateLimitRules = rules
+	}
+}
+
+func WithEphemeralRateLimit(rate float64, burst int) Option {
+	return func(opt *configs) {
+		opt.ephemeralRate = rate
+		opt.ephemeralBurst = burst
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/log"
+	"golang.org/x/time/rate"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/cache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/prome"
+)
+
+var (
+	ErrRateLimited = errs.NewCodeError(1603, "ConnRateLimited")
+	// ErrTooManyRequests closes a conn that keeps sending after being rate limited.
+	ErrTooManyRequests = errors.New("too many rate limited requests")
+)
+
+// RateLimitRule limits the requests of one ReqIdentifier, a rate of 0 disables that bucket.
+type RateLimitRule struct {
+	ReqIdentifier int32
+	// ConnRate requests per second of a single conn, ConnBurst the bucket size.
+	ConnRate  float64
+	ConnBurst int
+	// UserRate requests per second of all conns of a user on every node, UserBurst the bucket size.
+	UserRate  float64
+	UserBurst int
+}
+
+func (ws *WsServer) SetRateLimitCache(rateLimitCache cache.RateLimitCache) {
+	ws.rateLimitCache = rateLimitCache
+}
+
+// checkRateLimit takes a token from the conn and the user bucket of reqIdentifier.
+// disconnect is true once the client was rejected maxViolations times in a row.
+// It is only called from the read goroutine of client.
+func (ws *WsServer) checkRateLimit(ctx context.Context, client *Client, reqIdentifier int32) (allowed bool, disconnect bool) {
+	rule, ok := ws.rateLimitRules[reqIdentifier]
+	if !ok {
+		return true, false
+	}
+	allowed = true
+	if rule.ConnRate > 0 {
+		if client.rateLimiters == nil {
+			client.rateLimiters = make(map[int32]*rate.Limiter)
+		}
+		limiter, ok := client.rateLimiters[reqIdentifier]
+		if !ok {
+			limiter = rate.NewLimiter(rate.Limit(rule.ConnRate), rule.ConnBurst)
+			client.rateLimiters[reqIdentifier] = limiter
+		}
+		allowed = limiter.Allow()
+	}
+	if allowed && rule.UserRate > 0 && ws.rateLimitCache != nil {
+		key := client.UserID + ":" + strconv.Itoa(int(reqIdentifier))
+		var err error
+		allowed, err = ws.rateLimitCache.AllowRate(ctx, key, rule.UserRate, rule.UserBurst)
+		if err != nil {
+			// fail open, redis trouble must not cut every user off
+			log.ZWarn(ctx, "rate limit cache", err, "key", key)
+			allowed = true
+		}
+	}
+	if allowed {
+		client.rateLimitViolations = 0
+		return true, false
+	}
+	prome.Inc(prome.MsgGatewayRateLimitedCounter)
+	client.rateLimitViolations++
+	if ws.maxRateLimitViolations > 0 && client.rateLimitViolations >= ws.maxRateLimitViolations {
+		prome.Inc(prome.MsgGatewayAbuserDisconnectCounter)
+		return false, true
+	}
+	return false, false
+}
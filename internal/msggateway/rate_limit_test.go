@@ -0,0 +1,43 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCheckRateLimit(t *testing.T) {
+	ws := &WsServer{
+		rateLimitRules:         map[int32]RateLimitRule{WSSendMsg: {ReqIdentifier: WSSendMsg, ConnRate: 0.001, ConnBurst: 2}},
+		maxRateLimitViolations: 2,
+	}
+	client := &Client{UserID: "u1"}
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if allowed, _ := ws.checkRateLimit(ctx, client, WSSendMsg); !allowed {
+			t.Fatal("requests within the burst must be allowed")
+		}
+	}
+	if allowed, disconnect := ws.checkRateLimit(ctx, client, WSSendMsg); allowed || disconnect {
+		t.Fatal("expected rejection without disconnect", allowed, disconnect)
+	}
+	if allowed, disconnect := ws.checkRateLimit(ctx, client, WSSendMsg); allowed || !disconnect {
+		t.Fatal("expected disconnect after max violations", allowed, disconnect)
+	}
+	if allowed, _ := ws.checkRateLimit(ctx, client, WSGetNewestSeq); !allowed {
+		t.Fatal("requests without a rule must be allowed")
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/utils"
+)
+
+// replayBuffer keeps the latest pushes of a detached client, the oldest are
+// dropped when full and the client catches up by seq as it does after a fresh login.
+type replayBuffer struct {
+	resps []Resp
+	start int
+	size  int
+}
+
+func newReplayBuffer(capacity int) *replayBuffer {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &replayBuffer{resps: make([]Resp, capacity)}
+}
+
+func (b *replayBuffer) push(resp Resp) {
+	if b.size < len(b.resps) {
+		b.resps[(b.start+b.size)%len(b.resps)] = resp
+		b.size++
+		return
+	}
+	b.resps[b.start] = resp
+	b.start = (b.start + 1) % len(b.resps)
+}
+
+// drain returns the buffered pushes oldest first and empties the buffer.
+func (b *replayBuffer) drain() []Resp {
+	if b == nil || b.size == 0 {
+		return nil
+	}
+	resps := make([]Resp, 0, b.size)
+	for i := 0; i < b.size; i++ {
+		j := (b.start + i) % len(b.resps)
+		resps = append(resps, b.resps[j])
+		b.resps[j] = Resp{}
+	}
+	b.start, b.size = 0, 0
+	return resps
+}
+
+func newResumeToken() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return utils.OperationIDGenerator()
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// attachClient gives conn to the detached client of the resume token in the
+// request, or registers a new client when there is nothing to resume. Only the
+// clients connecting with resume=1 are kept online after their conn drops.
+func (ws *WsServer) attachClient(connContext *UserConnContext, conn LongConn, token, encoding, compression string) {
+	if resumeToken, ok := connContext.Query(ResumeToken); ok && ws.resumeGracePeriod > 0 {
+		if client := ws.claimSession(resumeToken, connContext, token); client != nil {
+			if client.resume(connContext, conn, compression, encoding, newResumeToken()) {
+				log.ZInfo(client.ctx, "user conn resumed", "userID", client.UserID, "platformID", client.PlatformID)
+				go client.readMessage()
+				return
+			}
+			// kicked or logged out while detached
+			client.expire()
+			ws.UnRegister(client)
+		}
+	}
+	client := ws.clientPool.Get().(*Client)
+	client.ResetClient(connContext, conn, connContext.GetBackground(), compression, ws, token, encoding)
+	if ws.resumeGracePeriod > 0 && connContext.GetResume() {
+		if err := client.enableResume(newResumeToken(), ws.resumeBufferSize); err != nil {
+			log.ZWarn(connContext, "write resume token", err)
+		}
+	}
+	ws.registerChan <- client
+	go client.readMessage()
+}
+
+// claimSession takes the detached client of resumeToken if it belongs to the connecting user.
+func (ws *WsServer) claimSession(resumeToken string, connContext *UserConnContext, token string) *Client {
+	v, ok := ws.sessions.Load(resumeToken)
+	if !ok {
+		return nil
+	}
+	client := v.(*Client)
+	if client.UserID != connContext.GetUserID() || client.PlatformID != utils.StringToInt(connContext.GetPlatformID()) ||
+		client.token != token {
+		return nil
+	}
+	if _, ok := ws.sessions.LoadAndDelete(resumeToken); !ok {
+		return nil
+	}
+	return client
+}
+
+// detachClient keeps a client whose conn dropped online for the resume grace period.
+// The client stays in the user map so pushes are buffered instead of going offline.
+func (ws *WsServer) detachClient(client *Client) {
+	resumeToken := client.resumeToken
+	ws.sessions.Store(resumeToken, client)
+	log.ZInfo(client.ctx, "user conn detached", "close reason", client.closedErr, "grace period", ws.resumeGracePeriod)
+	time.AfterFunc(ws.resumeGracePeriod, func() {
+		if _, ok := ws.sessions.LoadAndDelete(resumeToken); ok {
+			client.expire()
+			ws.UnRegister(client)
+		}
+	})
+}
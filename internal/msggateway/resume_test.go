@@ -0,0 +1,36 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import "testing"
+
+func TestReplayBuffer(t *testing.T) {
+	b := newReplayBuffer(3)
+	for i := 1; i <= 5; i++ {
+		b.push(Resp{ReqIdentifier: WSPushMsg, MsgIncr: string(rune('0' + i))})
+	}
+	resps := b.drain()
+	if len(resps) != 3 {
+		t.Fatal("expected 3 buffered pushes, got", len(resps))
+	}
+	for i, resp := range resps {
+		if want := string(rune('3' + i)); resp.MsgIncr != want {
+			t.Fatalf("resps[%d] = %s, want %s", i, resp.MsgIncr, want)
+		}
+	}
+	if len(b.drain()) != 0 {
+		t.Fatal("drain must empty the buffer")
+	}
+}
@@ -0,0 +1,344 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	errSSEReadTimeout   = errors.New("sse conn read timeout")
+	errSSEReplaced      = errors.New("sse stream replaced by a newer stream")
+	errSSEReplayGap     = errors.New("sse frames after last event id are no longer buffered")
+	errSSEDialNotAllow  = errors.New("sse conn does not support dial")
+	errSSENotStreamable = errors.New("response writer does not support flushing")
+)
+
+// Send sse comments at this period so proxies and dead streams are detected.
+var ssePingPeriod = 15 * time.Second
+
+type sseFrame struct {
+	id          uint64
+	messageType int
+	data        []byte
+}
+
+type sseInbound struct {
+	messageType int
+	data        []byte
+}
+
+// SSEConn is a LongConn for clients that cannot keep a websocket open.
+// Frames to the client are written as server-sent events on a streaming GET,
+// frames from the client arrive as POST bodies. The conn outlives a single
+// stream: a client reconnecting with its connID and Last-Event-ID resumes the
+// session and gets the frames it missed replayed from a bounded buffer.
+type SSEConn struct {
+	connID  string
+	token   string
+	inbound chan sseInbound
+	closed  chan struct{}
+	onClose func()
+	once    sync.Once
+
+	mu           sync.Mutex
+	frames       []sseFrame
+	nextID       uint64
+	wake         chan struct{}
+	replaced     chan struct{}
+	readDeadline time.Time
+	readLimit    int64
+	pongHandler  PongHandler
+	isNil        bool
+}
+
+func newSSEConn(connID, token string, onClose func()) *SSEConn {
+	return &SSEConn{
+		connID:   connID,
+		token:    token,
+		inbound:  make(chan sseInbound, sseInboundBuffer),
+		closed:   make(chan struct{}),
+		onClose:  onClose,
+		wake:     make(chan struct{}),
+		replaced: make(chan struct{}),
+	}
+}
+
+func (s *SSEConn) Close() error {
+	s.once.Do(func() {
+		close(s.closed)
+		if s.onClose != nil {
+			s.onClose()
+		}
+	})
+	return nil
+}
+
+// GenerateLongConn only checks that the response can be streamed, the stream itself is served by serve.
+func (s *SSEConn) GenerateLongConn(w http.ResponseWriter, r *http.Request) error {
+	if _, ok := w.(http.Flusher); !ok {
+		return errSSENotStreamable
+	}
+	return nil
+}
+
+func (s *SSEConn) WriteMessage(messageType int, message []byte) error {
+	if messageType == PingMessage {
+		// streams are kept alive by serve
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.closed:
+		return ErrConnClosed
+	default:
+	}
+	s.nextID++
+	data := make([]byte, len(message))
+	copy(data, message)
+	s.frames = append(s.frames, sseFrame{id: s.nextID, messageType: messageType, data: data})
+	if len(s.frames) > sseReplayBuffer {
+		s.frames = s.frames[len(s.frames)-sseReplayBuffer:]
+	}
+	close(s.wake)
+	s.wake = make(chan struct{})
+	return nil
+}
+
+func (s *SSEConn) ReadMessage() (int, []byte, error) {
+	for {
+		s.mu.Lock()
+		deadline := s.readDeadline
+		s.mu.Unlock()
+		timer := time.NewTimer(time.Until(deadline))
+		select {
+		case m := <-s.inbound:
+			timer.Stop()
+			return m.messageType, m.data, nil
+		case <-s.closed:
+			timer.Stop()
+			return 0, nil, ErrConnClosed
+		case <-timer.C:
+			s.mu.Lock()
+			extended := s.readDeadline.After(deadline)
+			s.mu.Unlock()
+			if !extended {
+				return 0, nil, errSSEReadTimeout
+			}
+		}
+	}
+}
+
+func (s *SSEConn) SetReadDeadline(timeout time.Duration) error {
+	s.mu.Lock()
+	s.readDeadline = time.Now().Add(timeout)
+	s.mu.Unlock()
+	return nil
+}
+
+// SetWriteDeadline is a no-op, writes are buffered and never block.
+func (s *SSEConn) SetWriteDeadline(timeout time.Duration) error {
+	return nil
+}
+
+func (s *SSEConn) Dial(urlStr string, requestHeader http.Header) (*http.Response, error) {
+	return nil, errSSEDialNotAllow
+}
+
+func (s *SSEConn) IsNil() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.isNil
+}
+
+func (s *SSEConn) SetConnNil() {
+	s.mu.Lock()
+	s.isNil = true
+	s.mu.Unlock()
+}
+
+func (s *SSEConn) SetReadLimit(limit int64) {
+	s.mu.Lock()
+	s.readLimit = limit
+	s.mu.Unlock()
+}
+
+func (s *SSEConn) SetPongHandler(handler PongHandler) {
+	s.mu.Lock()
+	s.pongHandler = handler
+	s.mu.Unlock()
+}
+
+// receive handles a POST from the client. An empty body is a heartbeat.
+func (s *SSEConn) receive(w http.ResponseWriter, r *http.Request) error {
+	s.mu.Lock()
+	limit, pongHandler := s.readLimit, s.pongHandler
+	s.mu.Unlock()
+	if limit > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r.Body); err != nil {
+		return err
+	}
+	if buf.Len() == 0 {
+		if pongHandler != nil {
+			return pongHandler("")
+		}
+		return nil
+	}
+	messageType := MessageBinary
+	if isTextContentType(r.Header.Get("Content-Type")) {
+		messageType = MessageText
+	}
+	timer := time.NewTimer(writeWait)
+	defer timer.Stop()
+	select {
+	case s.inbound <- sseInbound{messageType: messageType, data: buf.Bytes()}:
+		return nil
+	case <-s.closed:
+		return ErrConnClosed
+	case <-timer.C:
+		return errSSEReadTimeout
+	}
+}
+
+// serve streams frames after lastEventID to the client until the request ends,
+// the conn closes or a newer stream of the same conn takes over.
+func (s *SSEConn) serve(w http.ResponseWriter, r *http.Request, lastEventID uint64) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return errSSENotStreamable
+	}
+	s.mu.Lock()
+	close(s.replaced)
+	replaced := make(chan struct{})
+	s.replaced = replaced
+	s.mu.Unlock()
+	s.alive()
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(w, "event: conn\ndata: %s\n\n", s.connID); err != nil {
+		return err
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(ssePingPeriod)
+	defer ticker.Stop()
+	cursor := lastEventID
+	for {
+		s.mu.Lock()
+		frames, wake := s.pendingFrames(cursor), s.wake
+		gap := cursor < s.nextID && len(s.frames) > 0 && s.frames[0].id > cursor+1
+		s.mu.Unlock()
+		if gap {
+			// the client has to start a new session and sync by seq
+			_ = s.Close()
+			return errSSEReplayGap
+		}
+		for _, frame := range frames {
+			if err := writeSSEFrame(w, frame); err != nil {
+				return err
+			}
+			cursor = frame.id
+		}
+		if len(frames) > 0 {
+			flusher.Flush()
+			s.alive()
+		}
+		select {
+		case <-wake:
+		case <-ticker.C:
+			if _, err := w.Write([]byte(": ping\n\n")); err != nil {
+				return err
+			}
+			flusher.Flush()
+			s.alive()
+		case <-replaced:
+			return errSSEReplaced
+		case <-s.closed:
+			return nil
+		case <-r.Context().Done():
+			return r.Context().Err()
+		}
+	}
+}
+
+// alive is called after every successful write. A writable stream proves the client
+// is still there, so it counts like a pong and keeps a client that only listens from
+// being reaped as stale.
+func (s *SSEConn) alive() {
+	s.mu.Lock()
+	pongHandler := s.pongHandler
+	s.mu.Unlock()
+	if pongHandler != nil {
+		_ = pongHandler("")
+		return
+	}
+	_ = s.SetReadDeadline(pongWait)
+}
+
+// pendingFrames returns the buffered frames after cursor, s.mu must be held.
+func (s *SSEConn) pendingFrames(cursor uint64) []sseFrame {
+	for i, frame := range s.frames {
+		if frame.id > cursor {
+			return append([]sseFrame(nil), s.frames[i:]...)
+		}
+	}
+	return nil
+}
+
+func writeSSEFrame(w http.ResponseWriter, frame sseFrame) error {
+	var buf bytes.Buffer
+	buf.WriteString("id: ")
+	buf.WriteString(strconv.FormatUint(frame.id, 10))
+	buf.WriteByte('\n')
+	switch frame.messageType {
+	case MessageText:
+		buf.WriteString("event: text\n")
+		for _, line := range bytes.Split(frame.data, []byte{'\n'}) {
+			buf.WriteString("data: ")
+			buf.Write(line)
+			buf.WriteByte('\n')
+		}
+	case PongMessage:
+		buf.WriteString("event: pong\ndata: \n")
+	default:
+		buf.WriteString("event: binary\ndata: ")
+		buf.WriteString(base64.StdEncoding.EncodeToString(frame.data))
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func isTextContentType(contentType string) bool {
+	return strings.HasPrefix(contentType, "text/") || strings.HasPrefix(contentType, "application/json")
+}
@@ -0,0 +1,95 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSSEConnResume(t *testing.T) {
+	conn := newSSEConn("c1", "t", nil)
+	_ = conn.WriteMessage(MessageText, []byte(`{"a":1}`))
+	_ = conn.WriteMessage(MessageBinary, []byte{1, 2, 3})
+
+	body := serveSSE(t, conn, 1)
+	if strings.Contains(body, "id: 1\n") {
+		t.Fatalf("frame 1 replayed after last event id 1: %q", body)
+	}
+	if !strings.Contains(body, "id: 2\nevent: binary\ndata: AQID\n\n") {
+		t.Fatalf("frame 2 not replayed: %q", body)
+	}
+
+	for i := 0; i < sseReplayBuffer+1; i++ {
+		_ = conn.WriteMessage(MessageBinary, []byte{0})
+	}
+	serveSSE(t, conn, 2)
+	select {
+	case <-conn.closed:
+	default:
+		t.Fatal("conn must close when missed frames are no longer buffered")
+	}
+}
+
+func TestSSEConnReceive(t *testing.T) {
+	conn := newSSEConn("c1", "t", nil)
+	_ = conn.SetReadDeadline(time.Second)
+	r := httptest.NewRequest(http.MethodPost, sseSendPath, strings.NewReader(`{"reqIdentifier":1001}`))
+	r.Header.Set("Content-Type", "application/json")
+	if err := conn.receive(httptest.NewRecorder(), r); err != nil {
+		t.Fatal(err)
+	}
+	messageType, data, err := conn.ReadMessage()
+	if err != nil || messageType != MessageText || string(data) != `{"reqIdentifier":1001}` {
+		t.Fatal(messageType, string(data), err)
+	}
+	_ = conn.SetReadDeadline(10 * time.Millisecond)
+	if _, _, err := conn.ReadMessage(); err != errSSEReadTimeout {
+		t.Fatal("expected read timeout, got", err)
+	}
+}
+
+func serveSSE(t *testing.T, conn *SSEConn, lastEventID uint64) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	w := httptest.NewRecorder()
+	_ = conn.serve(w, httptest.NewRequest(http.MethodGet, ssePath, nil).WithContext(ctx), lastEventID)
+	return w.Body.String()
+}
+
+func TestSSEConnHeartbeatTouchesClient(t *testing.T) {
+	period := ssePingPeriod
+	ssePingPeriod = 5 * time.Millisecond
+	defer func() { ssePingPeriod = period }()
+
+	conn := newSSEConn("c1", "t", nil)
+	client := &Client{w: new(sync.Mutex), conn: conn, pongWait: time.Second}
+	conn.SetPongHandler(client.pongHandler)
+	idle := 40 * time.Millisecond
+	start := time.Now()
+	// the client never posts anything, only the heartbeats keep it active
+	serveSSE(t, conn, 0)
+	if client.isStale(time.Now(), idle) {
+		t.Fatal("idle sse client is stale although its stream is writable")
+	}
+	if client.isStale(start.Add(idle), idle) {
+		t.Fatal("sse client was not touched when the stream started")
+	}
+}
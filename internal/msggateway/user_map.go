@@ -118,3 +118,23 @@ func (u *UserMap) deleteClients(key string, clients []*Client) (isDeleteUser boo
 func (u *UserMap) DeleteAll(key string) {
 	u.m.Delete(key)
 }
+
+// UserIDs returns the users having at least one client.
+func (u *UserMap) UserIDs() []string {
+	var userIDs []string
+	u.m.Range(func(k, _ any) bool {
+		userIDs = append(userIDs, k.(string))
+		return true
+	})
+	return userIDs
+}
+
+// All returns the clients of every user.
+func (u *UserMap) All() []*Client {
+	var clients []*Client
+	u.m.Range(func(_, v any) bool {
+		clients = append(clients, v.([]*Client)...)
+		return true
+	})
+	return clients
+}
@@ -0,0 +1,106 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/mcontext"
+	"github.com/OpenIMSDK/tools/utils"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/cache"
+)
+
+// SetUserRouteCache enables the user route table and keeps the routes of this node fresh.
+func (ws *WsServer) SetUserRouteCache(userRouteCache cache.UserRouteCache) {
+	if ws.userRouteTTL <= 0 {
+		return
+	}
+	ws.userRouteCache = userRouteCache
+	go ws.refreshUserRoutes()
+}
+
+// refreshUserRoutes rewrites the routes of every online user of this node three times per ttl,
+// which also tells the pushers that the routes of this node can be trusted.
+func (ws *WsServer) refreshUserRoutes() {
+	ctx := mcontext.NewCtx(utils.GetSelfFuncName())
+	ticker := time.NewTicker(ws.userRouteTTL / 3)
+	defer ticker.Stop()
+	for {
+		// the node is known once registered, a draining node lets its routes
+		// expire and the pushers fall back to broadcasting meanwhile
+		if node := ws.disCov.GetSelfConnTarget(); node != "" && !ws.isDraining() {
+			userIDs := ws.clients.UserIDs()
+			if err := ws.userRouteCache.RefreshNode(ctx, node, userIDs, ws.userRouteTTL); err != nil {
+				log.ZWarn(ctx, "refresh user routes failed", err, "userNum", len(userIDs))
+			}
+		}
+		<-ticker.C
+	}
+}
+
+func (ws *WsServer) setUserRoute(ctx context.Context, userID string) {
+	if ws.userRouteCache == nil {
+		return
+	}
+	node := ws.disCov.GetSelfConnTarget()
+	if node == "" {
+		return
+	}
+	if err := ws.userRouteCache.SetUserNode(ctx, node, []string{userID}, ws.userRouteTTL); err != nil {
+		log.ZWarn(ctx, "set user route failed", err, "userID", userID)
+	}
+}
+
+func (ws *WsServer) delUserRoute(ctx context.Context, userID string) {
+	if ws.userRouteCache == nil {
+		return
+	}
+	node := ws.disCov.GetSelfConnTarget()
+	if node == "" {
+		return
+	}
+	if err := ws.userRouteCache.DelUserNode(ctx, node, userID); err != nil {
+		log.ZWarn(ctx, "del user route failed", err, "userID", userID)
+	}
+}
+
+// userNodeConns narrows conns down to the nodes userID is connected to,
+// all of conns are returned when the route table cannot be trusted.
+func (ws *WsServer) userNodeConns(ctx context.Context, conns []*grpc.ClientConn, userID string) []*grpc.ClientConn {
+	if ws.userRouteCache == nil {
+		return conns
+	}
+	nodes := make([]string, 0, len(conns))
+	for _, conn := range conns {
+		nodes = append(nodes, conn.Target())
+	}
+	nodeUsers, err := ws.userRouteCache.GetNodeUsers(ctx, nodes, []string{userID})
+	if err != nil {
+		log.ZDebug(ctx, "user route unavailable, broadcast", "err", err, "userID", userID)
+		return conns
+	}
+	var routed []*grpc.ClientConn
+	for _, conn := range conns {
+		if _, ok := nodeUsers[conn.Target()]; ok {
+			routed = append(routed, conn)
+		}
+	}
+	return routed
+}
@@ -0,0 +1,76 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgtransfer
+
+import (
+	"context"
+
+	"github.com/IBM/sarama"
+	"google.golang.org/protobuf/proto"
+
+	pbmsg "github.com/OpenIMSDK/protocol/msg"
+	"github.com/OpenIMSDK/tools/log"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/controller"
+	kfk "github.com/openimsdk/open-im-server/v3/pkg/common/kafka"
+)
+
+type ModifyMsgConsumerHandler struct {
+	modifyMsgConsumerGroup *kfk.MConsumerGroup
+	msgDatabase            controller.CommonMsgDatabase
+}
+
+func NewModifyMsgConsumerHandler(database controller.CommonMsgDatabase) *ModifyMsgConsumerHandler {
+	return &ModifyMsgConsumerHandler{
+		modifyMsgConsumerGroup: kfk.NewMConsumerGroup(&kfk.MConsumerGroupConfig{
+			KafkaVersion:   sarama.V2_0_0_0,
+			OffsetsInitial: sarama.OffsetNewest, IsReturnErr: false,
+		}, []string{config.Config.Kafka.MsgToModify.Topic},
+			config.Config.Kafka.Addr, config.Config.Kafka.ConsumerGroupID.MsgToModify),
+		msgDatabase: database,
+	}
+}
+
+func (mmc *ModifyMsgConsumerHandler) handleModifyMsg(ctx context.Context, cMsg *sarama.ConsumerMessage, key string) {
+	msgFromMQ := pbmsg.MsgDataToModifyByMQ{}
+	if err := proto.Unmarshal(cMsg.Value, &msgFromMQ); err != nil {
+		log.ZError(ctx, "unmarshall failed", err, "key", key, "len", len(cMsg.Value))
+		return
+	}
+	log.ZDebug(ctx, "modify consumer recv msg", "conversationID", msgFromMQ.ConversationID, "len", len(msgFromMQ.Messages))
+	if err := mmc.msgDatabase.PersistMsgReactions(ctx, msgFromMQ.ConversationID, msgFromMQ.Messages); err != nil {
+		log.ZError(ctx, "persist msg reactions err", err, "conversationID", msgFromMQ.ConversationID, "msgs", msgFromMQ.Messages)
+	}
+}
+
+func (ModifyMsgConsumerHandler) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
+func (ModifyMsgConsumerHandler) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
+
+func (mmc *ModifyMsgConsumerHandler) ConsumeClaim(
+	sess sarama.ConsumerGroupSession,
+	claim sarama.ConsumerGroupClaim,
+) error {
+	for msg := range claim.Messages() {
+		ctx := mmc.modifyMsgConsumerGroup.GetContextFromMsg(msg)
+		if len(msg.Value) != 0 {
+			mmc.handleModifyMsg(ctx, msg, string(msg.Key))
+		} else {
+			log.ZError(ctx, "modify msg get from kafka but is nil", nil, "conversationID", msg.Key)
+		}
+		sess.MarkMessage(msg, "")
+	}
+	return nil
+}
@@ -20,20 +20,44 @@ import (
 	"github.com/IBM/sarama"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/OpenIMSDK/protocol/constant"
 	pbmsg "github.com/OpenIMSDK/protocol/msg"
+	"github.com/OpenIMSDK/protocol/sdkws"
 	"github.com/OpenIMSDK/tools/log"
 
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/db/controller"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/searchindex"
 	kfk "github.com/openimsdk/open-im-server/v3/pkg/common/kafka"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/translator"
 )
 
+const (
+	// translateWorkers the most message batches translated at the same time.
+	translateWorkers = 8
+	// translateQueueSize the batches waiting for a worker, the ones beyond are only translated on demand.
+	translateQueueSize = 1024
+)
+
+type translateTask struct {
+	ctx            context.Context
+	conversationID string
+	msgs           []*sdkws.MsgData
+}
+
 type OnlineHistoryMongoConsumerHandler struct {
 	historyConsumerGroup *kfk.MConsumerGroup
 	msgDatabase          controller.CommonMsgDatabase
+	searchIndex          searchindex.SearchIndex
+	translator           translator.Translator
+	translateTasks       chan *translateTask
 }
 
-func NewOnlineHistoryMongoConsumerHandler(database controller.CommonMsgDatabase) *OnlineHistoryMongoConsumerHandler {
+func NewOnlineHistoryMongoConsumerHandler(
+	database controller.CommonMsgDatabase,
+	searchIndex searchindex.SearchIndex,
+	msgTranslator translator.Translator,
+) *OnlineHistoryMongoConsumerHandler {
 	mc := &OnlineHistoryMongoConsumerHandler{
 		historyConsumerGroup: kfk.NewMConsumerGroup(&kfk.MConsumerGroupConfig{
 			KafkaVersion:   sarama.V2_0_0_0,
@@ -41,6 +65,18 @@ func NewOnlineHistoryMongoConsumerHandler(database controller.CommonMsgDatabase)
 		}, []string{config.Config.Kafka.MsgToMongo.Topic},
 			config.Config.Kafka.Addr, config.Config.Kafka.ConsumerGroupID.MsgToMongo),
 		msgDatabase: database,
+		searchIndex: searchIndex,
+		translator:  msgTranslator,
+	}
+	if msgTranslator != nil && len(config.Config.Translation.AutoLanguages) > 0 {
+		mc.translateTasks = make(chan *translateTask, translateQueueSize)
+		for i := 0; i < translateWorkers; i++ {
+			go func() {
+				for task := range mc.translateTasks {
+					mc.translateMsgs(task.ctx, task.conversationID, task.msgs)
+				}
+			}()
+		}
 	}
 	return mc
 }
@@ -74,6 +110,9 @@ func (mc *OnlineHistoryMongoConsumerHandler) handleChatWs2Mongo(
 			"conversationID",
 			msgFromMQ.ConversationID,
 		)
+	} else {
+		mc.indexMsgs(ctx, msgFromMQ.ConversationID, msgFromMQ.MsgData)
+		mc.addTranslateTask(ctx, msgFromMQ.ConversationID, msgFromMQ.MsgData)
 	}
 	var seqs []int64
 	for _, msg := range msgFromMQ.MsgData {
@@ -94,6 +133,47 @@ func (mc *OnlineHistoryMongoConsumerHandler) handleChatWs2Mongo(
 	mc.msgDatabase.DelUserDeleteMsgsList(ctx, msgFromMQ.ConversationID, seqs)
 }
 
+func (mc *OnlineHistoryMongoConsumerHandler) indexMsgs(ctx context.Context, conversationID string, msgs []*sdkws.MsgData) {
+	if mc.searchIndex == nil {
+		return
+	}
+	docs := make([]*searchindex.Doc, 0, len(msgs))
+	for _, msg := range msgs {
+		if doc := searchindex.NewDoc(conversationID, msg); doc != nil {
+			docs = append(docs, doc)
+		}
+	}
+	if err := mc.searchIndex.Index(ctx, docs); err != nil {
+		log.ZError(ctx, "search index msgs err", err, "conversationID", conversationID, "len", len(docs))
+	}
+}
+
+// addTranslateTask queues msgs for translation without holding up the consumer.
+func (mc *OnlineHistoryMongoConsumerHandler) addTranslateTask(ctx context.Context, conversationID string, msgs []*sdkws.MsgData) {
+	if mc.translateTasks == nil {
+		return
+	}
+	select {
+	case mc.translateTasks <- &translateTask{ctx: ctx, conversationID: conversationID, msgs: msgs}:
+	default:
+		log.ZWarn(ctx, "translate queue is full", nil, "conversationID", conversationID, "len", len(msgs))
+	}
+}
+
+// translateMsgs translates the group text messages into the auto languages ahead of pulling.
+func (mc *OnlineHistoryMongoConsumerHandler) translateMsgs(ctx context.Context, conversationID string, msgs []*sdkws.MsgData) {
+	for _, msg := range msgs {
+		if msg.SessionType != constant.SuperGroupChatType || translator.MsgText(msg) == "" {
+			continue
+		}
+		for _, lang := range config.Config.Translation.AutoLanguages {
+			if _, err := translator.TranslateMsg(ctx, mc.translator, mc.msgDatabase, conversationID, msg, lang); err != nil {
+				log.ZWarn(ctx, "translate msg err", err, "conversationID", conversationID, "seq", msg.Seq, "lang", lang)
+			}
+		}
+	}
+}
+
 func (OnlineHistoryMongoConsumerHandler) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
 func (OnlineHistoryMongoConsumerHandler) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
 
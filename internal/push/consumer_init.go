@@ -32,6 +32,8 @@ func NewConsumer(pusher *Pusher) *Consumer {
 func (c *Consumer) initPrometheus() {
 	prome.NewMsgOfflinePushSuccessCounter()
 	prome.NewMsgOfflinePushFailedCounter()
+	prome.NewMsgOfflinePushRetryCounter()
+	prome.NewMsgOfflinePushDeadLetterCounter()
 }
 
 func (c *Consumer) Start() {
@@ -0,0 +1,300 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apns
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/utils"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/cache"
+)
+
+const (
+	ProductionHost  = "https://api.push.apple.com"
+	DevelopmentHost = "https://api.sandbox.push.apple.com"
+	// authTokenRefresh APNs rejects provider tokens older than an hour
+	// and ones that are renewed more than every 20 minutes.
+	authTokenRefresh = 50 * time.Minute
+	// concurrentPushes requests in flight on the HTTP/2 connection.
+	concurrentPushes = 32
+	requestTimeout   = 10 * time.Second
+)
+
+var Terminal = []int{constant.IOSPlatformID, constant.IPadPlatformID, constant.OSXPlatformID}
+
+// unregisteredReasons mean the token will never work again and is pruned.
+var unregisteredReasons = map[string]struct{}{
+	"BadDeviceToken":         {},
+	"Unregistered":           {},
+	"DeviceTokenNotForTopic": {},
+	"ExpiredToken":           {},
+}
+
+type Apns struct {
+	host       string
+	keyID      string
+	teamID     string
+	bundleID   string
+	key        *ecdsa.PrivateKey
+	httpClient *http.Client
+	cache      cache.MsgModel
+
+	lock        sync.Mutex
+	authToken   string
+	authTokenAt time.Time
+}
+
+func NewClient(cache cache.MsgModel) *Apns {
+	conf := config.Config.Push.Apns
+	pem, err := os.ReadFile(filepath.Join(config.Root, "config", conf.KeyFile))
+	if err != nil {
+		panic(err.Error())
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(pem)
+	if err != nil {
+		panic(err.Error())
+	}
+	host := DevelopmentHost
+	if config.Config.IOSPush.Production {
+		host = ProductionHost
+	}
+	return newApns(cache, host, conf.KeyID, conf.TeamID, conf.BundleID, key, &http.Client{
+		Transport: &http.Transport{ForceAttemptHTTP2: true},
+		Timeout:   requestTimeout,
+	})
+}
+
+func newApns(cache cache.MsgModel, host, keyID, teamID, bundleID string, key *ecdsa.PrivateKey, httpClient *http.Client) *Apns {
+	return &Apns{
+		host:       host,
+		keyID:      keyID,
+		teamID:     teamID,
+		bundleID:   bundleID,
+		key:        key,
+		httpClient: httpClient,
+		cache:      cache,
+	}
+}
+
+type alert struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+type aps struct {
+	Alert          alert  `json:"alert"`
+	Sound          string `json:"sound,omitempty"`
+	Badge          *int   `json:"badge,omitempty"`
+	MutableContent int    `json:"mutable-content,omitempty"`
+}
+
+type payload struct {
+	Aps aps    `json:"aps"`
+	Ex  string `json:"ex,omitempty"`
+}
+
+type errorResp struct {
+	Reason string `json:"reason"`
+}
+
+func (a *Apns) Push(ctx context.Context, userIDs []string, title, content string, opts *offlinepush.Opts) error {
+	var devices []*offlinepush.Device
+	for _, userID := range userIDs {
+		for _, platformID := range Terminal {
+			token, err := a.cache.GetApnsToken(ctx, userID, platformID)
+			if err == nil && token != "" {
+				devices = append(devices, &offlinepush.Device{
+					UserID:     userID,
+					PlatformID: int32(platformID),
+					Provider:   offlinepush.APNs,
+					Token:      token,
+				})
+			}
+		}
+	}
+	unregistered, err := a.PushDevices(ctx, devices, title, content, opts)
+	for _, d := range unregistered {
+		if err := a.cache.DelApnsToken(ctx, d.UserID, int(d.PlatformID)); err != nil {
+			log.ZWarn(ctx, "apns del token failed", err, "userID", d.UserID, "platformID", d.PlatformID)
+		}
+	}
+	return err
+}
+
+func (a *Apns) PushDevices(ctx context.Context, devices []*offlinepush.Device, title, content string,
+	opts *offlinepush.Opts,
+) ([]*offlinepush.Device, error) {
+	sound := opts.IOSPushSound
+	if sound == "" {
+		sound = config.Config.IOSPush.PushSound
+	}
+	var (
+		wg           sync.WaitGroup
+		lock         sync.Mutex
+		success      int
+		fail         int
+		lastErr      error
+		failed       []string
+		unregistered []*offlinepush.Device
+		limit        = make(chan struct{}, concurrentPushes)
+	)
+	var userIDs []string
+	userDevices := make(map[string][]*offlinepush.Device)
+	for _, d := range devices {
+		if _, ok := userDevices[d.UserID]; !ok {
+			userIDs = append(userIDs, d.UserID)
+		}
+		userDevices[d.UserID] = append(userDevices[d.UserID], d)
+	}
+	for _, userID := range userIDs {
+		devices := userDevices[userID]
+		badge, err := a.getBadge(ctx, userID, opts.IOSBadgeCount)
+		if err != nil {
+			log.ZWarn(ctx, "apns get badge failed", err, "userID", userID)
+			lock.Lock()
+			fail += len(devices)
+			lastErr = err
+			failed = append(failed, userID)
+			lock.Unlock()
+			continue
+		}
+		body, err := json.Marshal(payload{
+			Aps: aps{Alert: alert{Title: title, Body: content}, Sound: sound, Badge: badge, MutableContent: 1},
+			Ex:  opts.Ex,
+		})
+		if err != nil {
+			return nil, errs.Wrap(err)
+		}
+		for _, d := range devices {
+			wg.Add(1)
+			limit <- struct{}{}
+			go func(d *offlinepush.Device) {
+				defer func() {
+					<-limit
+					wg.Done()
+				}()
+				gone, err := a.send(ctx, d, body)
+				lock.Lock()
+				defer lock.Unlock()
+				if gone {
+					// a gone token is unregistered, retrying it is pointless
+					unregistered = append(unregistered, d)
+				}
+				if err != nil {
+					log.ZWarn(ctx, "apns push failed", err, "userID", d.UserID, "platformID", d.PlatformID)
+					fail++
+					if !gone {
+						lastErr = err
+						failed = append(failed, d.UserID)
+					}
+					return
+				}
+				success++
+			}(d)
+		}
+	}
+	wg.Wait()
+	log.ZDebug(ctx, "apns push", "success", success, "fail", fail, "unregistered", len(unregistered))
+	if lastErr != nil {
+		return unregistered, &offlinepush.FailedError{UserIDs: utils.Distinct(failed), Err: lastErr}
+	}
+	return unregistered, nil
+}
+
+// getBadge returns the app badge of userID, incremented for this push when incr is set.
+func (a *Apns) getBadge(ctx context.Context, userID string, incr bool) (*int, error) {
+	if incr {
+		count, err := a.cache.IncrUserBadgeUnreadCountSum(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		return &count, nil
+	}
+	count, err := a.cache.GetUserBadgeUnreadCountSum(ctx, userID)
+	if errors.Is(errs.Unwrap(err), redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &count, nil
+}
+
+// send posts body to the device, gone reports a token APNs will never accept again.
+func (a *Apns) send(ctx context.Context, d *offlinepush.Device, body []byte) (gone bool, err error) {
+	authToken, err := a.getAuthToken()
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.host+"/3/device/"+d.Token, bytes.NewReader(body))
+	if err != nil {
+		return false, errs.Wrap(err)
+	}
+	req.Header.Set("authorization", "bearer "+authToken)
+	req.Header.Set("apns-topic", a.bundleID)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	req.Header.Set("content-type", "application/json")
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return false, errs.Wrap(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return false, nil
+	}
+	var errResp errorResp
+	_ = json.NewDecoder(resp.Body).Decode(&errResp)
+	_, gone = unregisteredReasons[errResp.Reason]
+	gone = gone || resp.StatusCode == http.StatusGone
+	return gone, errs.Wrap(fmt.Errorf("apns status %d reason %s", resp.StatusCode, errResp.Reason))
+}
+
+// getAuthToken returns the ES256 provider token, renewed every authTokenRefresh.
+func (a *Apns) getAuthToken() (string, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.authToken != "" && time.Since(a.authTokenAt) < authTokenRefresh {
+		return a.authToken, nil
+	}
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{"iss": a.teamID, "iat": now.Unix()})
+	token.Header["kid"] = a.keyID
+	signed, err := token.SignedString(a.key)
+	if err != nil {
+		return "", errs.Wrap(err)
+	}
+	a.authToken, a.authTokenAt = signed, now
+	return signed, nil
+}
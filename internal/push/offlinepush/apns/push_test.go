@@ -0,0 +1,151 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apns
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/cache"
+)
+
+type tokenCache struct {
+	cache.MsgModel
+	lock   sync.Mutex
+	tokens map[string]string
+	badge  int
+}
+
+func (c *tokenCache) GetApnsToken(_ context.Context, account string, platformID int) (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	token, ok := c.tokens[account+":"+constant.PlatformIDToName(platformID)]
+	if !ok {
+		return "", redis.Nil
+	}
+	return token, nil
+}
+
+func (c *tokenCache) DelApnsToken(_ context.Context, account string, platformID int) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.tokens, account+":"+constant.PlatformIDToName(platformID))
+	return nil
+}
+
+func (c *tokenCache) IncrUserBadgeUnreadCountSum(context.Context, string) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.badge++
+	return c.badge, nil
+}
+
+func (c *tokenCache) GetUserBadgeUnreadCountSum(context.Context, string) (int, error) {
+	return 0, redis.Nil
+}
+
+func TestPush(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	var (
+		lock     sync.Mutex
+		payloads = make(map[string]payload)
+	)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, 2, r.ProtoMajor)
+		assert.Equal(t, "com.example.im", r.Header.Get("apns-topic"))
+		authToken := strings.TrimPrefix(r.Header.Get("authorization"), "bearer ")
+		parsed, err := jwt.Parse(authToken, func(*jwt.Token) (interface{}, error) { return &key.PublicKey, nil })
+		assert.Nil(t, err)
+		assert.Equal(t, "KEY123", parsed.Header["kid"])
+		token := strings.TrimPrefix(r.URL.Path, "/3/device/")
+		if token == "stale" {
+			w.WriteHeader(http.StatusGone)
+			_ = json.NewEncoder(w).Encode(errorResp{Reason: "Unregistered"})
+			return
+		}
+		var p payload
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&p))
+		lock.Lock()
+		payloads[token] = p
+		lock.Unlock()
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	c := &tokenCache{tokens: map[string]string{
+		"u1:" + constant.PlatformIDToName(constant.IOSPlatformID):  "good",
+		"u2:" + constant.PlatformIDToName(constant.IPadPlatformID): "stale",
+	}}
+	pusher := newApns(c, server.URL, "KEY123", "TEAM", "com.example.im", key, server.Client())
+	err = pusher.Push(context.Background(), []string{"u1", "u2", "u3"}, "title", "content",
+		&offlinepush.Opts{IOSPushSound: "ding.caf", IOSBadgeCount: true, Ex: "ex"})
+	assert.Nil(t, err)
+
+	assert.Len(t, payloads, 1)
+	p := payloads["good"]
+	assert.Equal(t, "title", p.Aps.Alert.Title)
+	assert.Equal(t, "content", p.Aps.Alert.Body)
+	assert.Equal(t, "ding.caf", p.Aps.Sound)
+	assert.NotNil(t, p.Aps.Badge)
+	assert.Equal(t, "ex", p.Ex)
+	_, err = c.GetApnsToken(context.Background(), "u2", constant.IPadPlatformID)
+	assert.Equal(t, redis.Nil, err)
+}
+
+func TestPushDevicesFailedUsers(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.URL.Path, "/3/device/") {
+		case "busy":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(errorResp{Reason: "ServiceUnavailable"})
+		case "stale":
+			w.WriteHeader(http.StatusGone)
+			_ = json.NewEncoder(w).Encode(errorResp{Reason: "Unregistered"})
+		}
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	pusher := newApns(&tokenCache{}, server.URL, "KEY123", "TEAM", "com.example.im", key, server.Client())
+	unregistered, err := pusher.PushDevices(context.Background(), []*offlinepush.Device{
+		{UserID: "u1", Token: "good"},
+		{UserID: "u2", Token: "busy"},
+		{UserID: "u3", Token: "stale"},
+	}, "title", "content", &offlinepush.Opts{IOSBadgeCount: true})
+	// the success of u1 does not hide the failure of u2, the gone token of u3 is not retried
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"u2"}, offlinepush.FailedUserIDs(err, nil))
+	assert.Len(t, unregistered, 1)
+	assert.Equal(t, "u3", unregistered[0].UserID)
+}
@@ -26,6 +26,9 @@ import (
 	"google.golang.org/api/option"
 
 	"github.com/OpenIMSDK/protocol/constant"
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/utils"
 
 	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
@@ -63,35 +66,77 @@ func NewClient(cache cache.MsgModel) *Fcm {
 
 func (f *Fcm) Push(ctx context.Context, userIDs []string, title, content string, opts *offlinepush.Opts) error {
 	// accounts->registrationToken
-	allTokens := make(map[string][]string, 0)
+	var devices []*offlinepush.Device
 	for _, account := range userIDs {
-		var personTokens []string
 		for _, v := range Terminal {
 			Token, err := f.cache.GetFcmToken(ctx, account, v)
 			if err == nil {
-				personTokens = append(personTokens, Token)
+				devices = append(devices, &offlinepush.Device{UserID: account, PlatformID: int32(v), Provider: offlinepush.FCM, Token: Token})
 			}
 		}
-		allTokens[account] = personTokens
+	}
+	unregistered, err := f.PushDevices(ctx, devices, title, content, opts)
+	for _, d := range unregistered {
+		if err := f.cache.DelFcmToken(ctx, d.UserID, int(d.PlatformID)); err != nil {
+			log.ZWarn(ctx, "fcm del token failed", err, "userID", d.UserID, "platformID", d.PlatformID)
+		}
+	}
+	return err
+}
+
+func (f *Fcm) PushDevices(ctx context.Context, devices []*offlinepush.Device, title, content string,
+	opts *offlinepush.Opts,
+) ([]*offlinepush.Device, error) {
+	var userIDs []string
+	allTokens := make(map[string][]*offlinepush.Device)
+	for _, d := range devices {
+		if _, ok := allTokens[d.UserID]; !ok {
+			userIDs = append(userIDs, d.UserID)
+		}
+		allTokens[d.UserID] = append(allTokens[d.UserID], d)
 	}
 	Success := 0
 	Fail := 0
+	var (
+		unregistered []*offlinepush.Device
+		failed       []string
+		lastErr      error
+	)
 	notification := &messaging.Notification{}
 	notification.Body = content
 	notification.Title = title
 	var messages []*messaging.Message
-	for userID, personTokens := range allTokens {
-		apns := &messaging.APNSConfig{Payload: &messaging.APNSPayload{Aps: &messaging.Aps{Sound: opts.IOSPushSound}}}
-		messageCount := len(messages)
-		if messageCount >= SinglePushCountLimit {
-			response, err := f.fcmMsgCli.SendAll(ctx, messages)
-			if err != nil {
-				Fail = Fail + messageCount
-			} else {
-				Success = Success + response.SuccessCount
-				Fail = Fail + response.FailureCount
+	var sent []*offlinepush.Device
+	sendAll := func() {
+		response, err := f.fcmMsgCli.SendAll(ctx, messages)
+		if err != nil {
+			Fail = Fail + len(messages)
+			lastErr = err
+			for _, d := range sent {
+				failed = append(failed, d.UserID)
+			}
+		} else {
+			Success = Success + response.SuccessCount
+			Fail = Fail + response.FailureCount
+			for i, r := range response.Responses {
+				switch {
+				case r.Error == nil:
+				case messaging.IsRegistrationTokenNotRegistered(r.Error):
+					unregistered = append(unregistered, sent[i])
+				default:
+					lastErr = r.Error
+					failed = append(failed, sent[i].UserID)
+				}
 			}
-			messages = messages[0:0]
+		}
+		messages = messages[0:0]
+		sent = sent[0:0]
+	}
+	for _, userID := range userIDs {
+		personTokens := allTokens[userID]
+		apns := &messaging.APNSConfig{Payload: &messaging.APNSPayload{Aps: &messaging.Aps{Sound: opts.IOSPushSound}}}
+		if len(messages) >= SinglePushCountLimit {
+			sendAll()
 		}
 		if opts.IOSBadgeCount {
 			unreadCountSum, err := f.cache.IncrUserBadgeUnreadCountSum(ctx, userID)
@@ -100,6 +145,8 @@ func (f *Fcm) Push(ctx context.Context, userIDs []string, title, content string,
 			} else {
 				// log.Error(operationID, "IncrUserBadgeUnreadCountSum redis err", err.Error(), uid)
 				Fail++
+				lastErr = err
+				failed = append(failed, userID)
 				continue
 			}
 		} else {
@@ -112,29 +159,28 @@ func (f *Fcm) Push(ctx context.Context, userIDs []string, title, content string,
 			} else {
 				// log.Error(operationID, "GetUserBadgeUnreadCountSum redis err", err.Error(), uid)
 				Fail++
+				lastErr = err
+				failed = append(failed, userID)
 				continue
 			}
 		}
-		for _, token := range personTokens {
+		for _, d := range personTokens {
 			temp := &messaging.Message{
 				Data:         map[string]string{"ex": opts.Ex},
-				Token:        token,
+				Token:        d.Token,
 				Notification: notification,
 				APNS:         apns,
 			}
 			messages = append(messages, temp)
+			sent = append(sent, d)
 		}
 	}
-	messageCount := len(messages)
-	if messageCount > 0 {
-		response, err := f.fcmMsgCli.SendAll(ctx, messages)
-		if err != nil {
-			Fail = Fail + messageCount
-			// log.Info(operationID, "some token push err", err.Error(), messageCount)
-		} else {
-			Success = Success + response.SuccessCount
-			Fail = Fail + response.FailureCount
-		}
+	if len(messages) > 0 {
+		sendAll()
+	}
+	log.ZDebug(ctx, "fcm push", "success", Success, "fail", Fail, "unregistered", len(unregistered))
+	if lastErr != nil {
+		return unregistered, &offlinepush.FailedError{UserIDs: utils.Distinct(failed), Err: errs.Wrap(lastErr)}
 	}
-	return nil
+	return unregistered, nil
 }
@@ -16,22 +16,87 @@ package offlinepush
 
 import (
 	"context"
+	"errors"
 )
 
-// OfflinePusher Offline Pusher.
+// OfflinePusher Offline Pusher. A Push that reached some of the users returns the
+// others in a FailedError, any other error means none of them was reached.
 type OfflinePusher interface {
 	Push(ctx context.Context, userIDs []string, title, content string, opts *Opts) error
 }
 
+// FailedError is returned by a pusher that did not reach every user, only
+// UserIDs have to be pushed again.
+type FailedError struct {
+	UserIDs []string
+	Err     error
+}
+
+func (e *FailedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FailedError) Unwrap() error {
+	return e.Err
+}
+
+// FailedUserIDs returns the users a failed push of userIDs did not reach, all of
+// userIDs unless err is a FailedError.
+func FailedUserIDs(err error, userIDs []string) []string {
+	var failedErr *FailedError
+	if errors.As(err, &failedErr) {
+		return failedErr.UserIDs
+	}
+	return userIDs
+}
+
 // Opts opts.
 type Opts struct {
-	Signal        *Signal
-	IOSPushSound  string
-	IOSBadgeCount bool
-	Ex            string
+	Signal        *Signal `json:"signal,omitempty"`
+	IOSPushSound  string  `json:"iosPushSound"`
+	IOSBadgeCount bool    `json:"iosBadgeCount"`
+	Ex            string  `json:"ex"`
+	Msg           *Msg    `json:"msg,omitempty"`
 }
 
 // Signal message id.
 type Signal struct {
-	ClientMsgID string
+	ClientMsgID string `json:"clientMsgID"`
+}
+
+// Msg describes the message being pushed.
+type Msg struct {
+	ConversationID string `json:"conversationID"`
+	ClientMsgID    string `json:"clientMsgID"`
+	ServerMsgID    string `json:"serverMsgID"`
+	SendID         string `json:"sendID"`
+	GroupID        string `json:"groupID,omitempty"`
+	SessionType    int32  `json:"sessionType"`
+	ContentType    int32  `json:"contentType"`
+	Seq            int64  `json:"seq"`
+	SendTime       int64  `json:"sendTime"`
+}
+
+const (
+	GeTui   = "getui"
+	FCM     = "fcm"
+	JPush   = "jpush"
+	APNs    = "apns"
+	Webhook = "webhook"
+)
+
+// Device a registered device token of a user.
+type Device struct {
+	UserID     string `json:"userID"`
+	PlatformID int32  `json:"platformID"`
+	Provider   string `json:"provider"`
+	Token      string `json:"token"`
+	AppVersion string `json:"appVersion"`
+	Locale     string `json:"locale"`
+}
+
+// DevicePusher is implemented by the pushers that address device tokens
+// directly, unregistered are the devices the provider reported gone.
+type DevicePusher interface {
+	PushDevices(ctx context.Context, devices []*Device, title, content string, opts *Opts) (unregistered []*Device, err error)
 }
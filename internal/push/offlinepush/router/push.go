@@ -0,0 +1,137 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package router
+
+import (
+	"context"
+
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/utils"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/controller"
+)
+
+// Router dispatches every registered device of the users to the provider it was
+// registered with, in a single Push.
+type Router struct {
+	providers map[string]offlinepush.OfflinePusher
+	// fallback pushes the users without a registered device, nil skips them
+	fallback offlinepush.OfflinePusher
+	devices  controller.PushDeviceDatabase
+}
+
+// NewClient routes devices to providers by name. Devices of a provider that is
+// not configured go to the webhook provider when there is one, so it can bridge
+// vendor channels such as huawei or xiaomi.
+func NewClient(providers map[string]offlinepush.OfflinePusher, fallback offlinepush.OfflinePusher,
+	devices controller.PushDeviceDatabase,
+) *Router {
+	return &Router{providers: providers, fallback: fallback, devices: devices}
+}
+
+// Push returns the users of the failed providers in an offlinepush.FailedError.
+func (r *Router) Push(ctx context.Context, userIDs []string, title, content string, opts *offlinepush.Opts) error {
+	devices, err := r.devices.FindUserDevices(ctx, userIDs)
+	if err != nil {
+		// none of the users is pushed, they are retried as a whole
+		return &offlinepush.FailedError{UserIDs: userIDs, Err: err}
+	}
+	var providers []string
+	routed := make(map[string][]*offlinepush.Device)
+	withDevice := make(map[string]struct{})
+	for _, device := range devices {
+		provider := device.Provider
+		if _, ok := r.providers[provider]; !ok {
+			if _, ok := r.providers[offlinepush.Webhook]; !ok {
+				log.ZDebug(ctx, "push provider not enabled", "provider", provider, "userID", device.UserID)
+				continue
+			}
+			provider = offlinepush.Webhook
+		}
+		if _, ok := routed[provider]; !ok {
+			providers = append(providers, provider)
+		}
+		routed[provider] = append(routed[provider], &offlinepush.Device{
+			UserID:     device.UserID,
+			PlatformID: device.PlatformID,
+			Provider:   device.Provider,
+			Token:      device.Token,
+			AppVersion: device.AppVersion,
+			Locale:     device.Locale,
+		})
+		withDevice[device.UserID] = struct{}{}
+	}
+	var (
+		lastErr error
+		failed  []string
+	)
+	for _, provider := range providers {
+		if err := r.push(ctx, provider, routed[provider], title, content, opts); err != nil {
+			log.ZWarn(ctx, "offline push failed", err, "provider", provider)
+			lastErr = err
+			failed = append(failed, offlinepush.FailedUserIDs(err, deviceUserIDs(routed[provider]))...)
+		}
+	}
+	if r.fallback != nil {
+		var rest []string
+		for _, userID := range userIDs {
+			if _, ok := withDevice[userID]; !ok {
+				rest = append(rest, userID)
+			}
+		}
+		if len(rest) > 0 {
+			if err := r.fallback.Push(ctx, rest, title, content, opts); err != nil {
+				log.ZWarn(ctx, "fallback offline push failed", err, "userIDs", rest)
+				lastErr = err
+				failed = append(failed, offlinepush.FailedUserIDs(err, rest)...)
+			}
+		}
+	}
+	if lastErr != nil {
+		// a user whose devices span providers is pushed again on all of them
+		return &offlinepush.FailedError{UserIDs: utils.Distinct(failed), Err: lastErr}
+	}
+	return nil
+}
+
+func deviceUserIDs(devices []*offlinepush.Device) []string {
+	return utils.Distinct(utils.Slice(devices, func(d *offlinepush.Device) string { return d.UserID }))
+}
+
+// push hands the devices to a provider addressing tokens, the others get the users
+// and look their tokens up themselves.
+func (r *Router) push(ctx context.Context, provider string, devices []*offlinepush.Device, title, content string,
+	opts *offlinepush.Opts,
+) error {
+	pusher := r.providers[provider]
+	devicePusher, ok := pusher.(offlinepush.DevicePusher)
+	if !ok {
+		return pusher.Push(ctx, deviceUserIDs(devices), title, content, opts)
+	}
+	unregistered, err := devicePusher.PushDevices(ctx, devices, title, content, opts)
+	tokens := make(map[string][]string)
+	for _, d := range unregistered {
+		tokens[d.Provider] = append(tokens[d.Provider], d.Token)
+	}
+	for name, ts := range tokens {
+		if err := r.devices.UnregisterDevices(ctx, name, ts); err != nil {
+			log.ZWarn(ctx, "unregister push devices failed", err, "provider", name)
+		} else {
+			log.ZInfo(ctx, "unregistered gone push devices", "provider", name, "num", len(ts))
+		}
+	}
+	return err
+}
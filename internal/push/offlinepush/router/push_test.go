@@ -0,0 +1,148 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package router
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/controller"
+	relationtb "github.com/openimsdk/open-im-server/v3/pkg/common/db/table/relation"
+)
+
+type deviceDatabase struct {
+	controller.PushDeviceDatabase
+	devices      []*relationtb.PushDeviceModel
+	unregistered map[string][]string
+	err          error
+}
+
+func (d *deviceDatabase) FindUserDevices(_ context.Context, userIDs []string) ([]*relationtb.PushDeviceModel, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	var devices []*relationtb.PushDeviceModel
+	for _, device := range d.devices {
+		for _, userID := range userIDs {
+			if device.UserID == userID {
+				devices = append(devices, device)
+			}
+		}
+	}
+	return devices, nil
+}
+
+func (d *deviceDatabase) UnregisterDevices(_ context.Context, provider string, tokens []string) error {
+	d.unregistered[provider] = append(d.unregistered[provider], tokens...)
+	return nil
+}
+
+type userPusher struct {
+	userIDs []string
+	err     error
+}
+
+func (p *userPusher) Push(_ context.Context, userIDs []string, _, _ string, _ *offlinepush.Opts) error {
+	p.userIDs = append(p.userIDs, userIDs...)
+	return p.err
+}
+
+type devicePusher struct {
+	userPusher
+	devices []*offlinepush.Device
+}
+
+func (p *devicePusher) PushDevices(_ context.Context, devices []*offlinepush.Device, _, _ string,
+	_ *offlinepush.Opts,
+) ([]*offlinepush.Device, error) {
+	p.devices = append(p.devices, devices...)
+	var gone []*offlinepush.Device
+	for _, d := range devices {
+		if d.Token == "gone" {
+			gone = append(gone, d)
+		}
+	}
+	return gone, nil
+}
+
+func TestPush(t *testing.T) {
+	db := &deviceDatabase{
+		devices: []*relationtb.PushDeviceModel{
+			{UserID: "u1", Provider: offlinepush.APNs, Token: "ios1", PlatformID: 1},
+			{UserID: "u1", Provider: offlinepush.FCM, Token: "android1", PlatformID: 2},
+			{UserID: "u2", Provider: offlinepush.APNs, Token: "gone", PlatformID: 1},
+			{UserID: "u3", Provider: "huawei", Token: "hw3", PlatformID: 2},
+			{UserID: "u3", Provider: offlinepush.GeTui, Token: "getui3", PlatformID: 2},
+		},
+		unregistered: make(map[string][]string),
+	}
+	apns, webhook := &devicePusher{}, &devicePusher{}
+	fcm, fallback := &userPusher{}, &userPusher{}
+	r := NewClient(map[string]offlinepush.OfflinePusher{
+		offlinepush.APNs:    apns,
+		offlinepush.FCM:     fcm,
+		offlinepush.Webhook: webhook,
+	}, fallback, db)
+
+	err := r.Push(context.Background(), []string{"u1", "u2", "u3", "u4"}, "title", "content", &offlinepush.Opts{})
+	assert.Nil(t, err)
+	assert.Len(t, apns.devices, 2)
+	assert.Equal(t, []string{"u1"}, fcm.userIDs)
+	// huawei and the not started getui are bridged by the webhook
+	assert.Len(t, webhook.devices, 2)
+	assert.Equal(t, "huawei", webhook.devices[0].Provider)
+	assert.Equal(t, []string{"u4"}, fallback.userIDs)
+	assert.Equal(t, map[string][]string{offlinepush.APNs: {"gone"}}, db.unregistered)
+}
+
+func TestPushFailedUsers(t *testing.T) {
+	db := &deviceDatabase{
+		devices: []*relationtb.PushDeviceModel{
+			{UserID: "u1", Provider: offlinepush.APNs, Token: "ios1", PlatformID: 1},
+			{UserID: "u2", Provider: offlinepush.FCM, Token: "android2", PlatformID: 2},
+			{UserID: "u3", Provider: offlinepush.FCM, Token: "android3", PlatformID: 2},
+		},
+		unregistered: make(map[string][]string),
+	}
+	failErr := errors.New("provider down")
+	apns := &devicePusher{}
+	fcm := &userPusher{err: &offlinepush.FailedError{UserIDs: []string{"u3"}, Err: failErr}}
+	fallback := &userPusher{err: failErr}
+	r := NewClient(map[string]offlinepush.OfflinePusher{
+		offlinepush.APNs: apns,
+		offlinepush.FCM:  fcm,
+	}, fallback, db)
+
+	err := r.Push(context.Background(), []string{"u1", "u2", "u3", "u4"}, "title", "content", &offlinepush.Opts{})
+	assert.ErrorIs(t, err, failErr)
+	// u1 went through apns, u2 through fcm, u4 has no device and the fallback failed entirely
+	assert.ElementsMatch(t, []string{"u3", "u4"}, offlinepush.FailedUserIDs(err, nil))
+}
+
+func TestPushFindDevicesFailed(t *testing.T) {
+	findErr := errors.New("mysql down")
+	fallback := &userPusher{}
+	r := NewClient(map[string]offlinepush.OfflinePusher{offlinepush.APNs: &devicePusher{}}, fallback,
+		&deviceDatabase{err: findErr})
+
+	err := r.Push(context.Background(), []string{"u1", "u2"}, "title", "content", &offlinepush.Opts{})
+	assert.ErrorIs(t, err, findErr)
+	assert.Equal(t, []string{"u1", "u2"}, offlinepush.FailedUserIDs(err, nil))
+	assert.Empty(t, fallback.userIDs)
+}
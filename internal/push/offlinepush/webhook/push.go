@@ -0,0 +1,212 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/utils"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	http2 "github.com/openimsdk/open-im-server/v3/pkg/common/http"
+)
+
+const (
+	SignatureHeader = "X-OpenIM-Signature"
+	TimestampHeader = "X-OpenIM-Timestamp"
+
+	// maxRetryWait caps the time a batch waits between its retries, the push
+	// consumer is blocked meanwhile. Longer failures are left to the push retry queue.
+	maxRetryWait = 5 * time.Second
+)
+
+// Payload is posted to the webhook for every batch of users. Devices is set
+// when the registered devices of the users are pushed, the provider of a device
+// names the vendor channel the webhook bridges to.
+type Payload struct {
+	UserIDs []string              `json:"userIDs"`
+	Devices []*offlinepush.Device `json:"devices,omitempty"`
+	Title   string                `json:"title"`
+	Content string                `json:"content"`
+	Opts    *offlinepush.Opts     `json:"opts"`
+}
+
+// Resp is expected back from the webhook, a non zero ErrCode is retried.
+// UnregisteredTokens lists the device tokens the vendor reported gone.
+type Resp struct {
+	ErrCode            int32    `json:"errCode"`
+	ErrMsg             string   `json:"errMsg"`
+	UnregisteredTokens []string `json:"unregisteredTokens,omitempty"`
+}
+
+type Webhook struct {
+	url           string
+	secret        string
+	timeout       int
+	batchSize     int
+	retryTimes    int
+	retryInterval time.Duration
+}
+
+func NewClient() *Webhook {
+	conf := config.Config.Push.Webhook
+	retryTimes := conf.RetryTimes
+	if config.Config.Push.Retry.Enable {
+		// the retry queue pushes the failed users again without blocking the consumer
+		retryTimes = 0
+	}
+	return newWebhook(conf.Url, conf.Secret, conf.Timeout, conf.BatchSize, retryTimes,
+		time.Duration(conf.RetryInterval)*time.Millisecond)
+}
+
+func newWebhook(url, secret string, timeout, batchSize, retryTimes int, retryInterval time.Duration) *Webhook {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &Webhook{
+		url:           url,
+		secret:        secret,
+		timeout:       timeout,
+		batchSize:     batchSize,
+		retryTimes:    retryTimes,
+		retryInterval: retryInterval,
+	}
+}
+
+// Push posts userIDs in batches, the users of the failed batches are returned in an offlinepush.FailedError.
+func (w *Webhook) Push(ctx context.Context, userIDs []string, title, content string, opts *offlinepush.Opts) error {
+	var (
+		lastErr error
+		failed  []string
+	)
+	for i := 0; i < len(userIDs); i += w.batchSize {
+		end := i + w.batchSize
+		if end > len(userIDs) {
+			end = len(userIDs)
+		}
+		payload := &Payload{UserIDs: userIDs[i:end], Title: title, Content: content, Opts: opts}
+		if _, err := w.postWithRetry(ctx, payload); err != nil {
+			log.ZWarn(ctx, "webhook push failed", err, "url", w.url, "userIDs", payload.UserIDs)
+			lastErr = err
+			failed = append(failed, payload.UserIDs...)
+		}
+	}
+	if lastErr != nil {
+		return &offlinepush.FailedError{UserIDs: failed, Err: lastErr}
+	}
+	return nil
+}
+
+func (w *Webhook) PushDevices(ctx context.Context, devices []*offlinepush.Device, title, content string,
+	opts *offlinepush.Opts,
+) ([]*offlinepush.Device, error) {
+	var (
+		lastErr      error
+		failed       []string
+		unregistered []*offlinepush.Device
+	)
+	for i := 0; i < len(devices); i += w.batchSize {
+		end := i + w.batchSize
+		if end > len(devices) {
+			end = len(devices)
+		}
+		payload := &Payload{Devices: devices[i:end], Title: title, Content: content, Opts: opts}
+		for _, d := range payload.Devices {
+			if !utils.Contain(d.UserID, payload.UserIDs...) {
+				payload.UserIDs = append(payload.UserIDs, d.UserID)
+			}
+		}
+		resp, err := w.postWithRetry(ctx, payload)
+		if err != nil {
+			log.ZWarn(ctx, "webhook push failed", err, "url", w.url, "userIDs", payload.UserIDs)
+			lastErr = err
+			failed = append(failed, payload.UserIDs...)
+			continue
+		}
+		for _, d := range payload.Devices {
+			if utils.Contain(d.Token, resp.UnregisteredTokens...) {
+				unregistered = append(unregistered, d)
+			}
+		}
+	}
+	if lastErr != nil {
+		return unregistered, &offlinepush.FailedError{UserIDs: utils.Distinct(failed), Err: lastErr}
+	}
+	return unregistered, nil
+}
+
+// postWithRetry posts payload up to retryTimes+1 times, doubling the wait after each failure.
+// It gives up early once the waits would exceed maxRetryWait or ctx is done.
+func (w *Webhook) postWithRetry(ctx context.Context, payload *Payload) (*Resp, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	var waited time.Duration
+	interval := w.retryInterval
+	for attempt := 0; ; attempt++ {
+		resp, err := w.post(ctx, body)
+		if err == nil || attempt >= w.retryTimes || waited+interval > maxRetryWait {
+			return resp, err
+		}
+		log.ZDebug(ctx, "webhook push retry", "attempt", attempt+1, "err", err)
+		select {
+		case <-ctx.Done():
+			return nil, errs.Wrap(ctx.Err())
+		case <-time.After(interval):
+		}
+		waited += interval
+		interval *= 2
+	}
+}
+
+func (w *Webhook) post(ctx context.Context, body []byte) (*Resp, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	header := map[string]string{
+		TimestampHeader: timestamp,
+		SignatureHeader: Sign(w.secret, timestamp, body),
+	}
+	b, err := http2.Post(ctx, w.url, header, json.RawMessage(body), w.timeout)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	var resp Resp
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return nil, errs.Wrap(err, string(b))
+	}
+	if resp.ErrCode != 0 {
+		return nil, errs.Wrap(fmt.Errorf("webhook errCode %d errMsg %s", resp.ErrCode, resp.ErrMsg))
+	}
+	return &resp, nil
+}
+
+// Sign returns the hex hmac-sha256 of "timestamp.body", receivers recompute it to authenticate a push.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
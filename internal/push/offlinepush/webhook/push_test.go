@@ -0,0 +1,114 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush"
+)
+
+func TestPush(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		attempts int
+		batches  [][]string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, Sign("secret", r.Header.Get(TimestampHeader), body), r.Header.Get(SignatureHeader))
+		lock.Lock()
+		defer lock.Unlock()
+		attempts++
+		if attempts == 1 {
+			_ = json.NewEncoder(w).Encode(Resp{ErrCode: 500, ErrMsg: "busy"})
+			return
+		}
+		var payload Payload
+		assert.Nil(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, "title <b>", payload.Title)
+		assert.Equal(t, "conversationID", payload.Opts.Msg.ConversationID)
+		batches = append(batches, payload.UserIDs)
+		_ = json.NewEncoder(w).Encode(Resp{})
+	}))
+	defer server.Close()
+
+	w := newWebhook(server.URL, "secret", 1, 2, 1, time.Millisecond)
+	err := w.Push(context.Background(), []string{"u1", "u2", "u3"}, "title <b>", "content",
+		&offlinepush.Opts{Msg: &offlinepush.Msg{ConversationID: "conversationID"}})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, [][]string{{"u1", "u2"}, {"u3"}}, batches)
+}
+
+func TestPushRetryExhausted(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	w := newWebhook(server.URL, "secret", 1, 10, 2, time.Millisecond)
+	err := w.Push(context.Background(), []string{"u1"}, "title", "content", &offlinepush.Opts{})
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestPushFailedBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload Payload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if payload.UserIDs[0] == "u3" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Resp{})
+	}))
+	defer server.Close()
+
+	w := newWebhook(server.URL, "secret", 1, 2, 0, time.Millisecond)
+	userIDs := []string{"u1", "u2", "u3", "u4", "u5"}
+	err := w.Push(context.Background(), userIDs, "title", "content", &offlinepush.Opts{})
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"u3", "u4"}, offlinepush.FailedUserIDs(err, userIDs))
+}
+
+func TestPushRetryWaitCapped(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	// 2s, 4s, 8s... only the first two waits fit into maxRetryWait
+	w := newWebhook(server.URL, "secret", 1, 10, 10, 2*time.Second)
+	start := time.Now()
+	err := w.Push(context.Background(), []string{"u1"}, "title", "content", &offlinepush.Opts{})
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.True(t, time.Since(start) <= maxRetryWait)
+}
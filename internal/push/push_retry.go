@@ -0,0 +1,288 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/mcontext"
+	"github.com/OpenIMSDK/tools/utils"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush"
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/cache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/prome"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/pushext"
+)
+
+const (
+	// pushRetryBatch tasks are claimed at a time.
+	pushRetryBatch = 100
+	// pushRetryLease is how long a claimed task stays hidden, a task of a crashed
+	// worker is retried once it passed.
+	pushRetryLease = time.Minute * 5
+)
+
+// pushRetryTask is a failed offline push waiting for its next attempt.
+type pushRetryTask struct {
+	TaskID         string            `json:"taskID"`
+	ConversationID string            `json:"conversationID"`
+	UserIDs        []string          `json:"userIDs"`
+	Title          string            `json:"title"`
+	Content        string            `json:"content"`
+	Opts           *offlinepush.Opts `json:"opts"`
+	Attempts       int32             `json:"attempts"`
+	LastErr        string            `json:"lastErr"`
+	CreateTime     int64             `json:"createTime"`
+	FailTime       int64             `json:"failTime"`
+}
+
+func (t *pushRetryTask) deadLetter() *pushext.PushDeadLetter {
+	deadLetter := &pushext.PushDeadLetter{
+		TaskID:         t.TaskID,
+		ConversationID: t.ConversationID,
+		UserIDs:        t.UserIDs,
+		Title:          t.Title,
+		Content:        t.Content,
+		Attempts:       t.Attempts,
+		LastErr:        t.LastErr,
+		CreateTime:     t.CreateTime,
+		FailTime:       t.FailTime,
+	}
+	if t.Opts != nil && t.Opts.Msg != nil {
+		deadLetter.ClientMsgID = t.Opts.Msg.ClientMsgID
+	}
+	return deadLetter
+}
+
+// pushRetry retries the failed offline pushes with an exponential backoff and
+// keeps the ones failing maxAttempts times as dead letters.
+type pushRetry struct {
+	cache         cache.PushRetryCache
+	offlinePusher offlinepush.OfflinePusher
+	enable        bool
+	maxAttempts   int32
+	backoff       time.Duration
+	maxBackoff    time.Duration
+	// deadLetterMax and deadLetterExpire bound the kept dead letters, 0 keeps them
+	deadLetterMax    int
+	deadLetterExpire time.Duration
+}
+
+func newPushRetry(cache cache.PushRetryCache, offlinePusher offlinepush.OfflinePusher) *pushRetry {
+	r := &pushRetry{
+		cache:         cache,
+		offlinePusher: offlinePusher,
+		enable:        config.Config.Push.Retry.Enable,
+		maxAttempts:   int32(config.Config.Push.Retry.MaxAttempts),
+		backoff:       time.Duration(config.Config.Push.Retry.Backoff) * time.Second,
+		maxBackoff:    time.Duration(config.Config.Push.Retry.MaxBackoff) * time.Second,
+
+		deadLetterMax:    config.Config.Push.Retry.DeadLetterMax,
+		deadLetterExpire: time.Duration(config.Config.Push.Retry.DeadLetterExpire) * time.Second,
+	}
+	if r.maxAttempts <= 0 {
+		r.maxAttempts = 5
+	}
+	if r.backoff <= 0 {
+		r.backoff = time.Second * 5
+	}
+	if r.maxBackoff < r.backoff {
+		r.maxBackoff = r.backoff
+	}
+	return r
+}
+
+// backoffOf is the wait after the attempts-th failed attempt.
+func (r *pushRetry) backoffOf(attempts int32) time.Duration {
+	backoff := r.backoff
+	for i := int32(1); i < attempts && backoff < r.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > r.maxBackoff {
+		return r.maxBackoff
+	}
+	return backoff
+}
+
+// Add queues a push that failed for the first time, userIDs are the users it did not reach.
+func (r *pushRetry) Add(ctx context.Context, conversationID string, userIDs []string, title, content string, opts *offlinepush.Opts, pushErr error) error {
+	task := &pushRetryTask{
+		TaskID:         utils.OperationIDGenerator(),
+		ConversationID: conversationID,
+		UserIDs:        userIDs,
+		Title:          title,
+		Content:        content,
+		Opts:           opts,
+		CreateTime:     time.Now().UnixMilli(),
+	}
+	return r.fail(ctx, task, pushErr)
+}
+
+// fail counts a failed attempt of task and schedules the next one, or turns
+// task into a dead letter when it has no attempt left.
+func (r *pushRetry) fail(ctx context.Context, task *pushRetryTask, pushErr error) error {
+	now := time.Now()
+	task.Attempts++
+	task.LastErr = pushErr.Error()
+	task.FailTime = now.UnixMilli()
+	data, err := json.Marshal(task)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	if task.Attempts >= r.maxAttempts {
+		if err := r.cache.AddPushDeadLetter(ctx, task.TaskID, string(data), now, r.deadLetterMax, r.deadLetterExpire); err != nil {
+			return err
+		}
+		prome.Inc(prome.MsgOfflinePushDeadLetterCounter)
+		log.ZWarn(ctx, "offline push gave up", pushErr, "taskID", task.TaskID, "attempts", task.Attempts, "userIDs", task.UserIDs)
+		return nil
+	}
+	if err := r.cache.SchedulePushRetry(ctx, task.TaskID, string(data), now.Add(r.backoffOf(task.Attempts))); err != nil {
+		return err
+	}
+	prome.Inc(prome.MsgOfflinePushRetryCounter)
+	return nil
+}
+
+// Run retries the due tasks until the process exits.
+func (r *pushRetry) Run() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		for {
+			// a full batch means more tasks may be due
+			if r.retryDue() < pushRetryBatch {
+				break
+			}
+		}
+	}
+}
+
+// retryDue retries a batch of due tasks and returns how many were claimed.
+func (r *pushRetry) retryDue() int {
+	ctx := mcontext.NewCtx(utils.GetSelfFuncName())
+	tasks, err := r.cache.ClaimPushRetries(ctx, time.Now(), pushRetryLease, pushRetryBatch)
+	if err != nil {
+		log.ZWarn(ctx, "claim push retries failed", err)
+		return 0
+	}
+	for _, data := range tasks {
+		var task pushRetryTask
+		if err := json.Unmarshal([]byte(data), &task); err != nil {
+			log.ZWarn(ctx, "invalid push retry task", err, "task", data)
+			continue
+		}
+		r.retry(mcontext.NewCtx("@@@"+task.TaskID), &task)
+	}
+	return len(tasks)
+}
+
+func (r *pushRetry) retry(ctx context.Context, task *pushRetryTask) {
+	if err := r.offlinePusher.Push(ctx, task.UserIDs, task.Title, task.Content, task.Opts); err != nil {
+		prome.Inc(prome.MsgOfflinePushFailedCounter)
+		// the users reached by this attempt are not pushed again
+		task.UserIDs = offlinepush.FailedUserIDs(err, task.UserIDs)
+		if err := r.fail(ctx, task, err); err != nil {
+			log.ZWarn(ctx, "reschedule offline push failed", err, "taskID", task.TaskID)
+		}
+		return
+	}
+	prome.Inc(prome.MsgOfflinePushSuccessCounter)
+	if err := r.cache.RemovePushRetry(ctx, task.TaskID); err != nil {
+		log.ZWarn(ctx, "remove push retry failed", err, "taskID", task.TaskID)
+	}
+}
+
+// Replay gives the dead letters of taskIDs maxAttempts new attempts, the first one right away.
+// Every dead letter is moved to the queue in one step, a failure leaves the rest as they are.
+func (r *pushRetry) Replay(ctx context.Context, taskIDs []string) ([]string, error) {
+	tasks, err := r.cache.FindPushDeadLetters(ctx, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	replayed := make([]string, 0, len(tasks))
+	for _, data := range tasks {
+		var task pushRetryTask
+		if err := json.Unmarshal([]byte(data), &task); err != nil {
+			log.ZWarn(ctx, "invalid push dead letter", err, "task", data)
+			continue
+		}
+		task.Attempts = 0
+		newData, err := json.Marshal(&task)
+		if err != nil {
+			return nil, errs.Wrap(err)
+		}
+		ok, err := r.cache.ReplayPushDeadLetter(ctx, task.TaskID, string(newData), time.Now())
+		if err != nil {
+			return nil, err
+		}
+		// a dead letter replayed or deleted meanwhile is skipped
+		if ok {
+			replayed = append(replayed, task.TaskID)
+		}
+	}
+	return replayed, nil
+}
+
+func (r *pushServer) GetPushDeadLetters(ctx context.Context, req *pushext.GetPushDeadLettersReq) (*pushext.GetPushDeadLettersResp, error) {
+	if err := authverify.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	offset := int((req.Pagination.PageNumber - 1) * req.Pagination.ShowNumber)
+	tasks, total, err := r.retry.cache.GetPushDeadLetters(ctx, offset, int(req.Pagination.ShowNumber))
+	if err != nil {
+		return nil, err
+	}
+	resp := &pushext.GetPushDeadLettersResp{Total: total, DeadLetters: make([]*pushext.PushDeadLetter, 0, len(tasks))}
+	for _, data := range tasks {
+		var task pushRetryTask
+		if err := json.Unmarshal([]byte(data), &task); err != nil {
+			log.ZWarn(ctx, "invalid push dead letter", err, "task", data)
+			continue
+		}
+		resp.DeadLetters = append(resp.DeadLetters, task.deadLetter())
+	}
+	return resp, nil
+}
+
+func (r *pushServer) ReplayPushDeadLetters(ctx context.Context, req *pushext.ReplayPushDeadLettersReq) (*pushext.ReplayPushDeadLettersResp, error) {
+	if err := authverify.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if !r.retry.enable {
+		return nil, errs.ErrArgs.Wrap("push retry is disabled")
+	}
+	taskIDs, err := r.retry.Replay(ctx, req.TaskIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &pushext.ReplayPushDeadLettersResp{TaskIDs: taskIDs}, nil
+}
+
+func (r *pushServer) DeletePushDeadLetters(ctx context.Context, req *pushext.DeletePushDeadLettersReq) (*pushext.DeletePushDeadLettersResp, error) {
+	if err := authverify.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.retry.cache.DelPushDeadLetters(ctx, req.TaskIDs); err != nil {
+		return nil, err
+	}
+	return &pushext.DeletePushDeadLettersResp{}, nil
+}
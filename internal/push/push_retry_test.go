@@ -0,0 +1,193 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/cache"
+)
+
+type retryCache struct {
+	cache.PushRetryCache
+	queue map[string]time.Time
+	tasks map[string]string
+	dead  map[string]string
+	// replayErr fails every replay
+	replayErr error
+}
+
+func (c *retryCache) SchedulePushRetry(_ context.Context, taskID string, task string, dueAt time.Time) error {
+	c.queue[taskID] = dueAt
+	c.tasks[taskID] = task
+	return nil
+}
+
+func (c *retryCache) ClaimPushRetries(_ context.Context, now time.Time, lease time.Duration, count int) ([]string, error) {
+	var tasks []string
+	for taskID, dueAt := range c.queue {
+		if !dueAt.After(now) && len(tasks) < count {
+			c.queue[taskID] = now.Add(lease)
+			tasks = append(tasks, c.tasks[taskID])
+		}
+	}
+	return tasks, nil
+}
+
+func (c *retryCache) RemovePushRetry(_ context.Context, taskID string) error {
+	delete(c.queue, taskID)
+	delete(c.tasks, taskID)
+	return nil
+}
+
+func (c *retryCache) AddPushDeadLetter(ctx context.Context, taskID string, task string, _ time.Time, _ int, _ time.Duration) error {
+	_ = c.RemovePushRetry(ctx, taskID)
+	c.dead[taskID] = task
+	return nil
+}
+
+func (c *retryCache) FindPushDeadLetters(_ context.Context, taskIDs []string) ([]string, error) {
+	var tasks []string
+	for _, taskID := range taskIDs {
+		if task, ok := c.dead[taskID]; ok {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (c *retryCache) ReplayPushDeadLetter(ctx context.Context, taskID string, task string, dueAt time.Time) (bool, error) {
+	if _, ok := c.dead[taskID]; !ok {
+		return false, nil
+	}
+	if c.replayErr != nil {
+		return false, c.replayErr
+	}
+	delete(c.dead, taskID)
+	return true, c.SchedulePushRetry(ctx, taskID, task, dueAt)
+}
+
+type failPusher struct {
+	err     error
+	calls   int
+	userIDs [][]string
+}
+
+func (p *failPusher) Push(_ context.Context, userIDs []string, _, _ string, _ *offlinepush.Opts) error {
+	p.calls++
+	p.userIDs = append(p.userIDs, userIDs)
+	return p.err
+}
+
+func newTestPushRetry(pusher offlinepush.OfflinePusher) (*pushRetry, *retryCache) {
+	c := &retryCache{queue: map[string]time.Time{}, tasks: map[string]string{}, dead: map[string]string{}}
+	return &pushRetry{
+		cache:         c,
+		offlinePusher: pusher,
+		enable:        true,
+		maxAttempts:   3,
+		backoff:       time.Second,
+		maxBackoff:    time.Second * 3,
+	}, c
+}
+
+func TestPushRetryBackoff(t *testing.T) {
+	r, _ := newTestPushRetry(nil)
+	assert.Equal(t, time.Second, r.backoffOf(1))
+	assert.Equal(t, time.Second*2, r.backoffOf(2))
+	assert.Equal(t, time.Second*3, r.backoffOf(3))
+	assert.Equal(t, time.Second*3, r.backoffOf(100))
+}
+
+func TestPushRetryDeadLetter(t *testing.T) {
+	pusher := &failPusher{err: errors.New("provider down")}
+	r, c := newTestPushRetry(pusher)
+	ctx := context.Background()
+	assert.NoError(t, r.Add(ctx, "si_a_b", []string{"b"}, "title", "content", &offlinepush.Opts{}, pusher.err))
+	assert.Len(t, c.queue, 1)
+
+	for taskID := range c.queue {
+		c.queue[taskID] = time.Now()
+	}
+	assert.Equal(t, 1, r.retryDue())
+	assert.Len(t, c.queue, 1)
+	for taskID := range c.queue {
+		c.queue[taskID] = time.Now()
+	}
+	assert.Equal(t, 1, r.retryDue())
+	assert.Equal(t, 2, pusher.calls)
+	assert.Empty(t, c.queue)
+	assert.Len(t, c.dead, 1)
+
+	var taskIDs []string
+	for taskID := range c.dead {
+		taskIDs = append(taskIDs, taskID)
+	}
+	replayed, err := r.Replay(ctx, taskIDs)
+	assert.NoError(t, err)
+	assert.Equal(t, taskIDs, replayed)
+	assert.Empty(t, c.dead)
+
+	pusher.err = nil
+	assert.Equal(t, 1, r.retryDue())
+	assert.Equal(t, 3, pusher.calls)
+	assert.Empty(t, c.queue)
+	assert.Empty(t, c.tasks)
+}
+
+func TestPushRetryFailedUsers(t *testing.T) {
+	pusher := &failPusher{err: &offlinepush.FailedError{UserIDs: []string{"c"}, Err: errors.New("provider down")}}
+	r, c := newTestPushRetry(pusher)
+	ctx := context.Background()
+	assert.NoError(t, r.Add(ctx, "sg_g", []string{"b", "c"}, "title", "content", &offlinepush.Opts{}, pusher.err))
+	for taskID := range c.queue {
+		c.queue[taskID] = time.Now()
+	}
+	assert.Equal(t, 1, r.retryDue())
+	pusher.err = nil
+	for taskID := range c.queue {
+		c.queue[taskID] = time.Now()
+	}
+	assert.Equal(t, 1, r.retryDue())
+	// only the user the first retry did not reach is pushed again
+	assert.Equal(t, [][]string{{"b", "c"}, {"c"}}, pusher.userIDs)
+	assert.Empty(t, c.queue)
+}
+
+func TestPushRetryReplayFailureKeepsDeadLetters(t *testing.T) {
+	r, c := newTestPushRetry(&failPusher{})
+	ctx := context.Background()
+	for _, taskID := range []string{"t1", "t2", "t3"} {
+		c.dead[taskID] = `{"taskID":"` + taskID + `","attempts":3}`
+	}
+	c.replayErr = errors.New("redis down")
+	_, err := r.Replay(ctx, []string{"t1", "t2", "t3"})
+	assert.Error(t, err)
+	assert.Len(t, c.dead, 3)
+	assert.Empty(t, c.queue)
+
+	c.replayErr = nil
+	replayed, err := r.Replay(ctx, []string{"t1", "t2", "t3", "t4"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"t1", "t2", "t3"}, replayed)
+	assert.Empty(t, c.dead)
+	assert.Len(t, c.queue, 3)
+}
@@ -25,14 +25,20 @@ import (
 	"github.com/OpenIMSDK/tools/discoveryregistry"
 	"github.com/OpenIMSDK/tools/log"
 
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/db/cache"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/db/controller"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/db/localcache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/relation"
+	relationtb "github.com/openimsdk/open-im-server/v3/pkg/common/db/table/relation"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/pushext"
 )
 
 type pushServer struct {
-	pusher *Pusher
+	pusher       *Pusher
+	retry        *pushRetry
+	pushTemplate controller.PushTemplateDatabase
 }
 
 func Start(client discoveryregistry.SvcDiscoveryRegistry, server *grpc.Server) error {
@@ -41,11 +47,36 @@ func Start(client discoveryregistry.SvcDiscoveryRegistry, server *grpc.Server) e
 		return err
 	}
 	cacheModel := cache.NewMsgCacheModel(rdb)
-	offlinePusher := NewOfflinePusher(cacheModel)
-	database := controller.NewPushDatabase(cacheModel)
+	var (
+		pushDevice   controller.PushDeviceDatabase
+		pushTemplate controller.PushTemplateDatabase
+	)
+	// mysql is only needed to route pushes to the registered devices and to localize them
+	if config.Config.Push.Enable == "routing" || config.Config.Push.Template.Enable {
+		db, err := relation.NewGormDB()
+		if err != nil {
+			return err
+		}
+		if err := db.AutoMigrate(&relationtb.PushDeviceModel{}); err != nil {
+			return err
+		}
+		pushDevice = controller.NewPushDeviceDatabase(relation.NewPushDeviceGorm(db))
+		if config.Config.Push.Template.Enable {
+			if err := db.AutoMigrate(&relationtb.PushTemplateModel{}, &relationtb.PushUserSettingModel{}); err != nil {
+				return err
+			}
+			pushTemplate = controller.NewPushTemplateDatabase(relation.NewPushTemplateGorm(db), relation.NewPushUserSettingGorm(db))
+		}
+	}
+	offlinePusher := NewOfflinePusher(cacheModel, pushDevice)
+	database := controller.NewPushDatabase(cacheModel, pushDevice)
 	groupRpcClient := rpcclient.NewGroupRpcClient(client)
 	conversationRpcClient := rpcclient.NewConversationRpcClient(client)
 	msgRpcClient := rpcclient.NewMessageRpcClient(client)
+	var userRouteCache cache.UserRouteCache
+	if config.Config.LongConnSvr.UserRouteTTL > 0 {
+		userRouteCache = cache.NewUserRouteCacheRedis(rdb)
+	}
 	pusher := NewPusher(
 		client,
 		offlinePusher,
@@ -55,14 +86,31 @@ func Start(client discoveryregistry.SvcDiscoveryRegistry, server *grpc.Server) e
 		&conversationRpcClient,
 		&groupRpcClient,
 		&msgRpcClient,
+		userRouteCache,
 	)
+	retry := newPushRetry(cache.NewPushRetryCacheRedis(rdb), offlinePusher)
+	if retry.enable && offlinePusher != nil {
+		pusher.retry = retry
+		go retry.Run()
+	}
+	if pushTemplate != nil {
+		pusher.localizer = newPushLocalizer(pushTemplate, pushDevice, &groupRpcClient)
+		if err := pusher.localizer.templates.load(context.Background()); err != nil {
+			return err
+		}
+		go pusher.localizer.templates.run()
+	}
 	var wg sync.WaitGroup
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		pbpush.RegisterPushMsgServiceServer(server, &pushServer{
-			pusher: pusher,
-		})
+		s := &pushServer{
+			pusher:       pusher,
+			retry:        retry,
+			pushTemplate: pushTemplate,
+		}
+		pbpush.RegisterPushMsgServiceServer(server, s)
+		pushext.RegisterPushExtServer(server, s)
 	}()
 	go func() {
 		defer wg.Done()
@@ -98,5 +146,11 @@ func (r *pushServer) DelUserPushToken(
 	if err = r.pusher.database.DelFcmToken(ctx, req.UserID, int(req.PlatformID)); err != nil {
 		return nil, err
 	}
+	if err = r.pusher.database.DelApnsToken(ctx, req.UserID, int(req.PlatformID)); err != nil {
+		return nil, err
+	}
+	if err = r.pusher.database.DelPushDevices(ctx, req.UserID, req.PlatformID); err != nil {
+		return nil, err
+	}
 	return &pbpush.DelUserPushTokenResp{}, nil
 }
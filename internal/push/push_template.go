@@ -0,0 +1,413 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	"github.com/OpenIMSDK/protocol/sdkws"
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/mcontext"
+	"github.com/OpenIMSDK/tools/utils"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/controller"
+	relationtb "github.com/openimsdk/open-im-server/v3/pkg/common/db/table/relation"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/pushext"
+)
+
+// pushPreviewLen is the number of characters of the text kept in {preview}.
+const pushPreviewLen = 50
+
+// normalizeLocale makes zh_CN and zh-cn the same locale.
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// pushTemplates keeps the push templates of mysql in memory.
+type pushTemplates struct {
+	db            controller.PushTemplateDatabase
+	defaultLocale string
+	lock          sync.RWMutex
+	// templates by content type and normalized locale
+	templates map[int32]map[string]*relationtb.PushTemplateModel
+}
+
+func newPushTemplates(db controller.PushTemplateDatabase, defaultLocale string) *pushTemplates {
+	return &pushTemplates{db: db, defaultLocale: normalizeLocale(defaultLocale)}
+}
+
+func (t *pushTemplates) load(ctx context.Context) error {
+	models, err := t.db.FindAllTemplates(ctx)
+	if err != nil {
+		return err
+	}
+	templates := make(map[int32]map[string]*relationtb.PushTemplateModel)
+	for _, model := range models {
+		if templates[model.ContentType] == nil {
+			templates[model.ContentType] = make(map[string]*relationtb.PushTemplateModel)
+		}
+		templates[model.ContentType][normalizeLocale(model.Locale)] = model
+	}
+	t.lock.Lock()
+	t.templates = templates
+	t.lock.Unlock()
+	log.ZDebug(ctx, "push templates loaded", "count", len(models))
+	return nil
+}
+
+func (t *pushTemplates) run() {
+	interval := time.Duration(config.Config.Push.Template.ReloadInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Second * 10
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx := mcontext.NewCtx(utils.GetSelfFuncName())
+		if err := t.load(ctx); err != nil {
+			log.ZError(ctx, "reload push templates failed", err)
+		}
+	}
+}
+
+// find returns the template of contentType in locale, trying the language of
+// locale ("zh" for "zh-cn") and the default locale after it.
+func (t *pushTemplates) find(contentType int32, locale string) *relationtb.PushTemplateModel {
+	t.lock.RLock()
+	templates := t.templates[contentType]
+	t.lock.RUnlock()
+	if len(templates) == 0 {
+		return nil
+	}
+	locales := []string{locale}
+	if i := strings.IndexByte(locale, '-'); i > 0 {
+		locales = append(locales, locale[:i])
+	}
+	locales = append(locales, t.defaultLocale)
+	for _, l := range locales {
+		if template, ok := templates[l]; ok {
+			return template
+		}
+	}
+	return nil
+}
+
+// localizedPush is the push of the users sharing a locale and a content setting.
+type localizedPush struct {
+	UserIDs []string
+	Title   string
+	Content string
+}
+
+// pushLocalizer renders the offline pushes from the templates in the locale of each user.
+type pushLocalizer struct {
+	templates      *pushTemplates
+	db             controller.PushTemplateDatabase
+	pushDevice     controller.PushDeviceDatabase
+	groupRpcClient *rpcclient.GroupRpcClient
+}
+
+func newPushLocalizer(db controller.PushTemplateDatabase, pushDevice controller.PushDeviceDatabase, groupRpcClient *rpcclient.GroupRpcClient) *pushLocalizer {
+	return &pushLocalizer{
+		templates:      newPushTemplates(db, config.Config.Push.Template.DefaultLocale),
+		db:             db,
+		pushDevice:     pushDevice,
+		groupRpcClient: groupRpcClient,
+	}
+}
+
+// userSettings returns the locale of userIDs and the users hiding the content, the
+// locale of a user without a setting is the one of the latest registered device.
+func (l *pushLocalizer) userSettings(ctx context.Context, userIDs []string) (map[string]string, map[string]bool) {
+	locales := make(map[string]string, len(userIDs))
+	hidden := make(map[string]bool)
+	settings, err := l.db.FindUserSettings(ctx, userIDs)
+	if err != nil {
+		log.ZWarn(ctx, "find push user settings failed", err, "userIDs", userIDs)
+	}
+	for _, setting := range settings {
+		if setting.Locale != "" {
+			locales[setting.UserID] = normalizeLocale(setting.Locale)
+		}
+		if setting.HideContent {
+			hidden[setting.UserID] = true
+		}
+	}
+	var deviceUserIDs []string
+	for _, userID := range userIDs {
+		if _, ok := locales[userID]; !ok {
+			deviceUserIDs = append(deviceUserIDs, userID)
+		}
+	}
+	if len(deviceUserIDs) == 0 || l.pushDevice == nil {
+		return locales, hidden
+	}
+	devices, err := l.pushDevice.FindUserDevices(ctx, deviceUserIDs)
+	if err != nil {
+		log.ZWarn(ctx, "find push devices failed", err, "userIDs", deviceUserIDs)
+		return locales, hidden
+	}
+	updated := make(map[string]time.Time)
+	for _, device := range devices {
+		if device.Locale == "" || device.UpdateTime.Before(updated[device.UserID]) {
+			continue
+		}
+		updated[device.UserID] = device.UpdateTime
+		locales[device.UserID] = normalizeLocale(device.Locale)
+	}
+	return locales, hidden
+}
+
+// localize splits the push of msg to userIDs by locale and content setting. pushType
+// selects the template, title and content are the push when no template applies.
+func (l *pushLocalizer) localize(ctx context.Context, pushType int32, msg *sdkws.MsgData, userIDs []string, title, content string) []*localizedPush {
+	locales, hidden := l.userSettings(ctx, userIDs)
+	type key struct {
+		locale string
+		hidden bool
+	}
+	var keys []key
+	groups := make(map[key][]string)
+	for _, userID := range userIDs {
+		k := key{locale: locales[userID], hidden: hidden[userID]}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], userID)
+	}
+	// the title chosen by the sender wins over the templates
+	custom := msg.OfflinePushInfo != nil && msg.OfflinePushInfo.Title != ""
+	vars := &pushTemplateVars{msg: msg, groupRpcClient: l.groupRpcClient}
+	pushes := make([]*localizedPush, 0, len(keys))
+	for _, k := range keys {
+		push := &localizedPush{UserIDs: groups[k], Title: title, Content: content}
+		var template *relationtb.PushTemplateModel
+		switch {
+		case k.hidden:
+			template = l.templates.find(relationtb.PushTemplateHidden, k.locale)
+			if template == nil {
+				push.Title = constant.ContentType2PushContent[constant.Common]
+				push.Content = push.Title
+			}
+		case !custom:
+			template = l.templates.find(pushType, k.locale)
+			if template == nil {
+				template = l.templates.find(constant.Common, k.locale)
+			}
+		}
+		if template != nil {
+			push.Title = vars.render(ctx, template.Title, !k.hidden)
+			push.Content = push.Title
+			if template.Content != "" {
+				push.Content = vars.render(ctx, template.Content, !k.hidden)
+			}
+		}
+		pushes = append(pushes, push)
+	}
+	return pushes
+}
+
+// pushTemplateVars are the placeholder values of a message, the group name is only
+// queried when a template uses it.
+type pushTemplateVars struct {
+	msg            *sdkws.MsgData
+	groupRpcClient *rpcclient.GroupRpcClient
+	groupName      *string
+}
+
+func (v *pushTemplateVars) render(ctx context.Context, text string, preview bool) string {
+	if !strings.Contains(text, "{") {
+		return text
+	}
+	var previewText string
+	if preview {
+		previewText = msgPreview(v.msg)
+	}
+	return strings.NewReplacer(
+		"{senderNickname}", v.msg.SenderNickname,
+		"{groupName}", v.getGroupName(ctx, text),
+		"{preview}", previewText,
+	).Replace(text)
+}
+
+func (v *pushTemplateVars) getGroupName(ctx context.Context, text string) string {
+	if v.msg.GroupID == "" || !strings.Contains(text, "{groupName}") {
+		return ""
+	}
+	if v.groupName == nil {
+		var name string
+		if groupInfo, err := v.groupRpcClient.GetGroupInfoCache(ctx, v.msg.GroupID); err != nil {
+			log.ZWarn(ctx, "get group info failed", err, "groupID", v.msg.GroupID)
+		} else {
+			name = groupInfo.GroupName
+		}
+		v.groupName = &name
+	}
+	return *v.groupName
+}
+
+// msgPreview is the beginning of the text of a text message, the push label of other messages.
+func msgPreview(msg *sdkws.MsgData) string {
+	var text string
+	switch msg.ContentType {
+	case constant.Text:
+		var elem struct {
+			Content string `json:"content"`
+		}
+		if err := json.Unmarshal(msg.Content, &elem); err != nil {
+			// text sent by the api is stored without the json element
+			text = string(msg.Content)
+		} else {
+			text = elem.Content
+		}
+	case constant.AtText, constant.Quote:
+		var elem struct {
+			Text string `json:"text"`
+		}
+		_ = json.Unmarshal(msg.Content, &elem)
+		text = elem.Text
+	default:
+		if label, ok := constant.ContentType2PushContent[int64(msg.ContentType)]; ok {
+			return label
+		}
+		return constant.ContentType2PushContent[constant.Common]
+	}
+	if runes := []rune(text); len(runes) > pushPreviewLen {
+		return string(runes[:pushPreviewLen]) + "..."
+	}
+	return text
+}
+
+func (r *pushServer) SetPushTemplates(ctx context.Context, req *pushext.SetPushTemplatesReq) (*pushext.SetPushTemplatesResp, error) {
+	if err := authverify.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if r.pushTemplate == nil {
+		return nil, errs.ErrArgs.Wrap("push template is disabled")
+	}
+	now := time.Now()
+	templates := make([]*relationtb.PushTemplateModel, 0, len(req.Templates))
+	for _, template := range req.Templates {
+		templates = append(templates, &relationtb.PushTemplateModel{
+			ContentType: template.ContentType,
+			Locale:      template.Locale,
+			Title:       template.Title,
+			Content:     template.Content,
+			UpdateTime:  now,
+		})
+	}
+	if err := r.pushTemplate.SetTemplates(ctx, templates); err != nil {
+		return nil, err
+	}
+	r.reloadPushTemplates(ctx)
+	return &pushext.SetPushTemplatesResp{}, nil
+}
+
+func (r *pushServer) GetPushTemplates(ctx context.Context, req *pushext.GetPushTemplatesReq) (*pushext.GetPushTemplatesResp, error) {
+	if err := authverify.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if r.pushTemplate == nil {
+		return nil, errs.ErrArgs.Wrap("push template is disabled")
+	}
+	templates, err := r.pushTemplate.FindAllTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pushext.GetPushTemplatesResp{Templates: make([]*pushext.PushTemplate, 0, len(templates))}
+	for _, template := range templates {
+		resp.Templates = append(resp.Templates, &pushext.PushTemplate{
+			ContentType: template.ContentType,
+			Locale:      template.Locale,
+			Title:       template.Title,
+			Content:     template.Content,
+			UpdateTime:  template.UpdateTime.UnixMilli(),
+		})
+	}
+	return resp, nil
+}
+
+func (r *pushServer) DeletePushTemplates(ctx context.Context, req *pushext.DeletePushTemplatesReq) (*pushext.DeletePushTemplatesResp, error) {
+	if err := authverify.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if r.pushTemplate == nil {
+		return nil, errs.ErrArgs.Wrap("push template is disabled")
+	}
+	for _, key := range req.Keys {
+		if err := r.pushTemplate.DeleteTemplate(ctx, key.ContentType, key.Locale); err != nil {
+			return nil, err
+		}
+	}
+	r.reloadPushTemplates(ctx)
+	return &pushext.DeletePushTemplatesResp{}, nil
+}
+
+// reloadPushTemplates applies a change on this node right away, the others pick it up on their next reload.
+func (r *pushServer) reloadPushTemplates(ctx context.Context) {
+	if r.pusher.localizer == nil {
+		return
+	}
+	if err := r.pusher.localizer.templates.load(ctx); err != nil {
+		log.ZWarn(ctx, "reload push templates failed", err)
+	}
+}
+
+func (r *pushServer) SetUserPushSetting(ctx context.Context, req *pushext.SetUserPushSettingReq) (*pushext.SetUserPushSettingResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.Setting.UserID); err != nil {
+		return nil, err
+	}
+	if r.pushTemplate == nil {
+		return nil, errs.ErrArgs.Wrap("push template is disabled")
+	}
+	err := r.pushTemplate.SetUserSetting(ctx, &relationtb.PushUserSettingModel{
+		UserID:      req.Setting.UserID,
+		Locale:      req.Setting.Locale,
+		HideContent: req.Setting.HideContent,
+		UpdateTime:  time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pushext.SetUserPushSettingResp{}, nil
+}
+
+func (r *pushServer) GetUserPushSetting(ctx context.Context, req *pushext.GetUserPushSettingReq) (*pushext.GetUserPushSettingResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	if r.pushTemplate == nil {
+		return nil, errs.ErrArgs.Wrap("push template is disabled")
+	}
+	settings, err := r.pushTemplate.FindUserSettings(ctx, []string{req.UserID})
+	if err != nil {
+		return nil, err
+	}
+	setting := &pushext.UserPushSetting{UserID: req.UserID}
+	if len(settings) > 0 {
+		setting.Locale = settings[0].Locale
+		setting.HideContent = settings[0].HideContent
+	}
+	return &pushext.GetUserPushSettingResp{Setting: setting}, nil
+}
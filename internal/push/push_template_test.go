@@ -0,0 +1,142 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	"github.com/OpenIMSDK/protocol/sdkws"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/controller"
+	relationtb "github.com/openimsdk/open-im-server/v3/pkg/common/db/table/relation"
+)
+
+type templateDatabase struct {
+	controller.PushTemplateDatabase
+	templates []*relationtb.PushTemplateModel
+	settings  []*relationtb.PushUserSettingModel
+}
+
+func (d *templateDatabase) FindAllTemplates(context.Context) ([]*relationtb.PushTemplateModel, error) {
+	return d.templates, nil
+}
+
+func (d *templateDatabase) FindUserSettings(_ context.Context, userIDs []string) ([]*relationtb.PushUserSettingModel, error) {
+	var settings []*relationtb.PushUserSettingModel
+	for _, setting := range d.settings {
+		for _, userID := range userIDs {
+			if setting.UserID == userID {
+				settings = append(settings, setting)
+			}
+		}
+	}
+	return settings, nil
+}
+
+type localeDeviceDatabase struct {
+	controller.PushDeviceDatabase
+	devices []*relationtb.PushDeviceModel
+}
+
+func (d *localeDeviceDatabase) FindUserDevices(context.Context, []string) ([]*relationtb.PushDeviceModel, error) {
+	return d.devices, nil
+}
+
+func TestPushLocalizer(t *testing.T) {
+	db := &templateDatabase{
+		templates: []*relationtb.PushTemplateModel{
+			{ContentType: constant.Text, Locale: "en", Title: "{senderNickname}", Content: "{preview}"},
+			{ContentType: constant.Text, Locale: "zh", Title: "{senderNickname}发来消息", Content: "{preview}"},
+			{ContentType: constant.Common, Locale: "en", Title: "New message from {senderNickname}"},
+			{ContentType: relationtb.PushTemplateHidden, Locale: "en", Title: "You have a new message"},
+		},
+		settings: []*relationtb.PushUserSettingModel{
+			{UserID: "zh", Locale: "zh_CN"},
+			{UserID: "hidden", HideContent: true},
+		},
+	}
+	devices := &localeDeviceDatabase{devices: []*relationtb.PushDeviceModel{
+		{UserID: "device", Locale: "fr", UpdateTime: time.Now().Add(-time.Hour)},
+		{UserID: "device", Locale: "zh-TW", UpdateTime: time.Now()},
+	}}
+	l := &pushLocalizer{templates: newPushTemplates(db, "en"), db: db, pushDevice: devices}
+	ctx := context.Background()
+	assert.NoError(t, l.templates.load(ctx))
+
+	msg := &sdkws.MsgData{
+		SenderNickname: "alice",
+		ContentType:    constant.Text,
+		Content:        []byte(`{"content":"` + strings.Repeat("a", 60) + `"}`),
+	}
+	pushes := l.localize(ctx, constant.Text, msg, []string{"en", "zh", "hidden", "device"}, "[TEXT]", "[TEXT]")
+	assert.Len(t, pushes, 4)
+	assert.Equal(t, []string{"en"}, pushes[0].UserIDs)
+	assert.Equal(t, "alice", pushes[0].Title)
+	assert.Equal(t, strings.Repeat("a", pushPreviewLen)+"...", pushes[0].Content)
+	assert.Equal(t, []string{"zh"}, pushes[1].UserIDs)
+	assert.Equal(t, "alice发来消息", pushes[1].Title)
+	assert.Equal(t, []string{"hidden"}, pushes[2].UserIDs)
+	assert.Equal(t, "You have a new message", pushes[2].Title)
+	assert.Equal(t, "You have a new message", pushes[2].Content)
+	// the latest device is zh-TW, rendered with the zh template
+	assert.Equal(t, []string{"device"}, pushes[3].UserIDs)
+	assert.Equal(t, "alice发来消息", pushes[3].Title)
+
+	msg = &sdkws.MsgData{SenderNickname: "alice", ContentType: constant.Picture}
+	pushes = l.localize(ctx, constant.Picture, msg, []string{"en"}, "[PICTURE]", "[PICTURE]")
+	assert.Equal(t, "New message from alice", pushes[0].Title)
+
+	msg.OfflinePushInfo = &sdkws.OfflinePushInfo{Title: "custom", Desc: "desc"}
+	pushes = l.localize(ctx, constant.Picture, msg, []string{"en", "hidden"}, "custom", "desc")
+	assert.Equal(t, "custom", pushes[0].Title)
+	assert.Equal(t, "desc", pushes[0].Content)
+	assert.Equal(t, "You have a new message", pushes[1].Title)
+}
+
+type localePusher struct {
+	mu      sync.Mutex
+	titles  []string
+	failing map[string]error
+}
+
+func (p *localePusher) Push(_ context.Context, _ []string, title, _ string, _ *offlinepush.Opts) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.titles = append(p.titles, title)
+	return p.failing[title]
+}
+
+func TestPushLocalized(t *testing.T) {
+	failErr := errors.New("provider down")
+	pusher := &localePusher{failing: map[string]error{"en": failErr}}
+	p := &Pusher{offlinePusher: pusher}
+	err := p.pushLocalized(context.Background(), "si_a_b", []*localizedPush{
+		{UserIDs: []string{"u1", "u2"}, Title: "en"},
+		{UserIDs: []string{"u3"}, Title: "zh"},
+		{UserIDs: []string{"u4"}, Title: "fr"},
+	}, &offlinepush.Opts{})
+	assert.ErrorIs(t, err, failErr)
+	// the groups after the failed one are pushed as well
+	assert.ElementsMatch(t, []string{"en", "zh", "fr"}, pusher.titles)
+	assert.Equal(t, []string{"u1", "u2"}, offlinepush.FailedUserIDs(err, nil))
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"sync"
 
 	"github.com/OpenIMSDK/protocol/conversation"
 
@@ -30,11 +31,15 @@ import (
 	"github.com/OpenIMSDK/tools/log"
 	"github.com/OpenIMSDK/tools/mcontext"
 	"github.com/OpenIMSDK/tools/utils"
+	"google.golang.org/grpc"
 
 	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/apns"
 	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/fcm"
 	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/getui"
 	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/jpush"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/router"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/webhook"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/db/cache"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/db/controller"
@@ -52,7 +57,13 @@ type Pusher struct {
 	msgRpcClient           *rpcclient.MessageRpcClient
 	conversationRpcClient  *rpcclient.ConversationRpcClient
 	groupRpcClient         *rpcclient.GroupRpcClient
-	successCount           int
+	// userRouteCache is nil when every gateway gets every push
+	userRouteCache cache.UserRouteCache
+	// retry is nil when the failed offline pushes are dropped
+	retry *pushRetry
+	// localizer is nil when every user gets the built-in push titles
+	localizer    *pushLocalizer
+	successCount int
 }
 
 var errNoOfflinePusher = errors.New("no offlinePusher is configured")
@@ -60,6 +71,7 @@ var errNoOfflinePusher = errors.New("no offlinePusher is configured")
 func NewPusher(discov discoveryregistry.SvcDiscoveryRegistry, offlinePusher offlinepush.OfflinePusher, database controller.PushDatabase,
 	groupLocalCache *localcache.GroupLocalCache, conversationLocalCache *localcache.ConversationLocalCache,
 	conversationRpcClient *rpcclient.ConversationRpcClient, groupRpcClient *rpcclient.GroupRpcClient, msgRpcClient *rpcclient.MessageRpcClient,
+	userRouteCache cache.UserRouteCache,
 ) *Pusher {
 	return &Pusher{
 		discov:                 discov,
@@ -70,18 +82,36 @@ func NewPusher(discov discoveryregistry.SvcDiscoveryRegistry, offlinePusher offl
 		msgRpcClient:           msgRpcClient,
 		conversationRpcClient:  conversationRpcClient,
 		groupRpcClient:         groupRpcClient,
+		userRouteCache:         userRouteCache,
 	}
 }
 
-func NewOfflinePusher(cache cache.MsgModel) offlinepush.OfflinePusher {
+func NewOfflinePusher(cache cache.MsgModel, pushDevice controller.PushDeviceDatabase) offlinepush.OfflinePusher {
+	if config.Config.Push.Enable != "routing" {
+		return newOfflinePusher(config.Config.Push.Enable, cache)
+	}
+	providers := make(map[string]offlinepush.OfflinePusher)
+	for _, name := range config.Config.Push.Routing.Providers {
+		if offlinePusher := newOfflinePusher(name, cache); offlinePusher != nil {
+			providers[name] = offlinePusher
+		}
+	}
+	return router.NewClient(providers, providers[config.Config.Push.Routing.Fallback], pushDevice)
+}
+
+func newOfflinePusher(name string, cache cache.MsgModel) offlinepush.OfflinePusher {
 	var offlinePusher offlinepush.OfflinePusher
-	switch config.Config.Push.Enable {
-	case "getui":
+	switch name {
+	case offlinepush.GeTui:
 		offlinePusher = getui.NewClient(cache)
-	case "fcm":
+	case offlinepush.FCM:
 		offlinePusher = fcm.NewClient(cache)
-	case "jpush":
+	case offlinepush.JPush:
 		offlinePusher = jpush.NewClient()
+	case offlinepush.APNs:
+		offlinePusher = apns.NewClient(cache)
+	case offlinepush.Webhook:
+		offlinePusher = webhook.NewClient()
 	}
 	return offlinePusher
 }
@@ -264,10 +294,17 @@ func (p *Pusher) GetConnsAndOnlinePush(ctx context.Context, msg *sdkws.MsgData,
 	if err != nil {
 		return nil, err
 	}
+	nodeUserIDs := p.routeUsers(ctx, conns, pushToUserIDs)
 	// Online push message
 	for _, v := range conns {
+		userIDs := pushToUserIDs
+		if nodeUserIDs != nil {
+			if userIDs = nodeUserIDs[v.Target()]; len(userIDs) == 0 {
+				continue
+			}
+		}
 		msgClient := msggateway.NewMsgGatewayClient(v)
-		reply, err := msgClient.SuperGroupOnlineBatchPushOneMsg(ctx, &msggateway.OnlineBatchPushOneMsgReq{MsgData: msg, PushToUserIDs: pushToUserIDs})
+		reply, err := msgClient.SuperGroupOnlineBatchPushOneMsg(ctx, &msggateway.OnlineBatchPushOneMsgReq{MsgData: msg, PushToUserIDs: userIDs})
 		if err != nil {
 			continue
 		}
@@ -276,25 +313,112 @@ func (p *Pusher) GetConnsAndOnlinePush(ctx context.Context, msg *sdkws.MsgData,
 			wsResults = append(wsResults, reply.SinglePushResult...)
 		}
 	}
+	if nodeUserIDs != nil {
+		// the gateways only answered for their own users, the others are reported
+		// not online as a broadcast would have, so they still get an offline push
+		answered := make(map[string]struct{}, len(wsResults))
+		for _, result := range wsResults {
+			answered[result.UserID] = struct{}{}
+		}
+		for _, userID := range pushToUserIDs {
+			if _, ok := answered[userID]; !ok {
+				wsResults = append(wsResults, &msggateway.SingleMsgToUserResults{UserID: userID})
+			}
+		}
+	}
 	return wsResults, nil
 }
 
+// routeUsers groups pushToUserIDs by the gateway holding them, nil means the
+// route table cannot be trusted and every gateway has to be asked.
+func (p *Pusher) routeUsers(ctx context.Context, conns []*grpc.ClientConn, pushToUserIDs []string) map[string][]string {
+	if p.userRouteCache == nil {
+		return nil
+	}
+	nodes := make([]string, 0, len(conns))
+	for _, conn := range conns {
+		nodes = append(nodes, conn.Target())
+	}
+	nodeUserIDs, err := p.userRouteCache.GetNodeUsers(ctx, nodes, pushToUserIDs)
+	if err != nil {
+		log.ZDebug(ctx, "user route unavailable, push to every gateway", "err", err, "gatewayNum", len(nodes))
+		return nil
+	}
+	return nodeUserIDs
+}
+
 func (p *Pusher) offlinePushMsg(ctx context.Context, conversationID string, msg *sdkws.MsgData, offlinePushUserIDs []string) error {
 	title, content, opts, err := p.getOfflinePushInfos(conversationID, msg)
 	if err != nil {
 		return err
 	}
-	err = p.offlinePusher.Push(ctx, offlinePushUserIDs, title, content, opts)
+	if p.localizer == nil {
+		return p.pushOffline(ctx, conversationID, offlinePushUserIDs, title, content, opts)
+	}
+	pushType := p.offlinePushType(conversationID, msg)
+	return p.pushLocalized(ctx, conversationID, p.localizer.localize(ctx, int32(pushType), msg, offlinePushUserIDs, title, content), opts)
+}
+
+// pushLocalized pushes every locale group concurrently, a failed group does not stop the others.
+// The users of the failed groups are returned in an offlinepush.FailedError.
+func (p *Pusher) pushLocalized(ctx context.Context, conversationID string, pushes []*localizedPush, opts *offlinepush.Opts) error {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		lastErr error
+		failed  []string
+	)
+	for _, push := range pushes {
+		wg.Add(1)
+		go func(push *localizedPush) {
+			defer wg.Done()
+			if err := p.pushOffline(ctx, conversationID, push.UserIDs, push.Title, push.Content, opts); err != nil {
+				log.ZWarn(ctx, "localized offline push failed", err, "title", push.Title, "userIDs", push.UserIDs)
+				mu.Lock()
+				lastErr = err
+				failed = append(failed, offlinepush.FailedUserIDs(err, push.UserIDs)...)
+				mu.Unlock()
+			}
+		}(push)
+	}
+	wg.Wait()
+	if lastErr != nil {
+		return &offlinepush.FailedError{UserIDs: failed, Err: lastErr}
+	}
+	return nil
+}
+
+func (p *Pusher) pushOffline(ctx context.Context, conversationID string, userIDs []string, title, content string, opts *offlinepush.Opts) error {
+	err := p.offlinePusher.Push(ctx, userIDs, title, content, opts)
 	if err != nil {
 		prome.Inc(prome.MsgOfflinePushFailedCounter)
-		return err
+		if p.retry == nil {
+			return err
+		}
+		failedUserIDs := offlinepush.FailedUserIDs(err, userIDs)
+		if retryErr := p.retry.Add(ctx, conversationID, failedUserIDs, title, content, opts, err); retryErr != nil {
+			log.ZError(ctx, "queue offline push retry failed", retryErr, "pushErr", err)
+			return err
+		}
+		log.ZWarn(ctx, "offline push failed, retry queued", err, "userIDs", failedUserIDs)
+		return nil
 	}
 	prome.Inc(prome.MsgOfflinePushSuccessCounter)
 	return nil
 }
 
 func (p *Pusher) GetOfflinePushOpts(msg *sdkws.MsgData) (opts *offlinepush.Opts, err error) {
-	opts = &offlinepush.Opts{Signal: &offlinepush.Signal{}}
+	opts = &offlinepush.Opts{Signal: &offlinepush.Signal{}, Msg: &offlinepush.Msg{
+		ConversationID: msgprocessor.GetConversationIDByMsg(msg),
+		ClientMsgID:    msg.ClientMsgID,
+		ServerMsgID:    msg.ServerMsgID,
+		SendID:         msg.SendID,
+		GroupID:        msg.GroupID,
+		SessionType:    msg.SessionType,
+		ContentType:    msg.ContentType,
+		Seq:            msg.Seq,
+		SendTime:       msg.SendTime,
+	}}
 	// if msg.ContentType > constant.SignalingNotificationBegin && msg.ContentType < constant.SignalingNotificationEnd {
 	// 	req := &sdkws.SignalReq{}
 	// 	if err := proto.Unmarshal(msg.Content, req); err != nil {
@@ -318,11 +442,6 @@ func (p *Pusher) getOfflinePushInfos(conversationID string, msg *sdkws.MsgData)
 		err = errNoOfflinePusher
 		return
 	}
-	type AtContent struct {
-		Text       string   `json:"text"`
-		AtUserList []string `json:"atUserList"`
-		IsAtSelf   bool     `json:"isAtSelf"`
-	}
 	opts, err = p.GetOfflinePushOpts(msg)
 	if err != nil {
 		return
@@ -332,29 +451,10 @@ func (p *Pusher) getOfflinePushInfos(conversationID string, msg *sdkws.MsgData)
 		content = msg.OfflinePushInfo.Desc
 	}
 	if title == "" {
-		switch msg.ContentType {
-		case constant.Text:
-			fallthrough
-		case constant.Picture:
-			fallthrough
-		case constant.Voice:
-			fallthrough
-		case constant.Video:
-			fallthrough
-		case constant.File:
-			title = constant.ContentType2PushContent[int64(msg.ContentType)]
-		case constant.AtText:
-			a := AtContent{}
-			_ = utils.JsonStringToStruct(string(msg.Content), &a)
-			if utils.IsContain(conversationID, a.AtUserList) {
-				title = constant.ContentType2PushContent[constant.AtText] + constant.ContentType2PushContent[constant.Common]
-			} else {
-				title = constant.ContentType2PushContent[constant.GroupMsg]
-			}
-		case constant.SignalingNotification:
-			title = constant.ContentType2PushContent[constant.SignalMsg]
-		default:
-			title = constant.ContentType2PushContent[constant.Common]
+		pushType := p.offlinePushType(conversationID, msg)
+		title = constant.ContentType2PushContent[pushType]
+		if pushType == constant.AtText {
+			title += constant.ContentType2PushContent[constant.Common]
 		}
 	}
 	if content == "" {
@@ -362,3 +462,35 @@ func (p *Pusher) getOfflinePushInfos(conversationID string, msg *sdkws.MsgData)
 	}
 	return
 }
+
+// offlinePushType is the key of the push title of msg in constant.ContentType2PushContent.
+func (p *Pusher) offlinePushType(conversationID string, msg *sdkws.MsgData) int64 {
+	type AtContent struct {
+		Text       string   `json:"text"`
+		AtUserList []string `json:"atUserList"`
+		IsAtSelf   bool     `json:"isAtSelf"`
+	}
+	switch msg.ContentType {
+	case constant.Text:
+		fallthrough
+	case constant.Picture:
+		fallthrough
+	case constant.Voice:
+		fallthrough
+	case constant.Video:
+		fallthrough
+	case constant.File:
+		return int64(msg.ContentType)
+	case constant.AtText:
+		a := AtContent{}
+		_ = utils.JsonStringToStruct(string(msg.Content), &a)
+		if utils.IsContain(conversationID, a.AtUserList) {
+			return constant.AtText
+		}
+		return constant.GroupMsg
+	case constant.SignalingNotification:
+		return constant.SignalMsg
+	default:
+		return constant.Common
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/redis/go-redis/v9"
 
 	"github.com/OpenIMSDK/protocol/constant"
+	"github.com/OpenIMSDK/protocol/conversation"
 	"github.com/OpenIMSDK/protocol/msg"
 	"github.com/OpenIMSDK/protocol/sdkws"
 	"github.com/OpenIMSDK/tools/errs"
@@ -109,6 +110,11 @@ func (m *msgServer) MarkMsgsAsRead(
 	if err = m.MsgDatabase.MarkSingleChatMsgsAsRead(ctx, req.UserID, req.ConversationID, req.Seqs); err != nil {
 		return
 	}
+	if isGroupReadReceiptEnabled(conversation.ConversationType) {
+		if err = m.markGroupMsgsAsRead(ctx, req.UserID, req.ConversationID, req.Seqs); err != nil {
+			return
+		}
+	}
 	currentHasReadSeq, err := m.MsgDatabase.GetHasReadSeq(ctx, req.UserID, req.ConversationID)
 	if err != nil && errs.Unwrap(err) != redis.Nil {
 		return
@@ -119,7 +125,8 @@ func (m *msgServer) MarkMsgsAsRead(
 			return
 		}
 	}
-	if err = m.sendMarkAsReadNotification(ctx, req.ConversationID, conversation.ConversationType, req.UserID, m.conversationAndGetRecvID(conversation, req.UserID), req.Seqs, hasReadSeq); err != nil {
+	sessionType, recvID := m.markAsReadTarget(conversation, req.UserID)
+	if err = m.sendMarkAsReadNotification(ctx, req.ConversationID, sessionType, req.UserID, recvID, req.Seqs, hasReadSeq); err != nil {
 		return
 	}
 	return &msg.MarkMsgsAsReadResp{}, nil
@@ -151,6 +158,11 @@ func (m *msgServer) MarkConversationAsRead(
 		if err = m.MsgDatabase.MarkSingleChatMsgsAsRead(ctx, req.UserID, req.ConversationID, seqs); err != nil {
 			return
 		}
+		if isGroupReadReceiptEnabled(conversation.ConversationType) {
+			if err = m.markGroupMsgsAsRead(ctx, req.UserID, req.ConversationID, seqs); err != nil {
+				return
+			}
+		}
 	}
 	if req.HasReadSeq > hasReadSeq {
 		err = m.MsgDatabase.SetHasReadSeq(ctx, req.UserID, req.ConversationID, req.HasReadSeq)
@@ -159,12 +171,22 @@ func (m *msgServer) MarkConversationAsRead(
 		}
 		hasReadSeq = req.HasReadSeq
 	}
-	if err = m.sendMarkAsReadNotification(ctx, req.ConversationID, conversation.ConversationType, req.UserID, m.conversationAndGetRecvID(conversation, req.UserID), seqs, hasReadSeq); err != nil {
+	sessionType, recvID := m.markAsReadTarget(conversation, req.UserID)
+	if err = m.sendMarkAsReadNotification(ctx, req.ConversationID, sessionType, req.UserID, recvID, seqs, hasReadSeq); err != nil {
 		return
 	}
 	return &msg.MarkConversationAsReadResp{}, nil
 }
 
+// markAsReadTarget group readers only sync their own devices, senders get the
+// aggregated read count notification instead.
+func (m *msgServer) markAsReadTarget(conversation *conversation.Conversation, userID string) (sessionType int32, recvID string) {
+	if isGroupReadReceiptEnabled(conversation.ConversationType) {
+		return constant.SingleChatType, userID
+	}
+	return conversation.ConversationType, m.conversationAndGetRecvID(conversation, userID)
+}
+
 func (m *msgServer) sendMarkAsReadNotification(
 	ctx context.Context,
 	conversationID string,
@@ -0,0 +1,96 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+	"time"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/mcontext"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	unrelationtb "github.com/openimsdk/open-im-server/v3/pkg/common/db/table/unrelation"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+)
+
+func (m *msgServer) EditMsg(ctx context.Context, req *msgext.EditMsgReq) (*msgext.EditMsgResp, error) {
+	defer log.ZDebug(ctx, "EditMsg return line")
+	if err := authverify.CheckAccessV3(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	user, err := m.User.GetUserInfo(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, req.UserID, req.ConversationID, []int64{req.Seq})
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 || msgs[0] == nil {
+		return nil, errs.ErrRecordNotFound.Wrap("msg not found")
+	}
+	if msgs[0].ContentType == constant.MsgRevokeNotification {
+		return nil, errs.ErrMsgAlreadyRevoke.Wrap("msg already revoke")
+	}
+	if msgs[0].ContentType >= constant.NotificationBegin && msgs[0].ContentType <= constant.NotificationEnd {
+		return nil, errs.ErrArgs.Wrap("notification msg can not be edited")
+	}
+	if string(msgs[0].Content) == req.Content {
+		return &msgext.EditMsgResp{}, nil
+	}
+	if _, err := m.checkMsgOperatorRole(ctx, req.UserID, user.AppMangerLevel, msgs[0]); err != nil {
+		return nil, err
+	}
+	content, err := m.filterSensitiveContent(ctx, msgs[0].ContentType, []byte(req.Content))
+	if err != nil {
+		return nil, err
+	}
+	req.Content = string(content)
+	now := time.Now().UnixMilli()
+	err = m.MsgDatabase.EditMsg(ctx, req.ConversationID, req.Seq, req.Content, &unrelationtb.EditModel{
+		UserID:      req.UserID,
+		Nickname:    user.Nickname,
+		ContentType: msgs[0].ContentType,
+		Content:     string(msgs[0].Content),
+		Time:        now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	msgs[0].Content = []byte(req.Content)
+	m.updateSearchIndex(ctx, req.ConversationID, msgs[0])
+	tips := msgext.EditMsgTips{
+		EditorUserID:   mcontext.GetOpUserID(ctx),
+		ClientMsgID:    msgs[0].ClientMsgID,
+		ConversationID: req.ConversationID,
+		SessionType:    msgs[0].SessionType,
+		Seq:            req.Seq,
+		Content:        req.Content,
+		EditTime:       now,
+	}
+	var recvID string
+	if msgs[0].SessionType == constant.SuperGroupChatType {
+		recvID = msgs[0].GroupID
+	} else {
+		recvID = msgs[0].RecvID
+	}
+	if err := m.notificationSender.NotificationWithSesstionType(ctx, req.UserID, recvID, msgext.MsgEditNotification, msgs[0].SessionType, &tips); err != nil {
+		return nil, err
+	}
+	return &msgext.EditMsgResp{}, nil
+}
@@ -0,0 +1,250 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	pbmsg "github.com/OpenIMSDK/protocol/msg"
+	"github.com/OpenIMSDK/protocol/sdkws"
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/utils"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/apistruct"
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+)
+
+const (
+	// maxForwardSingleMsgs the most messages that can be forwarded one by one in a request.
+	maxForwardSingleMsgs = 20
+	// maxMergeAbstracts the number of abstracts shown in a merged message.
+	maxMergeAbstracts = 4
+)
+
+// ForwardMsgs sends copies of messages the caller can read to the target
+// conversations. Contents are reused as is, so media stays on the original S3 objects.
+func (m *msgServer) ForwardMsgs(ctx context.Context, req *msgext.ForwardMsgsReq) (*msgext.ForwardMsgsResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	if !req.IsMerge && len(req.Seqs) > maxForwardSingleMsgs {
+		return nil, errs.ErrArgs.Wrap("too many msgs to forward one by one")
+	}
+	if _, err := m.Conversation.GetConversation(ctx, req.UserID, req.ConversationID); err != nil {
+		return nil, err
+	}
+	_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, req.UserID, req.ConversationID, utils.Distinct(req.Seqs))
+	if err != nil {
+		return nil, err
+	}
+	sources := make([]*sdkws.MsgData, 0, len(msgs))
+	for _, msgData := range msgs {
+		if msgData == nil || msgData.Status == constant.MsgDeleted || !isForwardableMsg(msgData) {
+			continue
+		}
+		sources = append(sources, msgData)
+	}
+	if len(sources) == 0 {
+		return nil, errs.ErrRecordNotFound.Wrap("no msg can be forwarded")
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].Seq < sources[j].Seq })
+	user, err := m.User.GetUserInfo(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &msgext.ForwardMsgsResp{}
+	for _, target := range req.Targets {
+		if req.IsMerge {
+			msgData, err := m.newMergeMsg(req, user, target, sources)
+			if err != nil {
+				return nil, err
+			}
+			resp.Results = append(resp.Results, m.sendForwardMsg(ctx, target, 0, msgData))
+			continue
+		}
+		for _, source := range sources {
+			msgData := m.newForwardMsg(req, user, target)
+			msgData.ContentType, msgData.Content = forwardContent(source)
+			msgData.AttachedInfo = attachForwardInfo(&msgext.ForwardInfo{
+				SourceConversationID: req.ConversationID,
+				SourceSeq:            source.Seq,
+				SourceClientMsgID:    source.ClientMsgID,
+				SourceSendID:         source.SendID,
+				SourceSenderNickname: source.SenderNickname,
+				SourceSendTime:       source.SendTime,
+			})
+			resp.Results = append(resp.Results, m.sendForwardMsg(ctx, target, source.Seq, msgData))
+		}
+	}
+	return resp, nil
+}
+
+// forwardContent returns the content a forwarded copy of source is sent with. Mentions are not
+// forwarded, so an AtText becomes a Text of the same words.
+func forwardContent(source *sdkws.MsgData) (int32, []byte) {
+	if source.ContentType != constant.AtText {
+		return source.ContentType, source.Content
+	}
+	var at struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(source.Content, &at); err != nil {
+		return source.ContentType, source.Content
+	}
+	content, err := json.Marshal(&apistruct.TextElem{Content: at.Text})
+	if err != nil {
+		return source.ContentType, source.Content
+	}
+	return constant.Text, content
+}
+
+func isForwardableMsg(msgData *sdkws.MsgData) bool {
+	switch msgData.ContentType {
+	case constant.Text, constant.Picture, constant.Voice, constant.Video, constant.File,
+		constant.AtText, constant.Merger, constant.Card, constant.Location, constant.Custom,
+		constant.Quote:
+		return true
+	default:
+		return false
+	}
+}
+
+func (m *msgServer) newForwardMsg(req *msgext.ForwardMsgsReq, user *sdkws.UserInfo, target *msgext.ForwardTarget) *sdkws.MsgData {
+	return &sdkws.MsgData{
+		SendID:           req.UserID,
+		RecvID:           target.RecvID,
+		GroupID:          target.GroupID,
+		ClientMsgID:      utils.GetMsgID(req.UserID),
+		SenderPlatformID: req.SenderPlatformID,
+		SenderNickname:   user.Nickname,
+		SenderFaceURL:    user.FaceURL,
+		SessionType:      target.SessionType,
+		MsgFrom:          constant.UserMsgType,
+		CreateTime:       utils.GetCurrentTimestampByMill(),
+		Options:          make(map[string]bool),
+	}
+}
+
+func (m *msgServer) newMergeMsg(
+	req *msgext.ForwardMsgsReq,
+	user *sdkws.UserInfo,
+	target *msgext.ForwardTarget,
+	sources []*sdkws.MsgData,
+) (*sdkws.MsgData, error) {
+	elem := msgext.MergeElem{
+		Title:        req.Title,
+		AbstractList: make([]string, 0, maxMergeAbstracts),
+		MultiMessage: make([]*msgext.MergedMsg, 0, len(sources)),
+	}
+	for _, source := range sources {
+		if len(elem.AbstractList) < maxMergeAbstracts {
+			elem.AbstractList = append(elem.AbstractList, mergeAbstract(source))
+		}
+		elem.MultiMessage = append(elem.MultiMessage, &msgext.MergedMsg{
+			ClientMsgID:      source.ClientMsgID,
+			ServerMsgID:      source.ServerMsgID,
+			SendID:           source.SendID,
+			RecvID:           source.RecvID,
+			GroupID:          source.GroupID,
+			SenderPlatformID: source.SenderPlatformID,
+			SenderNickname:   source.SenderNickname,
+			SenderFaceURL:    source.SenderFaceURL,
+			SessionType:      source.SessionType,
+			MsgFrom:          source.MsgFrom,
+			ContentType:      source.ContentType,
+			Content:          string(source.Content),
+			Seq:              source.Seq,
+			SendTime:         source.SendTime,
+			CreateTime:       source.CreateTime,
+			Ex:               source.Ex,
+		})
+	}
+	content, err := json.Marshal(&elem)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	msgData := m.newForwardMsg(req, user, target)
+	msgData.ContentType = constant.Merger
+	msgData.Content = content
+	return msgData, nil
+}
+
+func mergeAbstract(msgData *sdkws.MsgData) string {
+	text := "[msg]"
+	switch msgData.ContentType {
+	case constant.Text, constant.AtText:
+		var elem struct {
+			Content string `json:"content"`
+			Text    string `json:"text"`
+		}
+		if err := json.Unmarshal(msgData.Content, &elem); err == nil {
+			if elem.Content != "" {
+				text = elem.Content
+			} else {
+				text = elem.Text
+			}
+		}
+	case constant.Picture:
+		text = "[picture]"
+	case constant.Voice:
+		text = "[voice]"
+	case constant.Video:
+		text = "[video]"
+	case constant.File:
+		text = "[file]"
+	case constant.Merger:
+		text = "[chat history]"
+	case constant.Card:
+		text = "[card]"
+	case constant.Location:
+		text = "[location]"
+	}
+	if runes := []rune(text); len(runes) > 64 {
+		text = string(runes[:64])
+	}
+	return msgData.SenderNickname + ": " + text
+}
+
+func attachForwardInfo(info *msgext.ForwardInfo) string {
+	data, err := json.Marshal(map[string]any{"forwardInfo": info})
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func (m *msgServer) sendForwardMsg(ctx context.Context, target *msgext.ForwardTarget, sourceSeq int64, msgData *sdkws.MsgData) *msgext.ForwardResult {
+	result := &msgext.ForwardResult{Target: target, SourceSeq: sourceSeq, ClientMsgID: msgData.ClientMsgID}
+	resp, err := m.SendMsg(ctx, &pbmsg.SendMsgReq{MsgData: msgData})
+	if err != nil {
+		log.ZWarn(ctx, "forward msg failed", err, "target", target, "sourceSeq", sourceSeq)
+		if codeErr, ok := errs.Unwrap(err).(errs.CodeError); ok {
+			result.ErrCode = int32(codeErr.Code())
+			result.ErrMsg = codeErr.Msg()
+		} else {
+			result.ErrCode = errs.ServerInternalError
+			result.ErrMsg = err.Error()
+		}
+		return result
+	}
+	result.ServerMsgID = resp.ServerMsgID
+	result.SendTime = resp.SendTime
+	return result
+}
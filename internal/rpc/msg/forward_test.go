@@ -0,0 +1,52 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	"github.com/OpenIMSDK/protocol/sdkws"
+)
+
+func TestForwardContent(t *testing.T) {
+	source := &sdkws.MsgData{
+		ContentType: constant.AtText,
+		Content:     []byte(`{"text":"@bob hello","atUserList":["bob"],"isAtSelf":false}`),
+	}
+	contentType, content := forwardContent(source)
+	if contentType != constant.Text {
+		t.Fatalf("content type %d, want %d", contentType, constant.Text)
+	}
+	var elem struct {
+		Content    string   `json:"content"`
+		AtUserList []string `json:"atUserList"`
+	}
+	if err := json.Unmarshal(content, &elem); err != nil {
+		t.Fatal(err)
+	}
+	if elem.Content != "@bob hello" {
+		t.Fatalf("content %q, want %q", elem.Content, "@bob hello")
+	}
+	if len(elem.AtUserList) != 0 {
+		t.Fatalf("mentions forwarded: %v", elem.AtUserList)
+	}
+
+	picture := &sdkws.MsgData{ContentType: constant.Picture, Content: []byte(`{"sourcePicture":{}}`)}
+	if contentType, content := forwardContent(picture); contentType != constant.Picture || string(content) != string(picture.Content) {
+		t.Fatalf("picture changed: %d %s", contentType, content)
+	}
+}
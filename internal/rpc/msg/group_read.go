@@ -0,0 +1,235 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	"github.com/OpenIMSDK/protocol/sdkws"
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/mcontext"
+	"github.com/OpenIMSDK/tools/utils"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+)
+
+const (
+	// maxGroupReadReceiptSeqs the most recent seqs recorded per mark as read request.
+	maxGroupReadReceiptSeqs = 500
+	// groupReadCountNotifyDelay how long read count changes are aggregated before notifying the sender.
+	groupReadCountNotifyDelay = time.Second * 2
+	// groupReadPersistRetry how many times reads of messages not persisted yet are recorded again.
+	groupReadPersistRetry    = 5
+	groupReadPersistInterval = time.Second * 2
+)
+
+func isGroupReadReceiptEnabled(conversationType int32) bool {
+	return conversationType == constant.SuperGroupChatType && config.Config.GroupMessageHasReadReceiptEnable
+}
+
+func isGroupReadReceiptMsg(msgData *sdkws.MsgData) bool {
+	return msgData.SessionType == constant.SuperGroupChatType &&
+		msgData.ContentType < constant.NotificationBegin &&
+		msgData.ContentType != constant.MsgRevokeNotification
+}
+
+// markGroupMsgsAsRead records userID as a reader of the group messages and
+// schedules an aggregated read count notification for each sender.
+func (m *msgServer) markGroupMsgsAsRead(ctx context.Context, userID, conversationID string, seqs []int64) error {
+	if len(seqs) > maxGroupReadReceiptSeqs {
+		seqs = seqs[len(seqs)-maxGroupReadReceiptSeqs:]
+	}
+	_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, userID, conversationID, seqs)
+	if err != nil {
+		return err
+	}
+	senderSeqs := make(map[string][]int64)
+	var groupID string
+	for _, msgData := range msgs {
+		if msgData == nil || msgData.SendID == userID || !isGroupReadReceiptMsg(msgData) {
+			continue
+		}
+		groupID = msgData.GroupID
+		senderSeqs[msgData.SendID] = append(senderSeqs[msgData.SendID], msgData.Seq)
+	}
+	if len(senderSeqs) == 0 {
+		return nil
+	}
+	return m.recordGroupMsgsRead(ctx, userID, conversationID, groupID, senderSeqs, 0)
+}
+
+// recordGroupMsgsRead records the reads of senderSeqs. The messages come from the cache and msg
+// transfer may not have persisted all of them yet, the reads of those are recorded again later.
+func (m *msgServer) recordGroupMsgsRead(ctx context.Context, userID, conversationID, groupID string, senderSeqs map[string][]int64, retry int) error {
+	readSeqs := make([]int64, 0)
+	for _, s := range senderSeqs {
+		readSeqs = append(readSeqs, s...)
+	}
+	unpersisted, err := m.MsgDatabase.MarkGroupMsgsAsRead(ctx, userID, conversationID, readSeqs)
+	if err != nil {
+		return err
+	}
+	unpersistedSet := utils.SliceSet(unpersisted)
+	pending := make(map[string][]int64)
+	for sendID, s := range senderSeqs {
+		read := make([]int64, 0, len(s))
+		for _, seq := range s {
+			if _, ok := unpersistedSet[seq]; ok {
+				pending[sendID] = append(pending[sendID], seq)
+			} else {
+				read = append(read, seq)
+			}
+		}
+		if len(read) == 0 {
+			continue
+		}
+		flush, err := m.MsgDatabase.AddGroupReadReceiptSeqs(ctx, conversationID, sendID, read, groupReadCountNotifyDelay)
+		if err != nil {
+			log.ZWarn(ctx, "AddGroupReadReceiptSeqs", err, "conversationID", conversationID, "sendID", sendID)
+			continue
+		}
+		if flush {
+			nctx := mcontext.NewCtx("@@@" + mcontext.GetOperationID(ctx))
+			sendID := sendID
+			time.AfterFunc(groupReadCountNotifyDelay, func() {
+				m.groupMsgReadCountNotification(nctx, conversationID, groupID, sendID)
+			})
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if retry >= groupReadPersistRetry {
+		log.ZWarn(ctx, "group msgs read not recorded, msgs not persisted", nil, "userID", userID, "conversationID", conversationID, "seqs", unpersisted)
+		return nil
+	}
+	log.ZDebug(ctx, "group msgs not persisted yet", "userID", userID, "conversationID", conversationID, "seqs", unpersisted, "retry", retry)
+	nctx := mcontext.NewCtx("@@@" + mcontext.GetOperationID(ctx))
+	time.AfterFunc(groupReadPersistInterval, func() {
+		if err := m.recordGroupMsgsRead(nctx, userID, conversationID, groupID, pending, retry+1); err != nil {
+			log.ZError(nctx, "recordGroupMsgsRead", err, "userID", userID, "conversationID", conversationID)
+		}
+	})
+	return nil
+}
+
+func (m *msgServer) groupMsgReadCountNotification(ctx context.Context, conversationID, groupID, sendID string) {
+	seqs, err := m.MsgDatabase.PopGroupReadReceiptSeqs(ctx, conversationID, sendID)
+	if err != nil {
+		log.ZError(ctx, "PopGroupReadReceiptSeqs", err, "conversationID", conversationID, "sendID", sendID)
+		return
+	}
+	if len(seqs) == 0 {
+		return
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	counts, err := m.MsgDatabase.GetGroupMsgsReadCount(ctx, conversationID, seqs)
+	if err != nil {
+		log.ZError(ctx, "GetGroupMsgsReadCount", err, "conversationID", conversationID, "seqs", seqs)
+		return
+	}
+	tips := &msgext.GroupMsgReadCountTips{
+		ConversationID: conversationID,
+		GroupID:        groupID,
+		Reads:          make([]*msgext.GroupMsgReadCount, 0, len(seqs)),
+	}
+	if groupInfo, err := m.Group.GetGroupInfoCache(ctx, groupID); err == nil {
+		tips.GroupMemberCount = groupInfo.MemberCount
+	} else {
+		log.ZWarn(ctx, "GetGroupInfoCache", err, "groupID", groupID)
+	}
+	for _, seq := range seqs {
+		tips.Reads = append(tips.Reads, &msgext.GroupMsgReadCount{Seq: seq, ReadCount: counts[seq]})
+	}
+	if err := m.notificationSender.NotificationWithSesstionType(ctx, sendID, sendID, msgext.GroupMsgReadCountNotification, constant.SingleChatType, tips); err != nil {
+		log.ZError(ctx, "GroupMsgReadCountNotification", err, "conversationID", conversationID, "sendID", sendID)
+	}
+}
+
+func (m *msgServer) GetMsgReaders(ctx context.Context, req *msgext.GetMsgReadersReq) (*msgext.GetMsgReadersResp, error) {
+	_, readList, err := m.getGroupMsgReadList(ctx, req.UserID, req.ConversationID, req.Seq)
+	if err != nil {
+		return nil, err
+	}
+	return &msgext.GetMsgReadersResp{UserIDs: readList}, nil
+}
+
+func (m *msgServer) GetMsgUnreadMembers(ctx context.Context, req *msgext.GetMsgUnreadMembersReq) (*msgext.GetMsgUnreadMembersResp, error) {
+	msgData, readList, err := m.getGroupMsgReadList(ctx, req.UserID, req.ConversationID, req.Seq)
+	if err != nil {
+		return nil, err
+	}
+	memberIDs, err := m.Group.GetGroupMemberIDs(ctx, msgData.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := m.Group.GetGroupMemberInfos(ctx, msgData.GroupID, memberIDs, false)
+	if err != nil {
+		return nil, err
+	}
+	read := utils.SliceSet(readList)
+	userIDs := make([]string, 0, len(members))
+	for _, member := range members {
+		if _, ok := read[member.UserID]; ok || member.UserID == msgData.SendID {
+			continue
+		}
+		// members who joined after the msg was sent never received it
+		if member.JoinTime > msgData.SendTime {
+			continue
+		}
+		userIDs = append(userIDs, member.UserID)
+	}
+	return &msgext.GetMsgUnreadMembersResp{UserIDs: userIDs}, nil
+}
+
+func (m *msgServer) getGroupMsgReadList(ctx context.Context, userID, conversationID string, seq int64) (*sdkws.MsgData, []string, error) {
+	if err := authverify.CheckAccessV3(ctx, userID); err != nil {
+		return nil, nil, err
+	}
+	if !config.Config.GroupMessageHasReadReceiptEnable {
+		return nil, nil, errs.ErrNoPermission.Wrap("group read receipt is disabled")
+	}
+	conversation, err := m.Conversation.GetConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if conversation.ConversationType != constant.SuperGroupChatType {
+		return nil, nil, errs.ErrArgs.Wrap("not a group conversation")
+	}
+	if _, err := m.Group.GetGroupMemberCache(ctx, conversation.GroupID, userID); err != nil {
+		return nil, nil, err
+	}
+	_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, userID, conversationID, []int64{seq})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(msgs) == 0 || msgs[0] == nil || !isGroupReadReceiptMsg(msgs[0]) {
+		return nil, nil, errs.ErrRecordNotFound.Wrap("msg not found")
+	}
+	_, readList, err := m.MsgDatabase.GetGroupMsgReadList(ctx, conversationID, seq)
+	if err != nil {
+		return nil, nil, err
+	}
+	if readList == nil {
+		readList = []string{}
+	}
+	return msgs[0], readList, nil
+}
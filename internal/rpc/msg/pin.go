@@ -0,0 +1,243 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+	"time"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	"github.com/OpenIMSDK/protocol/sdkws"
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/log"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	relationtb "github.com/openimsdk/open-im-server/v3/pkg/common/db/table/relation"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+)
+
+// maxPinnedMsgs the most messages that can be pinned in one conversation.
+const maxPinnedMsgs = 100
+
+func (m *msgServer) PinMsg(ctx context.Context, req *msgext.PinMsgReq) (*msgext.PinMsgResp, error) {
+	defer log.ZDebug(ctx, "PinMsg return line")
+	if err := authverify.CheckAccessV3(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	msgData, err := m.getPinnableMsg(ctx, req.UserID, req.ConversationID, req.Seq)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkMsgPinRole(ctx, req.UserID, msgData); err != nil {
+		return nil, err
+	}
+	if _, err := m.PinnedMsgDatabase.TakePinnedMsg(ctx, req.ConversationID, req.Seq); err == nil {
+		return &msgext.PinMsgResp{}, nil
+	} else if !IsNotFound(err) {
+		return nil, err
+	}
+	count, err := m.PinnedMsgDatabase.CountPinnedMsgs(ctx, req.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxPinnedMsgs {
+		return nil, errs.ErrArgs.Wrap("too many pinned msgs in conversation")
+	}
+	now := time.Now()
+	err = m.PinnedMsgDatabase.PinMsg(ctx, &relationtb.PinnedMsgModel{
+		ConversationID: req.ConversationID,
+		Seq:            req.Seq,
+		ClientMsgID:    msgData.ClientMsgID,
+		SendID:         msgData.SendID,
+		SessionType:    msgData.SessionType,
+		PinnerUserID:   req.UserID,
+		PinTime:        now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.pinnedChangedNotification(ctx, req.UserID, req.ConversationID, msgData, true, now)
+	return &msgext.PinMsgResp{}, nil
+}
+
+func (m *msgServer) UnpinMsg(ctx context.Context, req *msgext.UnpinMsgReq) (*msgext.UnpinMsgResp, error) {
+	defer log.ZDebug(ctx, "UnpinMsg return line")
+	if err := authverify.CheckAccessV3(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	pinnedMsg, err := m.PinnedMsgDatabase.TakePinnedMsg(ctx, req.ConversationID, req.Seq)
+	if err != nil {
+		return nil, err
+	}
+	// the msg may have been deleted or cleared since it was pinned, so it is not loaded
+	msgData, err := m.pinnedMsgData(ctx, req.UserID, pinnedMsg)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkMsgPinRole(ctx, req.UserID, msgData); err != nil {
+		return nil, err
+	}
+	if err := m.PinnedMsgDatabase.UnpinMsg(ctx, req.ConversationID, req.Seq); err != nil {
+		return nil, err
+	}
+	m.pinnedChangedNotification(ctx, req.UserID, req.ConversationID, msgData, false, time.Now())
+	return &msgext.UnpinMsgResp{}, nil
+}
+
+// pinnedMsgData rebuilds the fields of a pinned msg that unpinning needs from the pin and
+// the conversation of userID.
+func (m *msgServer) pinnedMsgData(ctx context.Context, userID string, pinnedMsg *relationtb.PinnedMsgModel) (*sdkws.MsgData, error) {
+	conversation, err := m.Conversation.GetConversation(ctx, userID, pinnedMsg.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	msgData := &sdkws.MsgData{
+		SendID:      pinnedMsg.SendID,
+		GroupID:     conversation.GroupID,
+		ClientMsgID: pinnedMsg.ClientMsgID,
+		SessionType: pinnedMsg.SessionType,
+		Seq:         pinnedMsg.Seq,
+	}
+	if pinnedMsg.SessionType == constant.SingleChatType {
+		if pinnedMsg.SendID == userID {
+			msgData.RecvID = conversation.UserID
+		} else {
+			msgData.RecvID = userID
+		}
+	}
+	return msgData, nil
+}
+
+// unpinRevokedMsg removes the pin of a revoked msg.
+func (m *msgServer) unpinRevokedMsg(ctx context.Context, userID, conversationID string, msgData *sdkws.MsgData) {
+	if _, err := m.PinnedMsgDatabase.TakePinnedMsg(ctx, conversationID, msgData.Seq); err != nil {
+		if !IsNotFound(err) {
+			log.ZError(ctx, "TakePinnedMsg failed", err, "conversationID", conversationID, "seq", msgData.Seq)
+		}
+		return
+	}
+	if err := m.PinnedMsgDatabase.UnpinMsg(ctx, conversationID, msgData.Seq); err != nil {
+		log.ZError(ctx, "UnpinMsg failed", err, "conversationID", conversationID, "seq", msgData.Seq)
+		return
+	}
+	m.pinnedChangedNotification(ctx, userID, conversationID, msgData, false, time.Now())
+}
+
+func (m *msgServer) GetPinnedMsgs(ctx context.Context, req *msgext.GetPinnedMsgsReq) (*msgext.GetPinnedMsgsResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	if _, err := m.Conversation.GetConversation(ctx, req.UserID, req.ConversationID); err != nil {
+		return nil, err
+	}
+	pinnedMsgs, err := m.PinnedMsgDatabase.FindPinnedMsgs(ctx, req.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &msgext.GetPinnedMsgsResp{Msgs: make([]*msgext.PinnedMsg, 0, len(pinnedMsgs))}
+	if len(pinnedMsgs) == 0 {
+		return resp, nil
+	}
+	seqs := make([]int64, 0, len(pinnedMsgs))
+	for _, pinnedMsg := range pinnedMsgs {
+		seqs = append(seqs, pinnedMsg.Seq)
+	}
+	_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, req.UserID, req.ConversationID, seqs)
+	if err != nil {
+		return nil, err
+	}
+	m.attachTranslations(ctx, req.ConversationID, msgs)
+	msgMap := make(map[int64]*sdkws.MsgData, len(msgs))
+	for _, msg := range msgs {
+		if msg != nil {
+			msgMap[msg.Seq] = msg
+		}
+	}
+	for _, pinnedMsg := range pinnedMsgs {
+		msg, ok := msgMap[pinnedMsg.Seq]
+		if !ok {
+			// deleted for this user
+			continue
+		}
+		resp.Msgs = append(resp.Msgs, &msgext.PinnedMsg{
+			Seq:          pinnedMsg.Seq,
+			ClientMsgID:  pinnedMsg.ClientMsgID,
+			PinnerUserID: pinnedMsg.PinnerUserID,
+			PinTime:      pinnedMsg.PinTime.UnixMilli(),
+			MsgData:      msg,
+		})
+	}
+	return resp, nil
+}
+
+func (m *msgServer) getPinnableMsg(ctx context.Context, userID, conversationID string, seq int64) (*sdkws.MsgData, error) {
+	_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, userID, conversationID, []int64{seq})
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 || msgs[0] == nil {
+		return nil, errs.ErrRecordNotFound.Wrap("msg not found")
+	}
+	if msgs[0].ContentType == constant.MsgRevokeNotification {
+		return nil, errs.ErrMsgAlreadyRevoke.Wrap("msg already revoke")
+	}
+	if msgs[0].ContentType >= constant.NotificationBegin && msgs[0].ContentType <= constant.NotificationEnd {
+		return nil, errs.ErrArgs.Wrap("notification msg can not be pinned")
+	}
+	return msgs[0], nil
+}
+
+// checkMsgPinRole checks that userID may pin or unpin msgData. Either side of a single chat may pin,
+// in groups only the owner and admins may.
+func (m *msgServer) checkMsgPinRole(ctx context.Context, userID string, msgData *sdkws.MsgData) error {
+	if authverify.IsAppManagerUid(ctx) {
+		return nil
+	}
+	switch msgData.SessionType {
+	case constant.SingleChatType:
+		if userID != msgData.SendID && userID != msgData.RecvID {
+			return errs.ErrNoPermission.Wrap("no permission")
+		}
+		return nil
+	case constant.SuperGroupChatType:
+		member, err := m.Group.GetGroupMemberCache(ctx, msgData.GroupID, userID)
+		if err != nil {
+			return err
+		}
+		switch member.RoleLevel {
+		case constant.GroupOwner, constant.GroupAdmin:
+			return nil
+		default:
+			return errs.ErrNoPermission.Wrap("no permission")
+		}
+	default:
+		return errs.ErrInternalServer.Wrap("msg sessionType not supported")
+	}
+}
+
+func (m *msgServer) pinnedChangedNotification(ctx context.Context, userID, conversationID string, msgData *sdkws.MsgData, isPinned bool, operateTime time.Time) {
+	tips := msgext.MsgPinnedChangedTips{
+		OperatorUserID: userID,
+		ConversationID: conversationID,
+		SessionType:    msgData.SessionType,
+		Seq:            msgData.Seq,
+		ClientMsgID:    msgData.ClientMsgID,
+		IsPinned:       isPinned,
+		OperateTime:    operateTime.UnixMilli(),
+	}
+	if err := m.notificationSender.NotificationWithSesstionType(ctx, userID, msgConversationRecvID(msgData, userID), msgext.MsgPinnedChangedNotification, msgData.SessionType, &tips); err != nil {
+		log.ZError(ctx, "MsgPinnedChangedNotification failed", err, "conversationID", conversationID, "seq", msgData.Seq)
+	}
+}
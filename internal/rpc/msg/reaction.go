@@ -0,0 +1,128 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+	"sort"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	"github.com/OpenIMSDK/protocol/sdkws"
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/log"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+)
+
+func (m *msgServer) AddMsgReaction(ctx context.Context, req *msgext.AddMsgReactionReq) (*msgext.AddMsgReactionResp, error) {
+	reaction, err := m.modifyMsgReaction(ctx, req.UserID, req.ConversationID, req.Seq, req.ReactionType, true)
+	if err != nil {
+		return nil, err
+	}
+	return &msgext.AddMsgReactionResp{Reaction: reaction}, nil
+}
+
+func (m *msgServer) DeleteMsgReaction(ctx context.Context, req *msgext.DeleteMsgReactionReq) (*msgext.DeleteMsgReactionResp, error) {
+	reaction, err := m.modifyMsgReaction(ctx, req.UserID, req.ConversationID, req.Seq, req.ReactionType, false)
+	if err != nil {
+		return nil, err
+	}
+	return &msgext.DeleteMsgReactionResp{Reaction: reaction}, nil
+}
+
+func (m *msgServer) GetMsgReactions(ctx context.Context, req *msgext.GetMsgReactionsReq) (*msgext.GetMsgReactionsResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	if _, err := m.Conversation.GetConversation(ctx, req.UserID, req.ConversationID); err != nil {
+		return nil, err
+	}
+	_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, req.UserID, req.ConversationID, req.Seqs)
+	if err != nil {
+		return nil, err
+	}
+	resp := &msgext.GetMsgReactionsResp{Msgs: make([]*msgext.MsgReactions, 0, len(msgs))}
+	for _, msg := range msgs {
+		if msg == nil || msg.ClientMsgID == "" || msg.ContentType == constant.MsgRevokeNotification {
+			continue
+		}
+		reactions, err := m.MsgDatabase.GetMsgReactions(ctx, req.ConversationID, msg.Seq, msg.ClientMsgID, msg.SessionType)
+		if err != nil {
+			return nil, err
+		}
+		types := make([]string, 0, len(reactions))
+		for reactionType := range reactions {
+			types = append(types, reactionType)
+		}
+		sort.Strings(types)
+		item := &msgext.MsgReactions{Seq: msg.Seq, ClientMsgID: msg.ClientMsgID, Reactions: make([]*msgext.Reaction, 0, len(types))}
+		for _, reactionType := range types {
+			item.Reactions = append(item.Reactions, &msgext.Reaction{ReactionType: reactionType, UserIDs: reactions[reactionType]})
+		}
+		resp.Msgs = append(resp.Msgs, item)
+	}
+	return resp, nil
+}
+
+func (m *msgServer) modifyMsgReaction(ctx context.Context, userID, conversationID string, seq int64, reactionType string, isAdd bool) (*msgext.Reaction, error) {
+	defer log.ZDebug(ctx, "modifyMsgReaction return line", "userID", userID, "conversationID", conversationID, "seq", seq, "reactionType", reactionType, "isAdd", isAdd)
+	if err := authverify.CheckAccessV3(ctx, userID); err != nil {
+		return nil, err
+	}
+	if _, err := m.Conversation.GetConversation(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+	_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, userID, conversationID, []int64{seq})
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 || msgs[0] == nil || msgs[0].ClientMsgID == "" {
+		return nil, errs.ErrRecordNotFound.Wrap("msg not found")
+	}
+	msg := msgs[0]
+	if msg.ContentType == constant.MsgRevokeNotification {
+		return nil, errs.ErrMsgAlreadyRevoke.Wrap("msg already revoke")
+	}
+	userIDs, err := m.MsgDatabase.ModifyMsgReaction(ctx, conversationID, seq, msg.ClientMsgID, msg.SessionType, reactionType, userID, isAdd)
+	if err != nil {
+		return nil, err
+	}
+	reaction := &msgext.Reaction{ReactionType: reactionType, UserIDs: userIDs}
+	tips := msgext.MsgReactionChangedTips{
+		OperatorUserID: userID,
+		ConversationID: conversationID,
+		SessionType:    msg.SessionType,
+		Seq:            seq,
+		ClientMsgID:    msg.ClientMsgID,
+		IsAdd:          isAdd,
+		Reaction:       reaction,
+	}
+	if err := m.notificationSender.NotificationWithSesstionType(ctx, userID, msgConversationRecvID(msg, userID), msgext.MsgReactionChangedNotification, msg.SessionType, &tips); err != nil {
+		log.ZError(ctx, "MsgReactionChangedNotification failed", err, "conversationID", conversationID, "seq", seq)
+	}
+	return reaction, nil
+}
+
+// msgConversationRecvID the recvID a notification about msg sent by userID should use to reach its conversation.
+func msgConversationRecvID(msg *sdkws.MsgData, userID string) string {
+	if msg.SessionType == constant.SuperGroupChatType {
+		return msg.GroupID
+	}
+	if msg.SendID == userID {
+		return msg.RecvID
+	}
+	return msg.SendID
+}
@@ -0,0 +1,208 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	pbmsg "github.com/OpenIMSDK/protocol/msg"
+	"github.com/OpenIMSDK/protocol/sdkws"
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/utils"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	unrelationtb "github.com/openimsdk/open-im-server/v3/pkg/common/db/table/unrelation"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+)
+
+// maxScheduleDelay how far in the future a message may be scheduled.
+const maxScheduleDelay = time.Hour * 24 * 365
+
+func checkScheduleSendTime(sendTime int64) error {
+	now := time.Now()
+	if sendTime <= now.UnixMilli() {
+		return errs.ErrArgs.Wrap("sendTime must be in the future")
+	}
+	if sendTime > now.Add(maxScheduleDelay).UnixMilli() {
+		return errs.ErrArgs.Wrap("sendTime is too far in the future")
+	}
+	return nil
+}
+
+func (m *msgServer) ScheduleMsg(ctx context.Context, req *msgext.ScheduleMsgReq) (*msgext.ScheduleMsgResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.MsgData.SendID); err != nil {
+		return nil, err
+	}
+	scheduled, err := m.scheduleMsg(ctx, req.MsgData, req.SendTime)
+	if err != nil {
+		return nil, err
+	}
+	return &msgext.ScheduleMsgResp{
+		ScheduleID:  scheduled.ScheduleID,
+		ClientMsgID: scheduled.ClientMsgID,
+		SendTime:    scheduled.SendTime,
+	}, nil
+}
+
+// sendScheduledMsg schedules a message sent with the msgext.ScheduledSendOption option,
+// the send time of the message is the time it is delivered at.
+func (m *msgServer) sendScheduledMsg(ctx context.Context, req *pbmsg.SendMsgReq) (*pbmsg.SendMsgResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.MsgData.SendID); err != nil {
+		return nil, err
+	}
+	delete(req.MsgData.Options, msgext.ScheduledSendOption)
+	scheduled, err := m.scheduleMsg(ctx, req.MsgData, req.MsgData.SendTime)
+	if err != nil {
+		return nil, err
+	}
+	return &pbmsg.SendMsgResp{
+		ClientMsgID: scheduled.ClientMsgID,
+		SendTime:    scheduled.SendTime,
+	}, nil
+}
+
+func (m *msgServer) scheduleMsg(ctx context.Context, msgData *sdkws.MsgData, sendTime int64) (*unrelationtb.ScheduledMsgModel, error) {
+	if err := checkScheduleSendTime(sendTime); err != nil {
+		return nil, err
+	}
+	switch msgData.SessionType {
+	case constant.SingleChatType, constant.NotificationChatType:
+		if msgData.RecvID == "" {
+			return nil, errs.ErrArgs.Wrap("recvID is empty")
+		}
+	case constant.SuperGroupChatType:
+		if msgData.GroupID == "" {
+			return nil, errs.ErrArgs.Wrap("groupID is empty")
+		}
+	default:
+		return nil, errs.ErrArgs.Wrap("unknown sessionType")
+	}
+	if !isMessageHasReadEnabled(msgData) {
+		return nil, errs.ErrMessageHasReadDisable.Wrap()
+	}
+	// checked again when the message is delivered
+	if err := m.messageVerification(ctx, &pbmsg.SendMsgReq{MsgData: msgData}); err != nil {
+		return nil, err
+	}
+	if msgData.ClientMsgID == "" {
+		msgData.ClientMsgID = utils.GetMsgID(msgData.SendID)
+	}
+	// the real send time is assigned on delivery
+	msgData.SendTime = 0
+	data, err := proto.Marshal(msgData)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	now := time.Now().UnixMilli()
+	scheduled := &unrelationtb.ScheduledMsgModel{
+		ScheduleID:  GetMsgID(msgData.SendID),
+		SendID:      msgData.SendID,
+		RecvID:      msgData.RecvID,
+		GroupID:     msgData.GroupID,
+		SessionType: msgData.SessionType,
+		ClientMsgID: msgData.ClientMsgID,
+		SendTime:    sendTime,
+		Status:      unrelationtb.ScheduledMsgStatusPending,
+		MsgData:     data,
+		CreateTime:  now,
+		UpdateTime:  now,
+	}
+	if err := m.ScheduledMsgDatabase.CreateScheduledMsg(ctx, scheduled); err != nil {
+		return nil, err
+	}
+	log.ZInfo(ctx, "msg scheduled", "scheduleID", scheduled.ScheduleID, "clientMsgID", scheduled.ClientMsgID, "sendTime", scheduled.SendTime)
+	return scheduled, nil
+}
+
+func (m *msgServer) GetScheduledMsgs(ctx context.Context, req *msgext.GetScheduledMsgsReq) (*msgext.GetScheduledMsgsResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	total, scheduledMsgs, err := m.ScheduledMsgDatabase.PageScheduledMsgs(ctx, req.UserID, req.Status, req.Pagination.PageNumber, req.Pagination.ShowNumber)
+	if err != nil {
+		return nil, err
+	}
+	resp := &msgext.GetScheduledMsgsResp{Total: total, Msgs: make([]*msgext.ScheduledMsg, 0, len(scheduledMsgs))}
+	for _, scheduled := range scheduledMsgs {
+		var msgData sdkws.MsgData
+		if err := proto.Unmarshal(scheduled.MsgData, &msgData); err != nil {
+			log.ZError(ctx, "unmarshal scheduled msg failed", err, "scheduleID", scheduled.ScheduleID)
+			continue
+		}
+		resp.Msgs = append(resp.Msgs, &msgext.ScheduledMsg{
+			ScheduleID: scheduled.ScheduleID,
+			SendTime:   scheduled.SendTime,
+			Status:     scheduled.Status,
+			Ex:         scheduled.Ex,
+			CreateTime: scheduled.CreateTime,
+			UpdateTime: scheduled.UpdateTime,
+			MsgData:    &msgData,
+		})
+	}
+	return resp, nil
+}
+
+func (m *msgServer) CancelScheduledMsg(ctx context.Context, req *msgext.CancelScheduledMsgReq) (*msgext.CancelScheduledMsgResp, error) {
+	if err := m.checkScheduledMsgPending(ctx, req.UserID, req.ScheduleID); err != nil {
+		return nil, err
+	}
+	ok, err := m.ScheduledMsgDatabase.CancelScheduledMsg(ctx, req.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errs.ErrArgs.Wrap("scheduled msg is no longer pending")
+	}
+	return &msgext.CancelScheduledMsgResp{}, nil
+}
+
+func (m *msgServer) RescheduleMsg(ctx context.Context, req *msgext.RescheduleMsgReq) (*msgext.RescheduleMsgResp, error) {
+	if err := checkScheduleSendTime(req.SendTime); err != nil {
+		return nil, err
+	}
+	if err := m.checkScheduledMsgPending(ctx, req.UserID, req.ScheduleID); err != nil {
+		return nil, err
+	}
+	ok, err := m.ScheduledMsgDatabase.RescheduleMsg(ctx, req.ScheduleID, req.SendTime)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errs.ErrArgs.Wrap("scheduled msg is no longer pending")
+	}
+	return &msgext.RescheduleMsgResp{}, nil
+}
+
+func (m *msgServer) checkScheduledMsgPending(ctx context.Context, userID string, scheduleID string) error {
+	if err := authverify.CheckAccessV3(ctx, userID); err != nil {
+		return err
+	}
+	scheduled, err := m.ScheduledMsgDatabase.TakeScheduledMsg(ctx, scheduleID)
+	if err != nil {
+		return err
+	}
+	if scheduled.SendID != userID {
+		return errs.ErrNoPermission.Wrap("not the sender of the scheduled msg")
+	}
+	if scheduled.Status != unrelationtb.ScheduledMsgStatusPending {
+		return errs.ErrArgs.Wrap("scheduled msg is no longer pending")
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	"github.com/OpenIMSDK/protocol/sdkws"
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/utils"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/searchindex"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+)
+
+func (m *msgServer) SearchMsg(ctx context.Context, req *msgext.SearchMsgReq) (*msgext.SearchMsgResp, error) {
+	if m.searchIndex == nil {
+		return nil, errs.ErrArgs.Wrap("search index is not enabled")
+	}
+	if err := authverify.CheckAccessV3(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	conversationIDs, err := m.Conversation.GetConversationIDs(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.ConversationIDs) > 0 {
+		conversationIDs = utils.IntersectString(conversationIDs, req.ConversationIDs)
+	}
+	resp := &msgext.SearchMsgResp{Msgs: []*msgext.SearchedMsg{}}
+	if len(conversationIDs) == 0 {
+		return resp, nil
+	}
+	hits, nextCursor, err := m.searchIndex.Search(ctx, &searchindex.Query{
+		Keyword:         req.Keyword,
+		ConversationIDs: conversationIDs,
+		Cursor:          req.Cursor,
+		Count:           int(req.Count),
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp.NextCursor = nextCursor
+	// messages are loaded per conversation, so deleted and cleared messages of the user are dropped
+	conversationSeqs := make(map[string][]int64)
+	for _, hit := range hits {
+		conversationSeqs[hit.ConversationID] = append(conversationSeqs[hit.ConversationID], hit.Seq)
+	}
+	type msgKey struct {
+		conversationID string
+		seq            int64
+	}
+	msgs := make(map[msgKey]*sdkws.MsgData)
+	for conversationID, seqs := range conversationSeqs {
+		_, _, conversationMsgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, req.UserID, conversationID, seqs)
+		if err != nil {
+			return nil, err
+		}
+		m.attachTranslations(ctx, conversationID, conversationMsgs)
+		for _, msg := range conversationMsgs {
+			if msg == nil || msg.ContentType == constant.MsgRevokeNotification {
+				continue
+			}
+			msgs[msgKey{conversationID: conversationID, seq: msg.Seq}] = msg
+		}
+	}
+	for _, hit := range hits {
+		msg, ok := msgs[msgKey{conversationID: hit.ConversationID, seq: hit.Seq}]
+		if !ok {
+			log.ZDebug(ctx, "searched msg not visible", "conversationID", hit.ConversationID, "seq", hit.Seq)
+			continue
+		}
+		resp.Msgs = append(resp.Msgs, &msgext.SearchedMsg{ConversationID: hit.ConversationID, Score: hit.Score, Msg: msg})
+	}
+	return resp, nil
+}
+
+// updateSearchIndex re-indexes msg, or removes it from the index when it has no text left.
+func (m *msgServer) updateSearchIndex(ctx context.Context, conversationID string, msg *sdkws.MsgData) {
+	if m.searchIndex == nil {
+		return
+	}
+	doc := searchindex.NewDoc(conversationID, msg)
+	if doc == nil {
+		m.deleteFromSearchIndex(ctx, conversationID, msg.Seq)
+		return
+	}
+	if err := m.searchIndex.Index(ctx, []*searchindex.Doc{doc}); err != nil {
+		log.ZError(ctx, "update search index failed", err, "conversationID", conversationID, "seq", msg.Seq)
+	}
+}
+
+func (m *msgServer) deleteFromSearchIndex(ctx context.Context, conversationID string, seq int64) {
+	if m.searchIndex == nil {
+		return
+	}
+	if err := m.searchIndex.Delete(ctx, conversationID, []int64{seq}); err != nil {
+		log.ZError(ctx, "delete from search index failed", err, "conversationID", conversationID, "seq", seq)
+	}
+}
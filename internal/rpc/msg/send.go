@@ -30,15 +30,22 @@ import (
 	"github.com/OpenIMSDK/tools/utils"
 
 	promepkg "github.com/openimsdk/open-im-server/v3/pkg/common/prome"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
 )
 
 func (m *msgServer) SendMsg(ctx context.Context, req *pbmsg.SendMsgReq) (resp *pbmsg.SendMsgResp, error error) {
 	resp = &pbmsg.SendMsgResp{}
 	if req.MsgData != nil {
+		if req.MsgData.Options[msgext.ScheduledSendOption] {
+			return m.sendScheduledMsg(ctx, req)
+		}
 		flag := isMessageHasReadEnabled(req.MsgData)
 		if !flag {
 			return nil, errs.ErrMessageHasReadDisable.Wrap()
 		}
+		if err := m.execInterceptorHandler(ctx, req); err != nil {
+			return nil, err
+		}
 		m.encapsulateMsgData(req.MsgData)
 		switch req.MsgData.SessionType {
 		case constant.SingleChatType:
@@ -0,0 +1,235 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	pbmsg "github.com/OpenIMSDK/protocol/msg"
+	"github.com/OpenIMSDK/protocol/sdkws"
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/mcontext"
+	"github.com/OpenIMSDK/tools/utils"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/controller"
+	unrelationtb "github.com/openimsdk/open-im-server/v3/pkg/common/db/table/unrelation"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/sensitive"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+)
+
+// sensitiveWordFilter keeps the dictionary of config and mongo in memory and
+// reloads it when the version in redis changes.
+type sensitiveWordFilter struct {
+	db      controller.SensitiveWordDatabase
+	lock    sync.RWMutex
+	filter  *sensitive.Filter
+	version int64
+}
+
+func newSensitiveWordFilter(db controller.SensitiveWordDatabase) *sensitiveWordFilter {
+	return &sensitiveWordFilter{db: db, version: -1}
+}
+
+func (f *sensitiveWordFilter) load(ctx context.Context) error {
+	version, err := f.db.GetSensitiveWordVersion(ctx)
+	if err != nil {
+		return err
+	}
+	f.lock.RLock()
+	loaded := f.version == version
+	f.lock.RUnlock()
+	if loaded {
+		return nil
+	}
+	models, err := f.db.FindAllSensitiveWords(ctx)
+	if err != nil {
+		return err
+	}
+	words := make([]*sensitive.Word, 0, len(config.Config.SensitiveWord.Words)+len(models))
+	for _, word := range config.Config.SensitiveWord.Words {
+		words = append(words, &sensitive.Word{Word: word})
+	}
+	for _, model := range models {
+		words = append(words, &sensitive.Word{Word: model.Word, Category: model.Category})
+	}
+	actions := make(map[string]sensitive.Action, len(config.Config.SensitiveWord.Categories))
+	for category, action := range config.Config.SensitiveWord.Categories {
+		actions[category] = sensitive.Action(action)
+	}
+	filter := sensitive.NewFilter(words, actions, sensitive.Action(config.Config.SensitiveWord.DefaultAction))
+	f.lock.Lock()
+	f.filter = filter
+	f.version = version
+	f.lock.Unlock()
+	log.ZInfo(ctx, "sensitive words loaded", "version", version, "count", len(words))
+	return nil
+}
+
+func (f *sensitiveWordFilter) run() {
+	interval := time.Duration(config.Config.SensitiveWord.ReloadInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Second * 10
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx := mcontext.NewCtx(utils.GetSelfFuncName())
+		if err := f.load(ctx); err != nil {
+			log.ZError(ctx, "reload sensitive words failed", err)
+		}
+	}
+}
+
+// filterContent applies the dictionary to the text of Text, AtText and Quote contents.
+func (f *sensitiveWordFilter) filterContent(ctx context.Context, contentType int32, content []byte) ([]byte, error) {
+	var field string
+	switch contentType {
+	case constant.Text:
+		field = "content"
+	case constant.AtText, constant.Quote:
+		field = "text"
+	default:
+		return content, nil
+	}
+	f.lock.RLock()
+	filter := f.filter
+	f.lock.RUnlock()
+	if filter == nil {
+		return content, nil
+	}
+	var elem map[string]json.RawMessage
+	if err := json.Unmarshal(content, &elem); err != nil {
+		// text sent by the api is stored without the json element
+		if contentType != constant.Text {
+			return content, nil
+		}
+		res, err := f.apply(ctx, filter, string(content))
+		if err != nil {
+			return nil, err
+		}
+		return []byte(res), nil
+	}
+	var text string
+	if err := json.Unmarshal(elem[field], &text); err != nil || text == "" {
+		return content, nil
+	}
+	res, err := f.apply(ctx, filter, text)
+	if err != nil {
+		return nil, err
+	}
+	if res == text {
+		return content, nil
+	}
+	if elem[field], err = json.Marshal(res); err != nil {
+		return nil, err
+	}
+	return json.Marshal(elem)
+}
+
+func (f *sensitiveWordFilter) apply(ctx context.Context, filter *sensitive.Filter, text string) (string, error) {
+	res := filter.Check(text)
+	if res.Action == "" {
+		return text, nil
+	}
+	hits := make([]string, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		hits = append(hits, hit.Category+":"+hit.Word)
+	}
+	log.ZInfo(ctx, "msg contains sensitive words", "action", res.Action, "hits", strings.Join(hits, ","))
+	if res.Action == sensitive.ActionBlock {
+		return "", sensitive.ErrSensitiveWord.Wrap()
+	}
+	return res.Text, nil
+}
+
+func (f *sensitiveWordFilter) intercept(ctx context.Context, req *pbmsg.SendMsgReq) (*sdkws.MsgData, error) {
+	content, err := f.filterContent(ctx, req.MsgData.ContentType, req.MsgData.Content)
+	if err != nil {
+		return nil, err
+	}
+	req.MsgData.Content = content
+	return req.MsgData, nil
+}
+
+// filterSensitiveContent is used by operations that change message content outside SendMsg.
+func (m *msgServer) filterSensitiveContent(ctx context.Context, contentType int32, content []byte) ([]byte, error) {
+	if m.sensitiveWordFilter == nil {
+		return content, nil
+	}
+	return m.sensitiveWordFilter.filterContent(ctx, contentType, content)
+}
+
+func (m *msgServer) AddSensitiveWords(ctx context.Context, req *msgext.AddSensitiveWordsReq) (*msgext.AddSensitiveWordsResp, error) {
+	if err := authverify.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	now := time.Now().UnixMilli()
+	words := make([]*unrelationtb.SensitiveWordModel, 0, len(req.Words))
+	for _, word := range req.Words {
+		words = append(words, &unrelationtb.SensitiveWordModel{
+			Word:       word.Word,
+			Category:   word.Category,
+			CreateTime: now,
+			Ex:         word.Ex,
+		})
+	}
+	if err := m.SensitiveWordDatabase.AddSensitiveWords(ctx, words); err != nil {
+		return nil, err
+	}
+	return &msgext.AddSensitiveWordsResp{}, nil
+}
+
+func (m *msgServer) DeleteSensitiveWords(ctx context.Context, req *msgext.DeleteSensitiveWordsReq) (*msgext.DeleteSensitiveWordsResp, error) {
+	if err := authverify.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if err := m.SensitiveWordDatabase.DeleteSensitiveWords(ctx, req.Words); err != nil {
+		return nil, err
+	}
+	return &msgext.DeleteSensitiveWordsResp{}, nil
+}
+
+func (m *msgServer) SearchSensitiveWords(ctx context.Context, req *msgext.SearchSensitiveWordsReq) (*msgext.SearchSensitiveWordsResp, error) {
+	if err := authverify.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	total, words, err := m.SensitiveWordDatabase.SearchSensitiveWords(
+		ctx,
+		req.Keyword,
+		req.Category,
+		req.Pagination.PageNumber,
+		req.Pagination.ShowNumber,
+	)
+	if err != nil {
+		return nil, err
+	}
+	resp := &msgext.SearchSensitiveWordsResp{Total: total, Words: make([]*msgext.SensitiveWord, 0, len(words))}
+	for _, word := range words {
+		resp.Words = append(resp.Words, &msgext.SensitiveWord{
+			Word:       word.Word,
+			Category:   word.Category,
+			CreateTime: word.CreateTime,
+			Ex:         word.Ex,
+		})
+	}
+	return resp, nil
+}
@@ -24,12 +24,19 @@ import (
 	"github.com/OpenIMSDK/protocol/msg"
 	"github.com/OpenIMSDK/tools/discoveryregistry"
 
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/db/cache"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/db/controller"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/db/localcache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/relation"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/searchindex"
+	relationtb "github.com/openimsdk/open-im-server/v3/pkg/common/db/table/relation"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/db/unrelation"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/locker"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/prome"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/translator"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
 )
 
 type (
@@ -45,6 +52,13 @@ type (
 		ConversationLocalCache *localcache.ConversationLocalCache
 		Handlers               MessageInterceptorChain
 		notificationSender     *rpcclient.NotificationSender
+		searchIndex            searchindex.SearchIndex
+		translator             translator.Translator
+		MessageLocker          locker.MessageLocker
+		ScheduledMsgDatabase   controller.ScheduledMsgDatabase
+		PinnedMsgDatabase      controller.PinnedMsgDatabase
+		SensitiveWordDatabase  controller.SensitiveWordDatabase
+		sensitiveWordFilter    *sensitiveWordFilter
 	}
 )
 
@@ -75,6 +89,19 @@ func Start(client discoveryregistry.SvcDiscoveryRegistry, server *grpc.Server) e
 	if err := mongo.CreateMsgIndex(); err != nil {
 		return err
 	}
+	if err := mongo.CreateScheduledMsgIndex(); err != nil {
+		return err
+	}
+	if err := mongo.CreateSensitiveWordIndex(); err != nil {
+		return err
+	}
+	db, err := relation.NewGormDB()
+	if err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(&relationtb.PinnedMsgModel{}); err != nil {
+		return err
+	}
 	cacheModel := cache.NewMsgCacheModel(rdb)
 	msgDocModel := unrelation.NewMsgMongoDriver(mongo.GetDatabase())
 	conversationClient := rpcclient.NewConversationRpcClient(client)
@@ -82,6 +109,14 @@ func Start(client discoveryregistry.SvcDiscoveryRegistry, server *grpc.Server) e
 	groupRpcClient := rpcclient.NewGroupRpcClient(client)
 	friendRpcClient := rpcclient.NewFriendRpcClient(client)
 	msgDatabase := controller.NewCommonMsgDatabase(msgDocModel, cacheModel)
+	searchIndex, err := searchindex.NewSearchIndex(mongo.GetDatabase())
+	if err != nil {
+		return err
+	}
+	msgTranslator, err := translator.NewTranslator()
+	if err != nil {
+		return err
+	}
 	s := &msgServer{
 		Conversation:           &conversationClient,
 		User:                   &userRpcClient,
@@ -91,11 +126,29 @@ func Start(client discoveryregistry.SvcDiscoveryRegistry, server *grpc.Server) e
 		GroupLocalCache:        localcache.NewGroupLocalCache(&groupRpcClient),
 		ConversationLocalCache: localcache.NewConversationLocalCache(&conversationClient),
 		friend:                 &friendRpcClient,
+		searchIndex:            searchIndex,
+		translator:             msgTranslator,
+		MessageLocker:          locker.NewLockerMessage(cacheModel),
+		ScheduledMsgDatabase:   controller.NewScheduledMsgDatabase(unrelation.NewScheduledMsgMongoDriver(mongo.GetDatabase()), cache.NewScheduledMsgCacheRedis(rdb)),
+		PinnedMsgDatabase:      controller.NewPinnedMsgDatabase(relation.NewPinnedMsgGorm(db)),
+		SensitiveWordDatabase: controller.NewSensitiveWordDatabase(
+			unrelation.NewSensitiveWordMongoDriver(mongo.GetDatabase()),
+			cache.NewSensitiveWordCacheRedis(rdb),
+		),
 	}
 	s.notificationSender = rpcclient.NewNotificationSender(rpcclient.WithLocalSendMsg(s.SendMsg))
 	s.addInterceptorHandler(MessageHasReadEnabled)
+	if config.Config.SensitiveWord.Enable {
+		s.sensitiveWordFilter = newSensitiveWordFilter(s.SensitiveWordDatabase)
+		if err := s.sensitiveWordFilter.load(context.Background()); err != nil {
+			return err
+		}
+		go s.sensitiveWordFilter.run()
+		s.addInterceptorHandler(s.sensitiveWordFilter.intercept)
+	}
 	s.initPrometheus()
 	msg.RegisterMsgServer(server, s)
+	msgext.RegisterMsgExtServer(server, s)
 	return nil
 }
 
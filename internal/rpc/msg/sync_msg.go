@@ -62,6 +62,7 @@ func (m *msgServer) PullMessageBySeqs(
 			case sdkws.PullOrder_PullOrderDesc:
 				isEnd = seq.Begin <= minSeq
 			}
+			m.attachTranslations(ctx, seq.ConversationID, msgs)
 			resp.Msgs[seq.ConversationID] = &sdkws.PullMsgs{Msgs: msgs, IsEnd: isEnd}
 		} else {
 			var seqs []int64
@@ -80,6 +81,7 @@ func (m *msgServer) PullMessageBySeqs(
 			case sdkws.PullOrder_PullOrderDesc:
 				isEnd = seq.Begin <= minSeq
 			}
+			m.attachTranslations(ctx, seq.ConversationID, notificationMsgs)
 			resp.NotificationMsgs[seq.ConversationID] = &sdkws.PullMsgs{Msgs: notificationMsgs, IsEnd: isEnd}
 		}
 	}
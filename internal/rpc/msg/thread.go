@@ -0,0 +1,182 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	pbmsg "github.com/OpenIMSDK/protocol/msg"
+	"github.com/OpenIMSDK/protocol/sdkws"
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/mcontext"
+	"github.com/OpenIMSDK/tools/utils"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	unrelationtb "github.com/openimsdk/open-im-server/v3/pkg/common/db/table/unrelation"
+	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+)
+
+func (m *msgServer) SendThreadReply(ctx context.Context, req *msgext.SendThreadReplyReq) (*msgext.SendThreadReplyResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.MsgData.SendID); err != nil {
+		return nil, err
+	}
+	if msgprocessor.IsThreadConversation(req.ConversationID) {
+		return nil, errs.ErrArgs.Wrap("threads can not be nested")
+	}
+	if msgprocessor.GetChatConversationIDByMsg(req.MsgData) != req.ConversationID {
+		return nil, errs.ErrArgs.Wrap("msgData does not belong to conversationID")
+	}
+	if msgprocessor.IsNotificationByMsg(req.MsgData) {
+		return nil, errs.ErrArgs.Wrap("notification can not be a thread reply")
+	}
+	root, err := m.getThreadRoot(ctx, req.MsgData.SendID, req.ConversationID, req.RootSeq)
+	if err != nil {
+		return nil, err
+	}
+	if !isMessageHasReadEnabled(req.MsgData) {
+		return nil, errs.ErrMessageHasReadDisable.Wrap()
+	}
+	sendReq := &pbmsg.SendMsgReq{MsgData: req.MsgData}
+	if err := m.execInterceptorHandler(ctx, sendReq); err != nil {
+		return nil, err
+	}
+	if err := m.messageVerification(ctx, sendReq); err != nil {
+		return nil, err
+	}
+	if req.MsgData.ClientMsgID == "" {
+		req.MsgData.ClientMsgID = utils.GetMsgID(req.MsgData.SendID)
+	}
+	m.encapsulateMsgData(req.MsgData)
+	threadConversationID := msgprocessor.GetThreadConversationID(req.ConversationID, req.RootSeq)
+	if err := m.MsgDatabase.InsertThreadReply(ctx, threadConversationID, req.MsgData); err != nil {
+		return nil, err
+	}
+	thread := &unrelationtb.ThreadModel{
+		ThreadConversationID: threadConversationID,
+		LastReplySeq:         req.MsgData.Seq,
+		LastReplySendID:      req.MsgData.SendID,
+		LastReplyClientMsgID: req.MsgData.ClientMsgID,
+		LastReplyTime:        req.MsgData.SendTime,
+	}
+	// the root may not be persisted yet, so the summary is updated without holding up the reply
+	go m.updateThreadSummary(mcontext.WithOpUserIDContext(mcontext.NewCtx(mcontext.GetOperationID(ctx)), mcontext.GetOpUserID(ctx)), req.ConversationID, root, thread, req.MsgData)
+	return &msgext.SendThreadReplyResp{
+		ThreadConversationID: threadConversationID,
+		Seq:                  req.MsgData.Seq,
+		ServerMsgID:          req.MsgData.ServerMsgID,
+		ClientMsgID:          req.MsgData.ClientMsgID,
+		SendTime:             req.MsgData.SendTime,
+	}, nil
+}
+
+// updateThreadSummary counts reply in the thread summary of root and notifies the conversation.
+func (m *msgServer) updateThreadSummary(ctx context.Context, conversationID string, root *sdkws.MsgData, thread *unrelationtb.ThreadModel, reply *sdkws.MsgData) {
+	summary, err := m.MsgDatabase.UpdateMsgThread(ctx, conversationID, root.Seq, thread)
+	if err != nil {
+		log.ZError(ctx, "UpdateMsgThread failed", err, "conversationID", conversationID, "rootSeq", root.Seq)
+		return
+	}
+	tips := msgext.ThreadReplyTips{
+		ConversationID: conversationID,
+		Summary:        threadSummary(root, summary),
+		Reply:          reply,
+	}
+	recvID := reply.RecvID
+	if reply.SessionType == constant.SuperGroupChatType {
+		recvID = reply.GroupID
+	}
+	if err := m.notificationSender.NotificationWithSesstionType(ctx, reply.SendID, recvID, msgext.MsgThreadReplyNotification, reply.SessionType, &tips); err != nil {
+		log.ZError(ctx, "MsgThreadReplyNotification failed", err, "threadConversationID", thread.ThreadConversationID)
+	}
+}
+
+func (m *msgServer) PullThreadMsgs(ctx context.Context, req *msgext.PullThreadMsgsReq) (*msgext.PullThreadMsgsResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	if _, err := m.Conversation.GetConversation(ctx, req.UserID, req.ConversationID); err != nil {
+		return nil, err
+	}
+	threadConversationID := msgprocessor.GetThreadConversationID(req.ConversationID, req.RootSeq)
+	minSeq, maxSeq, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, req.UserID, threadConversationID, req.Seqs)
+	if err != nil {
+		return nil, err
+	}
+	m.attachTranslations(ctx, threadConversationID, msgs)
+	return &msgext.PullThreadMsgsResp{
+		ThreadConversationID: threadConversationID,
+		MinSeq:               minSeq,
+		MaxSeq:               maxSeq,
+		Msgs:                 msgs,
+	}, nil
+}
+
+func (m *msgServer) GetThreadSummaries(ctx context.Context, req *msgext.GetThreadSummariesReq) (*msgext.GetThreadSummariesResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	if _, err := m.Conversation.GetConversation(ctx, req.UserID, req.ConversationID); err != nil {
+		return nil, err
+	}
+	_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, req.UserID, req.ConversationID, req.Seqs)
+	if err != nil {
+		return nil, err
+	}
+	resp := &msgext.GetThreadSummariesResp{Summaries: make([]*msgext.ThreadSummary, 0, len(msgs))}
+	for _, msg := range msgs {
+		if msg == nil || msg.AttachedInfo == "" {
+			continue
+		}
+		var info struct {
+			ThreadSummary *unrelationtb.ThreadModel `json:"threadSummary"`
+		}
+		if err := json.Unmarshal([]byte(msg.AttachedInfo), &info); err != nil || info.ThreadSummary == nil {
+			continue
+		}
+		resp.Summaries = append(resp.Summaries, threadSummary(msg, info.ThreadSummary))
+	}
+	return resp, nil
+}
+
+func (m *msgServer) getThreadRoot(ctx context.Context, userID, conversationID string, rootSeq int64) (*sdkws.MsgData, error) {
+	_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, userID, conversationID, []int64{rootSeq})
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 || msgs[0] == nil {
+		return nil, errs.ErrRecordNotFound.Wrap("thread root msg not found")
+	}
+	if msgs[0].ContentType == constant.MsgRevokeNotification {
+		return nil, errs.ErrMsgAlreadyRevoke.Wrap("thread root msg already revoke")
+	}
+	return msgs[0], nil
+}
+
+func threadSummary(root *sdkws.MsgData, thread *unrelationtb.ThreadModel) *msgext.ThreadSummary {
+	return &msgext.ThreadSummary{
+		RootSeq:              root.Seq,
+		RootClientMsgID:      root.ClientMsgID,
+		ThreadConversationID: thread.ThreadConversationID,
+		ReplyCount:           thread.ReplyCount,
+		LastReplySeq:         thread.LastReplySeq,
+		LastReplySendID:      thread.LastReplySendID,
+		LastReplyClientMsgID: thread.LastReplyClientMsgID,
+		LastReplyTime:        thread.LastReplyTime,
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+
+	"github.com/OpenIMSDK/protocol/sdkws"
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/log"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/translator"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+)
+
+func (m *msgServer) TranslateMsg(ctx context.Context, req *msgext.TranslateMsgReq) (*msgext.TranslateMsgResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	if m.translator == nil {
+		return nil, errs.ErrNoPermission.Wrap("translation is disabled")
+	}
+	if _, err := m.Conversation.GetConversation(ctx, req.UserID, req.ConversationID); err != nil {
+		return nil, err
+	}
+	_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, req.UserID, req.ConversationID, []int64{req.Seq})
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 || msgs[0] == nil {
+		return nil, errs.ErrRecordNotFound.Wrap("msg not found")
+	}
+	text, err := translator.TranslateMsg(ctx, m.translator, m.MsgDatabase, req.ConversationID, msgs[0], req.TargetLang)
+	if err != nil {
+		return nil, err
+	}
+	return &msgext.TranslateMsgResp{Seq: req.Seq, TargetLang: req.TargetLang, Text: text}, nil
+}
+
+// attachTranslations adds the cached translations of msgs to their attached info.
+func (m *msgServer) attachTranslations(ctx context.Context, conversationID string, msgs []*sdkws.MsgData) {
+	if m.translator == nil || len(msgs) == 0 {
+		return
+	}
+	seqs := make([]int64, 0, len(msgs))
+	for _, msg := range msgs {
+		if msg != nil && translator.MsgText(msg) != "" {
+			seqs = append(seqs, msg.Seq)
+		}
+	}
+	if len(seqs) == 0 {
+		return
+	}
+	translations, err := m.MsgDatabase.GetMsgsTranslations(ctx, conversationID, seqs)
+	if err != nil {
+		log.ZWarn(ctx, "GetMsgsTranslations", err, "conversationID", conversationID)
+		return
+	}
+	for _, msg := range msgs {
+		if msg == nil {
+			continue
+		}
+		if t, ok := translations[msg.Seq]; ok {
+			msg.AttachedInfo = translator.AttachTranslations(msg.AttachedInfo, t)
+		}
+	}
+}
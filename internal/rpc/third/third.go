@@ -29,13 +29,16 @@ import (
 
 	"github.com/OpenIMSDK/protocol/third"
 	"github.com/OpenIMSDK/tools/discoveryregistry"
+	"github.com/OpenIMSDK/tools/errs"
 
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/db/cache"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/db/controller"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/db/relation"
 	relationtb "github.com/openimsdk/open-im-server/v3/pkg/common/db/table/relation"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/thirdext"
 )
 
 func Start(client discoveryregistry.SvcDiscoveryRegistry, server *grpc.Server) error {
@@ -58,7 +61,7 @@ func Start(client discoveryregistry.SvcDiscoveryRegistry, server *grpc.Server) e
 	if err != nil {
 		return err
 	}
-	if err := db.AutoMigrate(&relationtb.ObjectModel{}); err != nil {
+	if err := db.AutoMigrate(&relationtb.ObjectModel{}, &relationtb.PushDeviceModel{}); err != nil {
 		return err
 	}
 	// 根据配置文件策略选择 oss 方式
@@ -77,13 +80,16 @@ func Start(client discoveryregistry.SvcDiscoveryRegistry, server *grpc.Server) e
 	if err != nil {
 		return err
 	}
-	third.RegisterThirdServer(server, &thirdServer{
+	s := &thirdServer{
 		apiURL:        apiURL,
 		thirdDatabase: controller.NewThirdDatabase(cache.NewMsgCacheModel(rdb)),
 		userRpcClient: rpcclient.NewUserRpcClient(client),
 		s3dataBase:    controller.NewS3Database(o, relation.NewObjectInfo(db)),
+		pushDevice:    controller.NewPushDeviceDatabase(relation.NewPushDeviceGorm(db)),
 		defaultExpire: time.Hour * 24 * 7,
-	})
+	}
+	third.RegisterThirdServer(server, s)
+	thirdext.RegisterThirdExtServer(server, s)
 	return nil
 }
 
@@ -92,6 +98,7 @@ type thirdServer struct {
 	thirdDatabase controller.ThirdDatabase
 	s3dataBase    controller.S3Database
 	userRpcClient rpcclient.UserRpcClient
+	pushDevice    controller.PushDeviceDatabase
 	defaultExpire time.Duration
 }
 
@@ -103,6 +110,54 @@ func (t *thirdServer) FcmUpdateToken(ctx context.Context, req *third.FcmUpdateTo
 	return &third.FcmUpdateTokenResp{}, nil
 }
 
+func (t *thirdServer) ApnsUpdateToken(ctx context.Context, req *thirdext.ApnsUpdateTokenReq) (*thirdext.ApnsUpdateTokenResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.Account); err != nil {
+		return nil, err
+	}
+	if err := t.thirdDatabase.ApnsUpdateToken(ctx, req.Account, int(req.PlatformID), req.ApnsToken, req.ExpireTime); err != nil {
+		return nil, err
+	}
+	return &thirdext.ApnsUpdateTokenResp{}, nil
+}
+
+func (t *thirdServer) RegisterPushDevice(ctx context.Context, req *thirdext.RegisterPushDeviceReq) (*thirdext.RegisterPushDeviceResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	err := t.pushDevice.RegisterDevice(ctx, &relationtb.PushDeviceModel{
+		Provider:   req.Provider,
+		Token:      req.Token,
+		UserID:     req.UserID,
+		PlatformID: req.PlatformID,
+		AppVersion: req.AppVersion,
+		Locale:     req.Locale,
+		UpdateTime: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &thirdext.RegisterPushDeviceResp{}, nil
+}
+
+func (t *thirdServer) UnregisterPushDevice(ctx context.Context, req *thirdext.UnregisterPushDeviceReq) (*thirdext.UnregisterPushDeviceResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	devices, err := t.pushDevice.FindUserDevices(ctx, []string{req.UserID})
+	if err != nil {
+		return nil, err
+	}
+	for _, device := range devices {
+		if device.Provider == req.Provider && device.Token == req.Token {
+			if err := t.pushDevice.UnregisterDevices(ctx, req.Provider, []string{req.Token}); err != nil {
+				return nil, err
+			}
+			return &thirdext.UnregisterPushDeviceResp{}, nil
+		}
+	}
+	return nil, errs.ErrRecordNotFound.Wrap("push device not found")
+}
+
 func (t *thirdServer) SetAppBadge(ctx context.Context, req *third.SetAppBadgeReq) (resp *third.SetAppBadgeResp, err error) {
 	err = t.thirdDatabase.SetAppBadge(ctx, req.UserID, int(req.AppUnreadCount))
 	if err != nil {
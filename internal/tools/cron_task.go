@@ -48,6 +48,12 @@ func StartTask() error {
 		fmt.Println("start conversationsDestructMsgs cron failed", err.Error(), config.Config.ChatRecordsClearTime)
 		panic(err)
 	}
+	log.ZInfo(context.Background(), "start scheduledMsgDispatch cron task", "cron config", config.Config.ScheduledMsgDispatchTime)
+	_, err = c.AddFunc(config.Config.ScheduledMsgDispatchTime, msgTool.DispatchScheduledMsgs)
+	if err != nil {
+		fmt.Println("start dispatchScheduledMsgs cron failed", err.Error(), config.Config.ScheduledMsgDispatchTime)
+		panic(err)
+	}
 	c.Start()
 	wg.Wait()
 	return nil
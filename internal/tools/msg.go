@@ -47,10 +47,13 @@ type MsgTool struct {
 	userDatabase          controller.UserDatabase
 	groupDatabase         controller.GroupDatabase
 	msgNotificationSender *notification.MsgNotificationSender
+	scheduledMsgDatabase  controller.ScheduledMsgDatabase
+	msgRpcClient          *rpcclient.MessageRpcClient
 }
 
 func NewMsgTool(msgDatabase controller.CommonMsgDatabase, userDatabase controller.UserDatabase,
 	groupDatabase controller.GroupDatabase, conversationDatabase controller.ConversationDatabase, msgNotificationSender *notification.MsgNotificationSender,
+	scheduledMsgDatabase controller.ScheduledMsgDatabase, msgRpcClient *rpcclient.MessageRpcClient,
 ) *MsgTool {
 	return &MsgTool{
 		msgDatabase:           msgDatabase,
@@ -58,6 +61,8 @@ func NewMsgTool(msgDatabase controller.CommonMsgDatabase, userDatabase controlle
 		groupDatabase:         groupDatabase,
 		conversationDatabase:  conversationDatabase,
 		msgNotificationSender: msgNotificationSender,
+		scheduledMsgDatabase:  scheduledMsgDatabase,
+		msgRpcClient:          msgRpcClient,
 	}
 }
 
@@ -98,7 +103,8 @@ func InitMsgTool() (*MsgTool, error) {
 	)
 	msgRpcClient := rpcclient.NewMessageRpcClient(discov)
 	msgNotificationSender := notification.NewMsgNotificationSender(rpcclient.WithRpcClient(&msgRpcClient))
-	msgTool := NewMsgTool(msgDatabase, userDatabase, groupDatabase, conversationDatabase, msgNotificationSender)
+	scheduledMsgDatabase := controller.NewScheduledMsgDatabase(unrelation.NewScheduledMsgMongoDriver(mongo.GetDatabase()), cache.NewScheduledMsgCacheRedis(rdb))
+	msgTool := NewMsgTool(msgDatabase, userDatabase, groupDatabase, conversationDatabase, msgNotificationSender, scheduledMsgDatabase, &msgRpcClient)
 	return msgTool, nil
 }
 
@@ -0,0 +1,85 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	pbmsg "github.com/OpenIMSDK/protocol/msg"
+	"github.com/OpenIMSDK/protocol/sdkws"
+	"github.com/OpenIMSDK/tools/log"
+	"github.com/OpenIMSDK/tools/mcontext"
+	"github.com/OpenIMSDK/tools/utils"
+
+	unrelationtb "github.com/openimsdk/open-im-server/v3/pkg/common/db/table/unrelation"
+)
+
+const (
+	// scheduledMsgSendingTimeout after which a claimed message that was never finished is claimed again.
+	scheduledMsgSendingTimeout = time.Minute * 5
+	// scheduledMsgDispatchBatch the most messages delivered by one run.
+	scheduledMsgDispatchBatch = 1000
+)
+
+func (c *MsgTool) DispatchScheduledMsgs() {
+	ctx := mcontext.NewCtx(utils.GetSelfFuncName())
+	now := time.Now()
+	staleBefore := now.Add(-scheduledMsgSendingTimeout).UnixMilli()
+	var count int
+	for ; count < scheduledMsgDispatchBatch; count++ {
+		scheduled, err := c.scheduledMsgDatabase.ClaimDueScheduledMsg(ctx, now.UnixMilli(), staleBefore)
+		if err != nil {
+			log.ZError(ctx, "claim due scheduled msg failed", err)
+			break
+		}
+		if scheduled == nil {
+			break
+		}
+		c.sendScheduledMsg(scheduled)
+	}
+	if count > 0 {
+		log.ZInfo(ctx, "dispatch scheduled msgs", "count", count, "cost", time.Since(now))
+	}
+}
+
+func (c *MsgTool) sendScheduledMsg(scheduled *unrelationtb.ScheduledMsgModel) {
+	ctx := mcontext.NewCtx(utils.GetSelfFuncName() + "-" + utils.OperationIDGenerator() + "-" + scheduled.ScheduleID)
+	ctx = mcontext.WithOpUserIDContext(ctx, scheduled.SendID)
+	status, ex := int32(unrelationtb.ScheduledMsgStatusSent), ""
+	var msgData sdkws.MsgData
+	if err := proto.Unmarshal(scheduled.MsgData, &msgData); err != nil {
+		log.ZError(ctx, "unmarshal scheduled msg failed", err, "scheduleID", scheduled.ScheduleID)
+		status, ex = unrelationtb.ScheduledMsgStatusFailed, err.Error()
+	} else if first, err := c.scheduledMsgDatabase.StartScheduledMsgDelivery(ctx, scheduled.ClientMsgID); err != nil {
+		// left sending, so it is claimed again once stale
+		log.ZError(ctx, "start scheduled msg delivery failed", err, "scheduleID", scheduled.ScheduleID)
+		return
+	} else if !first {
+		// an earlier claim handed the message to SendMsg but never recorded the result
+		log.ZWarn(ctx, "scheduled msg already delivered", nil, "scheduleID", scheduled.ScheduleID, "clientMsgID", scheduled.ClientMsgID)
+		ex = "delivered by an earlier claim"
+	} else if _, err := c.msgRpcClient.Client.SendMsg(ctx, &pbmsg.SendMsgReq{MsgData: &msgData}); err != nil {
+		log.ZError(ctx, "send scheduled msg failed", err, "scheduleID", scheduled.ScheduleID, "clientMsgID", scheduled.ClientMsgID)
+		status, ex = unrelationtb.ScheduledMsgStatusFailed, err.Error()
+	}
+	ok, err := c.scheduledMsgDatabase.SetScheduledMsgStatus(ctx, scheduled, status, ex)
+	if err != nil {
+		log.ZError(ctx, "set scheduled msg status failed", err, "scheduleID", scheduled.ScheduleID, "status", status)
+	} else if !ok {
+		log.ZWarn(ctx, "scheduled msg was claimed again before its status was set", nil, "scheduleID", scheduled.ScheduleID, "status", status)
+	}
+}
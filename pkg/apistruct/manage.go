@@ -34,6 +34,8 @@ type SendMsg struct {
 
 type SendMsgReq struct {
 	RecvID string `json:"recvID" binding:"required_if" message:"recvID is required if sessionType is SingleChatType or NotificationChatType"`
+	// SendTime delivers the message later at this time (milliseconds), zero sends it now.
+	SendTime int64 `json:"sendTime"`
 	SendMsg
 }
 
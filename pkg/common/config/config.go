@@ -100,11 +100,15 @@ type configStruct struct {
 		MsgToPush struct {
 			Topic string `yaml:"topic"`
 		} `yaml:"msgToPush"`
+		MsgToModify struct {
+			Topic string `yaml:"topic"`
+		} `yaml:"msgToModify"`
 		ConsumerGroupID struct {
-			MsgToRedis string `yaml:"msgToRedis"`
-			MsgToMongo string `yaml:"msgToMongo"`
-			MsgToMySql string `yaml:"msgToMySql"`
-			MsgToPush  string `yaml:"msgToPush"`
+			MsgToRedis  string `yaml:"msgToRedis"`
+			MsgToMongo  string `yaml:"msgToMongo"`
+			MsgToMySql  string `yaml:"msgToMySql"`
+			MsgToPush   string `yaml:"msgToPush"`
+			MsgToModify string `yaml:"msgToModify"`
 		} `yaml:"consumerGroupID"`
 	} `yaml:"kafka"`
 
@@ -185,6 +189,30 @@ type configStruct struct {
 		WebsocketMaxConnNum int   `yaml:"websocketMaxConnNum"`
 		WebsocketMaxMsgLen  int   `yaml:"websocketMaxMsgLen"`
 		WebsocketTimeout    int   `yaml:"websocketTimeout"`
+		CompressThreshold   int   `yaml:"compressThreshold"`
+		PerMessageDeflate   bool  `yaml:"perMessageDeflate"`
+		ResumeGracePeriod   int   `yaml:"resumeGracePeriod"`
+		ResumeBufferSize    int   `yaml:"resumeBufferSize"`
+		PingInterval        int   `yaml:"pingInterval"`
+		DrainBatchSize      int   `yaml:"drainBatchSize"`
+		DrainBatchInterval  int   `yaml:"drainBatchInterval"`
+		DrainTimeout        int   `yaml:"drainTimeout"`
+		UserRouteTTL        int   `yaml:"userRouteTTL"`
+		RateLimit           struct {
+			Enable        bool `yaml:"enable"`
+			MaxViolations int  `yaml:"maxViolations"`
+			Rules         []struct {
+				ReqIdentifier int32   `yaml:"reqIdentifier"`
+				ConnRate      float64 `yaml:"connRate"`
+				ConnBurst     int     `yaml:"connBurst"`
+				UserRate      float64 `yaml:"userRate"`
+				UserBurst     int     `yaml:"userBurst"`
+			} `yaml:"rules"`
+		} `yaml:"rateLimit"`
+		Ephemeral struct {
+			Rate  float64 `yaml:"rate"`
+			Burst int     `yaml:"burst"`
+		} `yaml:"ephemeral"`
 	} `yaml:"longConnSvr"`
 
 	Push struct {
@@ -206,7 +234,59 @@ type configStruct struct {
 			PushUrl      string `yaml:"pushUrl"`
 			PushIntent   string `yaml:"pushIntent"`
 		} `yaml:"jpns"`
+		Routing struct {
+			Providers []string `yaml:"providers"`
+			Fallback  string   `yaml:"fallback"`
+		} `yaml:"routing"`
+		Webhook struct {
+			Url           string `yaml:"url"`
+			Secret        string `yaml:"secret"`
+			Timeout       int    `yaml:"timeout"`
+			BatchSize     int    `yaml:"batchSize"`
+			RetryTimes    int    `yaml:"retryTimes"`
+			RetryInterval int    `yaml:"retryInterval"`
+		} `yaml:"webhook"`
+		Apns struct {
+			KeyFile  string `yaml:"keyFile"`
+			KeyID    string `yaml:"keyID"`
+			TeamID   string `yaml:"teamID"`
+			BundleID string `yaml:"bundleID"`
+		} `yaml:"apns"`
+		Retry struct {
+			Enable           bool `yaml:"enable"`
+			MaxAttempts      int  `yaml:"maxAttempts"`
+			Backoff          int  `yaml:"backoff"`
+			MaxBackoff       int  `yaml:"maxBackoff"`
+			DeadLetterMax    int  `yaml:"deadLetterMax"`
+			DeadLetterExpire int  `yaml:"deadLetterExpire"`
+		} `yaml:"retry"`
+		Template struct {
+			Enable         bool   `yaml:"enable"`
+			DefaultLocale  string `yaml:"defaultLocale"`
+			ReloadInterval int    `yaml:"reloadInterval"`
+		} `yaml:"template"`
 	}
+	SearchIndex struct {
+		Enable bool   `yaml:"enable"`
+		Type   string `yaml:"type"`
+	} `yaml:"searchIndex"`
+	Translation struct {
+		Enable        bool     `yaml:"enable"`
+		Type          string   `yaml:"type"`
+		AutoLanguages []string `yaml:"autoLanguages"`
+		CacheExpire   int      `yaml:"cacheExpire"`
+		Webhook       struct {
+			URL     string `yaml:"url"`
+			Timeout int    `yaml:"timeout"`
+		} `yaml:"webhook"`
+	} `yaml:"translation"`
+	SensitiveWord struct {
+		Enable         bool              `yaml:"enable"`
+		ReloadInterval int               `yaml:"reloadInterval"`
+		DefaultAction  string            `yaml:"defaultAction"`
+		Categories     map[string]string `yaml:"categories"`
+		Words          []string          `yaml:"words"`
+	} `yaml:"sensitiveWord"`
 	Manager struct {
 		UserID   []string `yaml:"userID"`
 		Nickname []string `yaml:"nickname"`
@@ -220,6 +300,7 @@ type configStruct struct {
 	RetainChatRecords                 int    `yaml:"retainChatRecords"`
 	ChatRecordsClearTime              string `yaml:"chatRecordsClearTime"`
 	MsgDestructTime                   string `yaml:"msgDestructTime"`
+	ScheduledMsgDispatchTime          string `yaml:"scheduledMsgDispatchTime"`
 	Secret                            string `yaml:"secret"`
 	TokenPolicy                       struct {
 		Expire int64 `yaml:"expire"`
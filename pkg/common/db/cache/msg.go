@@ -16,6 +16,7 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"strconv"
 	"time"
 
@@ -50,6 +51,7 @@ const (
 	signalCache      = "SIGNAL_CACHE:"
 	signalListCache  = "SIGNAL_LIST_CACHE:"
 	fcmToken         = "FCM_TOKEN:"
+	apnsToken        = "APNS_TOKEN:"
 
 	messageCache            = "MESSAGE_CACHE:"
 	messageDelUserList      = "MESSAGE_DEL_USER_LIST:"
@@ -58,10 +60,15 @@ const (
 	userBadgeUnreadCountSum = "USER_BADGE_UNREAD_COUNT_SUM:"
 	exTypeKeyLocker         = "EX_LOCK:"
 	uidPidToken             = "UID_PID_TOKEN_STATUS:"
+	groupReadReceiptSeqs    = "GROUP_READ_RECEIPT_SEQS:"
+	groupReadReceiptFlush   = "GROUP_READ_RECEIPT_FLUSH:"
+	msgTranslation          = "MSG_TRANSLATION:"
 )
 
 type SeqCache interface {
 	SetMaxSeq(ctx context.Context, conversationID string, maxSeq int64) error
+	// IncrMaxSeq atomically reserves size seqs and returns the new max seq
+	IncrMaxSeq(ctx context.Context, conversationID string, size int64) (int64, error)
 	GetMaxSeqs(ctx context.Context, conversationIDs []string) (map[string]int64, error)
 	GetMaxSeq(ctx context.Context, conversationID string) (int64, error)
 	SetMinSeq(ctx context.Context, conversationID string, minSeq int64) error
@@ -89,6 +96,9 @@ type thirdCache interface {
 	SetFcmToken(ctx context.Context, account string, platformID int, fcmToken string, expireTime int64) (err error)
 	GetFcmToken(ctx context.Context, account string, platformID int) (string, error)
 	DelFcmToken(ctx context.Context, account string, platformID int) error
+	SetApnsToken(ctx context.Context, account string, platformID int, apnsToken string, expireTime int64) error
+	GetApnsToken(ctx context.Context, account string, platformID int) (string, error)
+	DelApnsToken(ctx context.Context, account string, platformID int) error
 	IncrUserBadgeUnreadCountSum(ctx context.Context, userID string) (int, error)
 	SetUserBadgeUnreadCountSum(ctx context.Context, userID string, value int) error
 	GetUserBadgeUnreadCountSum(ctx context.Context, userID string) (int, error)
@@ -130,8 +140,22 @@ type MsgModel interface {
 	) (bool, error)
 	GetMessageTypeKeyValue(ctx context.Context, clientMsgID string, sessionType int32, typeKey string) (string, error)
 	SetMessageTypeKeyValue(ctx context.Context, clientMsgID string, sessionType int32, typeKey, value string) error
+	// ModifyMessageReaction atomically adds userID to or removes it from the users of typeKey and
+	// returns them, changed is false when userID already was or was not among them
+	ModifyMessageReaction(ctx context.Context, clientMsgID string, sessionType int32, typeKey, userID string, isAdd bool, expiration time.Duration) (userIDs []string, changed bool, err error)
 	LockMessageTypeKey(ctx context.Context, clientMsgID string, TypeKey string) error
 	UnLockMessageTypeKey(ctx context.Context, clientMsgID string, TypeKey string) error
+	// AddGroupReadReceiptSeqs records seqs of sendID's messages whose read count changed,
+	// returns true when the caller should flush them after delay
+	AddGroupReadReceiptSeqs(ctx context.Context, conversationID, sendID string, seqs []int64, delay time.Duration) (bool, error)
+	// PopGroupReadReceiptSeqs takes all recorded seqs of sendID's messages
+	PopGroupReadReceiptSeqs(ctx context.Context, conversationID, sendID string) ([]int64, error)
+
+	GetMsgTranslation(ctx context.Context, conversationID string, seq int64, lang string) (string, error)
+	SetMsgTranslation(ctx context.Context, conversationID string, seq int64, lang string, text string, expire time.Duration) error
+	// GetMsgsTranslations returns seq -> lang -> translated text, seqs without translation are omitted
+	GetMsgsTranslations(ctx context.Context, conversationID string, seqs []int64) (map[int64]map[string]string, error)
+	DelMsgTranslation(ctx context.Context, conversationID string, seq int64) error
 }
 
 func NewMsgCacheModel(client redis.UniversalClient) MsgModel {
@@ -208,6 +232,10 @@ func (c *msgCache) SetMaxSeq(ctx context.Context, conversationID string, maxSeq
 	return c.setSeq(ctx, conversationID, maxSeq, c.getMaxSeqKey)
 }
 
+func (c *msgCache) IncrMaxSeq(ctx context.Context, conversationID string, size int64) (int64, error) {
+	return utils.Wrap2(c.rdb.IncrBy(ctx, c.getMaxSeqKey(conversationID), size).Result())
+}
+
 func (c *msgCache) GetMaxSeqs(ctx context.Context, conversationIDs []string) (m map[string]int64, err error) {
 	return c.getSeqs(ctx, conversationIDs, c.getMaxSeqKey)
 }
@@ -595,6 +623,26 @@ func (c *msgCache) DelFcmToken(ctx context.Context, account string, platformID i
 	return errs.Wrap(c.rdb.Del(ctx, fcmToken+account+":"+strconv.Itoa(platformID)).Err())
 }
 
+func (c *msgCache) SetApnsToken(
+	ctx context.Context,
+	account string,
+	platformID int,
+	token string,
+	expireTime int64,
+) error {
+	return errs.Wrap(
+		c.rdb.Set(ctx, apnsToken+account+":"+strconv.Itoa(platformID), token, time.Duration(expireTime)*time.Second).Err(),
+	)
+}
+
+func (c *msgCache) GetApnsToken(ctx context.Context, account string, platformID int) (string, error) {
+	return utils.Wrap2(c.rdb.Get(ctx, apnsToken+account+":"+strconv.Itoa(platformID)).Result())
+}
+
+func (c *msgCache) DelApnsToken(ctx context.Context, account string, platformID int) error {
+	return errs.Wrap(c.rdb.Del(ctx, apnsToken+account+":"+strconv.Itoa(platformID)).Err())
+}
+
 func (c *msgCache) IncrUserBadgeUnreadCountSum(ctx context.Context, userID string) (int, error) {
 	seq, err := c.rdb.Incr(ctx, userBadgeUnreadCountSum+userID).Result()
 	return int(seq), errs.Wrap(err)
@@ -618,6 +666,96 @@ func (c *msgCache) UnLockMessageTypeKey(ctx context.Context, clientMsgID string,
 	return errs.Wrap(c.rdb.Del(ctx, key).Err())
 }
 
+func (c *msgCache) getGroupReadReceiptSeqsKey(conversationID, sendID string) string {
+	return groupReadReceiptSeqs + conversationID + ":" + sendID
+}
+
+func (c *msgCache) AddGroupReadReceiptSeqs(
+	ctx context.Context,
+	conversationID, sendID string,
+	seqs []int64,
+	delay time.Duration,
+) (bool, error) {
+	key := c.getGroupReadReceiptSeqsKey(conversationID, sendID)
+	members := make([]any, 0, len(seqs))
+	for _, seq := range seqs {
+		members = append(members, seq)
+	}
+	pipe := c.rdb.Pipeline()
+	pipe.SAdd(ctx, key, members...)
+	pipe.Expire(ctx, key, time.Hour*24)
+	flush := pipe.SetNX(ctx, groupReadReceiptFlush+conversationID+":"+sendID, 1, delay)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, errs.Wrap(err)
+	}
+	return flush.Val(), nil
+}
+
+func (c *msgCache) PopGroupReadReceiptSeqs(ctx context.Context, conversationID, sendID string) ([]int64, error) {
+	key := c.getGroupReadReceiptSeqsKey(conversationID, sendID)
+	pipe := c.rdb.TxPipeline()
+	members := pipe.SMembers(ctx, key)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	seqs := make([]int64, 0, len(members.Val()))
+	for _, member := range members.Val() {
+		seq, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	return seqs, nil
+}
+
+func (c *msgCache) getMsgTranslationKey(conversationID string, seq int64) string {
+	return msgTranslation + conversationID + ":" + strconv.FormatInt(seq, 10)
+}
+
+func (c *msgCache) GetMsgTranslation(ctx context.Context, conversationID string, seq int64, lang string) (string, error) {
+	return utils.Wrap2(c.rdb.HGet(ctx, c.getMsgTranslationKey(conversationID, seq), lang).Result())
+}
+
+func (c *msgCache) SetMsgTranslation(
+	ctx context.Context,
+	conversationID string,
+	seq int64,
+	lang string,
+	text string,
+	expire time.Duration,
+) error {
+	key := c.getMsgTranslationKey(conversationID, seq)
+	pipe := c.rdb.Pipeline()
+	pipe.HSet(ctx, key, lang, text)
+	pipe.Expire(ctx, key, expire)
+	_, err := pipe.Exec(ctx)
+	return errs.Wrap(err)
+}
+
+func (c *msgCache) GetMsgsTranslations(ctx context.Context, conversationID string, seqs []int64) (map[int64]map[string]string, error) {
+	pipe := c.rdb.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, 0, len(seqs))
+	for _, seq := range seqs {
+		cmds = append(cmds, pipe.HGetAll(ctx, c.getMsgTranslationKey(conversationID, seq)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, errs.Wrap(err)
+	}
+	translations := make(map[int64]map[string]string)
+	for i, cmd := range cmds {
+		if len(cmd.Val()) > 0 {
+			translations[seqs[i]] = cmd.Val()
+		}
+	}
+	return translations, nil
+}
+
+func (c *msgCache) DelMsgTranslation(ctx context.Context, conversationID string, seq int64) error {
+	return errs.Wrap(c.rdb.Del(ctx, c.getMsgTranslationKey(conversationID, seq)).Err())
+}
+
 func (c *msgCache) getMessageReactionExPrefix(clientMsgID string, sessionType int32) string {
 	switch sessionType {
 	case constant.SingleChatType:
@@ -667,6 +805,71 @@ func (c *msgCache) GetMessageTypeKeyValue(
 	return utils.Wrap2(c.rdb.HGet(ctx, c.getMessageReactionExPrefix(clientMsgID, sessionType), typeKey).Result())
 }
 
+// modifyMessageReactionScript adds ARGV[2] to or removes it from the json user list in field ARGV[1],
+// it returns whether the list changed and the list.
+var modifyMessageReactionScript = redis.NewScript(`
+local value = redis.call('HGET', KEYS[1], ARGV[1])
+local userIDs = {}
+if value and value ~= 'null' then
+	userIDs = cjson.decode(value)
+end
+local index = 0
+for i, userID in ipairs(userIDs) do
+	if userID == ARGV[2] then
+		index = i
+		break
+	end
+end
+local changed = 0
+if ARGV[3] == '1' and index == 0 then
+	table.insert(userIDs, ARGV[2])
+	changed = 1
+elseif ARGV[3] ~= '1' and index > 0 then
+	table.remove(userIDs, index)
+	changed = 1
+end
+if #userIDs == 0 then
+	value = '[]'
+else
+	value = cjson.encode(userIDs)
+end
+if changed == 1 then
+	if #userIDs == 0 then
+		redis.call('HDEL', KEYS[1], ARGV[1])
+	else
+		redis.call('HSET', KEYS[1], ARGV[1], value)
+	end
+	redis.call('EXPIRE', KEYS[1], ARGV[4])
+end
+return {changed, value}
+`)
+
+func (c *msgCache) ModifyMessageReaction(
+	ctx context.Context,
+	clientMsgID string,
+	sessionType int32,
+	typeKey, userID string,
+	isAdd bool,
+	expiration time.Duration,
+) ([]string, bool, error) {
+	add := "0"
+	if isAdd {
+		add = "1"
+	}
+	res, err := modifyMessageReactionScript.Run(ctx, c.rdb, []string{c.getMessageReactionExPrefix(clientMsgID, sessionType)},
+		typeKey, userID, add, int64(expiration/time.Second)).Slice()
+	if err != nil {
+		return nil, false, errs.Wrap(err)
+	}
+	changed, _ := res[0].(int64)
+	value, _ := res[1].(string)
+	var userIDs []string
+	if err := json.Unmarshal([]byte(value), &userIDs); err != nil {
+		return nil, false, errs.Wrap(err)
+	}
+	return userIDs, changed == 1, nil
+}
+
 func (c *msgCache) GetOneMessageAllReactionList(
 	ctx context.Context,
 	clientMsgID string,
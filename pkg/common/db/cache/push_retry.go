@@ -0,0 +1,220 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/redis/go-redis/v9"
+)
+
+// the keys share a hash tag so that the scripts work on a redis cluster.
+const (
+	pushRetryQueueKey      = "{PUSH_RETRY}:QUEUE"
+	pushRetryTaskKey       = "{PUSH_RETRY}:TASK"
+	pushDeadLetterKey      = "{PUSH_RETRY}:DEAD"
+	pushDeadLetterIndexKey = "{PUSH_RETRY}:DEAD_INDEX"
+)
+
+// claimPushRetryScript takes the due tasks and pushes their due time lease into the future.
+var claimPushRetryScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[3]))
+local tasks = {}
+for _, id in ipairs(ids) do
+	local task = redis.call('HGET', KEYS[2], id)
+	if task then
+		redis.call('ZADD', KEYS[1], ARGV[2], id)
+		table.insert(tasks, task)
+	else
+		redis.call('ZREM', KEYS[1], id)
+	end
+end
+return tasks
+`)
+
+// addPushDeadLetterScript moves a task to the dead letters and drops the ones failed
+// before ARGV[4] and the oldest beyond ARGV[5], 0 disables either limit.
+var addPushDeadLetterScript = redis.NewScript(`
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('HSET', KEYS[3], ARGV[1], ARGV[2])
+redis.call('ZADD', KEYS[4], ARGV[3], ARGV[1])
+local drop = {}
+if tonumber(ARGV[4]) > 0 then
+	drop = redis.call('ZRANGEBYSCORE', KEYS[4], '-inf', '(' .. ARGV[4])
+end
+local max = tonumber(ARGV[5])
+local n = redis.call('ZCARD', KEYS[4]) - #drop
+if max > 0 and n > max then
+	for _, id in ipairs(redis.call('ZRANGE', KEYS[4], #drop, #drop + n - max - 1)) do
+		table.insert(drop, id)
+	end
+end
+for _, id in ipairs(drop) do
+	redis.call('HDEL', KEYS[3], id)
+	redis.call('ZREM', KEYS[4], id)
+end
+return #drop
+`)
+
+// replayPushDeadLetterScript moves a dead letter back to the queue as ARGV[2] due at ARGV[3],
+// it returns 0 if the dead letter is gone, so a replay happens once.
+var replayPushDeadLetterScript = redis.NewScript(`
+if redis.call('HDEL', KEYS[3], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('ZREM', KEYS[4], ARGV[1])
+redis.call('HSET', KEYS[2], ARGV[1], ARGV[2])
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[1])
+return 1
+`)
+
+// PushRetryCache is a delayed queue of failed offline pushes and the dead letters
+// of the pushes that kept failing. Tasks are opaque encoded strings.
+type PushRetryCache interface {
+	// SchedulePushRetry makes task due at dueAt, replacing a task with the same id.
+	SchedulePushRetry(ctx context.Context, taskID string, task string, dueAt time.Time) error
+	// ClaimPushRetries returns up to count due tasks and hides them for lease, a task
+	// neither rescheduled nor removed within lease is due again.
+	ClaimPushRetries(ctx context.Context, now time.Time, lease time.Duration, count int) ([]string, error)
+	RemovePushRetry(ctx context.Context, taskID string) error
+	// AddPushDeadLetter moves a task from the queue to the dead letters. The dead letters
+	// failed longer than expire ago and the oldest beyond maxCount are dropped, 0 keeps them.
+	AddPushDeadLetter(ctx context.Context, taskID string, task string, failTime time.Time, maxCount int, expire time.Duration) error
+	// GetPushDeadLetters pages through the dead letters, latest first.
+	GetPushDeadLetters(ctx context.Context, offset, count int) (tasks []string, total int64, err error)
+	// FindPushDeadLetters returns the dead letters of taskIDs that exist.
+	FindPushDeadLetters(ctx context.Context, taskIDs []string) ([]string, error)
+	// ReplayPushDeadLetter moves a dead letter back to the queue as task due at dueAt in one step,
+	// it returns false if there is no dead letter of taskID anymore.
+	ReplayPushDeadLetter(ctx context.Context, taskID string, task string, dueAt time.Time) (bool, error)
+	DelPushDeadLetters(ctx context.Context, taskIDs []string) error
+}
+
+func NewPushRetryCacheRedis(rdb redis.UniversalClient) PushRetryCache {
+	return &PushRetryCacheRedis{rdb: rdb}
+}
+
+type PushRetryCacheRedis struct {
+	rdb redis.UniversalClient
+}
+
+func (p *PushRetryCacheRedis) SchedulePushRetry(ctx context.Context, taskID string, task string, dueAt time.Time) error {
+	pipe := p.rdb.TxPipeline()
+	pipe.HSet(ctx, pushRetryTaskKey, taskID, task)
+	pipe.ZAdd(ctx, pushRetryQueueKey, redis.Z{Score: float64(dueAt.UnixMilli()), Member: taskID})
+	_, err := pipe.Exec(ctx)
+	return errs.Wrap(err)
+}
+
+func (p *PushRetryCacheRedis) ClaimPushRetries(ctx context.Context, now time.Time, lease time.Duration, count int) ([]string, error) {
+	tasks, err := claimPushRetryScript.Run(ctx, p.rdb, []string{pushRetryQueueKey, pushRetryTaskKey},
+		now.UnixMilli(), now.Add(lease).UnixMilli(), count).StringSlice()
+	if err != nil && err != redis.Nil {
+		return nil, errs.Wrap(err)
+	}
+	return tasks, nil
+}
+
+func (p *PushRetryCacheRedis) RemovePushRetry(ctx context.Context, taskID string) error {
+	pipe := p.rdb.TxPipeline()
+	pipe.ZRem(ctx, pushRetryQueueKey, taskID)
+	pipe.HDel(ctx, pushRetryTaskKey, taskID)
+	_, err := pipe.Exec(ctx)
+	return errs.Wrap(err)
+}
+
+func (p *PushRetryCacheRedis) AddPushDeadLetter(ctx context.Context, taskID string, task string, failTime time.Time,
+	maxCount int, expire time.Duration,
+) error {
+	var expireBefore int64
+	if expire > 0 {
+		expireBefore = time.Now().Add(-expire).UnixMilli()
+	}
+	keys := []string{pushRetryQueueKey, pushRetryTaskKey, pushDeadLetterKey, pushDeadLetterIndexKey}
+	err := addPushDeadLetterScript.Run(ctx, p.rdb, keys, taskID, task, failTime.UnixMilli(), expireBefore, maxCount).Err()
+	if err != nil && err != redis.Nil {
+		return errs.Wrap(err)
+	}
+	return nil
+}
+
+func (p *PushRetryCacheRedis) GetPushDeadLetters(ctx context.Context, offset, count int) ([]string, int64, error) {
+	total, err := p.rdb.ZCard(ctx, pushDeadLetterIndexKey).Result()
+	if err != nil {
+		return nil, 0, errs.Wrap(err)
+	}
+	ids, err := p.rdb.ZRevRange(ctx, pushDeadLetterIndexKey, int64(offset), int64(offset+count-1)).Result()
+	if err != nil {
+		return nil, 0, errs.Wrap(err)
+	}
+	if len(ids) == 0 {
+		return nil, total, nil
+	}
+	values, err := p.rdb.HMGet(ctx, pushDeadLetterKey, ids...).Result()
+	if err != nil {
+		return nil, 0, errs.Wrap(err)
+	}
+	tasks := make([]string, 0, len(values))
+	for _, v := range values {
+		if task, ok := v.(string); ok {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, total, nil
+}
+
+func (p *PushRetryCacheRedis) FindPushDeadLetters(ctx context.Context, taskIDs []string) ([]string, error) {
+	if len(taskIDs) == 0 {
+		return nil, nil
+	}
+	values, err := p.rdb.HMGet(ctx, pushDeadLetterKey, taskIDs...).Result()
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	tasks := make([]string, 0, len(values))
+	for _, v := range values {
+		if task, ok := v.(string); ok {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (p *PushRetryCacheRedis) ReplayPushDeadLetter(ctx context.Context, taskID string, task string, dueAt time.Time) (bool, error) {
+	keys := []string{pushRetryQueueKey, pushRetryTaskKey, pushDeadLetterKey, pushDeadLetterIndexKey}
+	n, err := replayPushDeadLetterScript.Run(ctx, p.rdb, keys, taskID, task, dueAt.UnixMilli()).Int()
+	if err != nil {
+		return false, errs.Wrap(err)
+	}
+	return n == 1, nil
+}
+
+func (p *PushRetryCacheRedis) DelPushDeadLetters(ctx context.Context, taskIDs []string) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	pipe := p.rdb.TxPipeline()
+	pipe.HDel(ctx, pushDeadLetterKey, taskIDs...)
+	members := make([]interface{}, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		members = append(members, taskID)
+	}
+	pipe.ZRem(ctx, pushDeadLetterIndexKey, members...)
+	_, err := pipe.Exec(ctx)
+	return errs.Wrap(err)
+}
@@ -0,0 +1,73 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/redis/go-redis/v9"
+)
+
+const rateLimitKey = "RATE_LIMIT:"
+
+// tokenBucketScript refills the bucket for the time since its last use and takes one token.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return allowed
+`)
+
+// RateLimitCache is a token bucket shared by every node.
+type RateLimitCache interface {
+	// AllowRate takes a token from the bucket of key, which holds up to burst
+	// tokens and refills rate tokens per second.
+	AllowRate(ctx context.Context, key string, rate float64, burst int) (bool, error)
+}
+
+func NewRateLimitCacheRedis(rdb redis.UniversalClient) RateLimitCache {
+	return &RateLimitCacheRedis{rdb: rdb}
+}
+
+type RateLimitCacheRedis struct {
+	rdb redis.UniversalClient
+}
+
+func (r *RateLimitCacheRedis) AllowRate(ctx context.Context, key string, rate float64, burst int) (bool, error) {
+	allowed, err := tokenBucketScript.Run(ctx, r.rdb, []string{rateLimitKey + key},
+		strconv.FormatFloat(rate, 'f', -1, 64), burst, time.Now().UnixMilli()).Int()
+	if err != nil {
+		return false, errs.Wrap(err)
+	}
+	return allowed == 1, nil
+}
@@ -0,0 +1,49 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/redis/go-redis/v9"
+)
+
+const scheduledMsgDeliveryKey = "SCHEDULED_MSG_DELIVERY:"
+
+// ScheduledMsgCache remembers which scheduled messages were handed to SendMsg,
+// so a message claimed again after a slow or lost dispatch is not sent twice.
+type ScheduledMsgCache interface {
+	// StartScheduledMsgDelivery marks clientMsgID as delivered for expiration,
+	// returns false if it had already been marked.
+	StartScheduledMsgDelivery(ctx context.Context, clientMsgID string, expiration time.Duration) (bool, error)
+}
+
+func NewScheduledMsgCacheRedis(rdb redis.UniversalClient) ScheduledMsgCache {
+	return &ScheduledMsgCacheRedis{rdb: rdb}
+}
+
+type ScheduledMsgCacheRedis struct {
+	rdb redis.UniversalClient
+}
+
+func (s *ScheduledMsgCacheRedis) StartScheduledMsgDelivery(ctx context.Context, clientMsgID string, expiration time.Duration) (bool, error) {
+	ok, err := s.rdb.SetNX(ctx, scheduledMsgDeliveryKey+clientMsgID, time.Now().UnixMilli(), expiration).Result()
+	if err != nil {
+		return false, errs.Wrap(err)
+	}
+	return ok, nil
+}
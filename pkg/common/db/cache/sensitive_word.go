@@ -0,0 +1,50 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/redis/go-redis/v9"
+)
+
+const sensitiveWordVersionKey = "SENSITIVE_WORD_VERSION"
+
+// SensitiveWordCache tells msg rpc instances when the dictionary must be reloaded.
+type SensitiveWordCache interface {
+	GetSensitiveWordVersion(ctx context.Context) (int64, error)
+	IncrSensitiveWordVersion(ctx context.Context) error
+}
+
+func NewSensitiveWordCacheRedis(rdb redis.UniversalClient) SensitiveWordCache {
+	return &SensitiveWordCacheRedis{rdb: rdb}
+}
+
+type SensitiveWordCacheRedis struct {
+	rdb redis.UniversalClient
+}
+
+func (s *SensitiveWordCacheRedis) GetSensitiveWordVersion(ctx context.Context) (int64, error) {
+	version, err := s.rdb.Get(ctx, sensitiveWordVersionKey).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return version, errs.Wrap(err)
+}
+
+func (s *SensitiveWordCacheRedis) IncrSensitiveWordVersion(ctx context.Context) error {
+	return errs.Wrap(s.rdb.Incr(ctx, sensitiveWordVersionKey).Err())
+}
@@ -0,0 +1,126 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	userRouteKey     = "USER_ROUTE:"
+	userRouteNodeKey = "USER_ROUTE_NODE:"
+	// userRouteBatch commands per pipeline when a node refreshes all its users
+	userRouteBatch = 1000
+)
+
+// ErrUserRouteStale is returned when a node has not refreshed its routes in time,
+// the callers then have to ask every node.
+var ErrUserRouteStale = errors.New("user route table is stale")
+
+// UserRouteCache maps online users to the msg gateway nodes holding their conns.
+// A user hash holds one field per node whose value is the unix milli the route
+// expires at, so the route of a node that died without cleaning up goes away by itself.
+type UserRouteCache interface {
+	// SetUserNode routes userIDs to node until ttl elapses.
+	SetUserNode(ctx context.Context, node string, userIDs []string, ttl time.Duration) error
+	// DelUserNode removes the route of userID to node.
+	DelUserNode(ctx context.Context, node string, userID string) error
+	// RefreshNode routes userIDs to node and marks the routes of node fresh for ttl.
+	RefreshNode(ctx context.Context, node string, userIDs []string, ttl time.Duration) error
+	// GetNodeUsers groups userIDs by the nodes among nodes they are routed to, users
+	// without a route are left out. It returns ErrUserRouteStale if a node of nodes
+	// has not refreshed its routes.
+	GetNodeUsers(ctx context.Context, nodes []string, userIDs []string) (map[string][]string, error)
+}
+
+func NewUserRouteCacheRedis(rdb redis.UniversalClient) UserRouteCache {
+	return &UserRouteCacheRedis{rdb: rdb}
+}
+
+type UserRouteCacheRedis struct {
+	rdb redis.UniversalClient
+}
+
+func (u *UserRouteCacheRedis) SetUserNode(ctx context.Context, node string, userIDs []string, ttl time.Duration) error {
+	expireAt := strconv.FormatInt(time.Now().Add(ttl).UnixMilli(), 10)
+	for i := 0; i < len(userIDs); i += userRouteBatch {
+		end := i + userRouteBatch
+		if end > len(userIDs) {
+			end = len(userIDs)
+		}
+		pipe := u.rdb.Pipeline()
+		for _, userID := range userIDs[i:end] {
+			pipe.HSet(ctx, userRouteKey+userID, node, expireAt)
+			pipe.PExpire(ctx, userRouteKey+userID, ttl)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return errs.Wrap(err)
+		}
+	}
+	return nil
+}
+
+func (u *UserRouteCacheRedis) DelUserNode(ctx context.Context, node string, userID string) error {
+	return errs.Wrap(u.rdb.HDel(ctx, userRouteKey+userID, node).Err())
+}
+
+func (u *UserRouteCacheRedis) RefreshNode(ctx context.Context, node string, userIDs []string, ttl time.Duration) error {
+	if err := u.SetUserNode(ctx, node, userIDs, ttl); err != nil {
+		return err
+	}
+	return errs.Wrap(u.rdb.Set(ctx, userRouteNodeKey+node, time.Now().UnixMilli(), ttl).Err())
+}
+
+func (u *UserRouteCacheRedis) GetNodeUsers(ctx context.Context, nodes []string, userIDs []string) (map[string][]string, error) {
+	pipe := u.rdb.Pipeline()
+	alive := make([]*redis.IntCmd, 0, len(nodes))
+	for _, node := range nodes {
+		alive = append(alive, pipe.Exists(ctx, userRouteNodeKey+node))
+	}
+	routes := make([]*redis.MapStringStringCmd, 0, len(userIDs))
+	for _, userID := range userIDs {
+		routes = append(routes, pipe.HGetAll(ctx, userRouteKey+userID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	known := make(map[string]struct{}, len(nodes))
+	for i, node := range nodes {
+		if alive[i].Val() == 0 {
+			return nil, errs.Wrap(ErrUserRouteStale, node)
+		}
+		known[node] = struct{}{}
+	}
+	now := time.Now().UnixMilli()
+	nodeUsers := make(map[string][]string)
+	for i, userID := range userIDs {
+		for node, expireAt := range routes[i].Val() {
+			if _, ok := known[node]; !ok {
+				continue
+			}
+			if t, _ := strconv.ParseInt(expireAt, 10, 64); t < now {
+				continue
+			}
+			nodeUsers[node] = append(nodeUsers[node], userID)
+		}
+	}
+	return nodeUsers, nil
+}
@@ -16,6 +16,7 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -34,6 +35,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/mongo"
 
+	"github.com/OpenIMSDK/protocol/constant"
 	pbmsg "github.com/OpenIMSDK/protocol/msg"
 	"github.com/OpenIMSDK/protocol/sdkws"
 	"github.com/OpenIMSDK/tools/utils"
@@ -44,13 +46,37 @@ const (
 	updateKeyRevoke
 )
 
+const (
+	// threadRootPersistRetry how many times a thread update waits for its root msg to be persisted.
+	threadRootPersistRetry    = 10
+	threadRootPersistInterval = time.Second
+)
+
 type CommonMsgDatabase interface {
 	// 批量插入消息
 	BatchInsertChat2DB(ctx context.Context, conversationID string, msgs []*sdkws.MsgData, currentMaxSeq int64) error
 	// 撤回消息
 	RevokeMsg(ctx context.Context, conversationID string, seq int64, revoke *unrelationtb.RevokeModel) error
+	// 编辑消息内容，保留历史版本并删除redis中的消息缓存
+	EditMsg(ctx context.Context, conversationID string, seq int64, content string, edit *unrelationtb.EditModel) error
 	// mark as read
 	MarkSingleChatMsgsAsRead(ctx context.Context, userID string, conversationID string, seqs []int64) error
+	// 群聊消息已读回执，记录已读成员, 返回尚未写入mongo而未记录的seq
+	MarkGroupMsgsAsRead(ctx context.Context, userID string, conversationID string, seqs []int64) (unpersisted []int64, err error)
+	// 获取群聊消息的发送者和已读成员列表
+	GetGroupMsgReadList(ctx context.Context, conversationID string, seq int64) (sendID string, readList []string, err error)
+	// 获取群聊消息的已读人数
+	GetGroupMsgsReadCount(ctx context.Context, conversationID string, seqs []int64) (map[int64]int64, error)
+	// 记录已读人数变化的消息seq，返回是否需要延迟delay后汇总通知发送者
+	AddGroupReadReceiptSeqs(ctx context.Context, conversationID, sendID string, seqs []int64, delay time.Duration) (bool, error)
+	// 取出已读人数变化的消息seq
+	PopGroupReadReceiptSeqs(ctx context.Context, conversationID, sendID string) ([]int64, error)
+	// 获取消息的译文缓存
+	GetMsgTranslation(ctx context.Context, conversationID string, seq int64, lang string) (string, error)
+	// 缓存消息的译文
+	SetMsgTranslation(ctx context.Context, conversationID string, seq int64, lang string, text string, expire time.Duration) error
+	// 批量获取消息的所有译文
+	GetMsgsTranslations(ctx context.Context, conversationID string, seqs []int64) (map[int64]map[string]string, error)
 	// 刪除redis中消息缓存
 	DeleteMessagesFromCache(ctx context.Context, conversationID string, seqs []int64) error
 	DelUserDeleteMsgsList(ctx context.Context, conversationID string, seqs []int64)
@@ -95,6 +121,18 @@ type CommonMsgDatabase interface {
 	GetSendMsgStatus(ctx context.Context, id string) (int32, error)
 	SearchMessage(ctx context.Context, req *pbmsg.SearchMessageReq) (total int32, msgData []*sdkws.MsgData, err error)
 
+	// 获取消息的回应列表, 缓存不存在时从mongo加载
+	GetMsgReactions(ctx context.Context, conversationID string, seq int64, clientMsgID string, sessionType int32) (map[string][]string, error)
+	// 修改消息的一个回应, 由redis脚本原子地写入缓存, 无需加锁, 之后通过modify topic持久化到mongo
+	ModifyMsgReaction(ctx context.Context, conversationID string, seq int64, clientMsgID string, sessionType int32, reactionType string, userID string, isAdd bool) (userIDs []string, err error)
+	// 将modify topic中的回应修改写入mongo
+	PersistMsgReactions(ctx context.Context, conversationID string, msgs []*sdkws.MsgData) error
+
+	// 话题回复写入派生的话题会话(独立seq空间), 直接写入缓存和mongo
+	InsertThreadReply(ctx context.Context, threadConversationID string, msg *sdkws.MsgData) error
+	// 话题根消息的回复数加一并记录最新回复, 删除根消息缓存后返回更新后的摘要. 根消息尚未写入mongo时会等待重试
+	UpdateMsgThread(ctx context.Context, conversationID string, rootSeq int64, thread *unrelationtb.ThreadModel) (*unrelationtb.ThreadModel, error)
+
 	// to mq
 	MsgToMQ(ctx context.Context, key string, msg2mq *sdkws.MsgData) error
 	MsgToModifyMQ(ctx context.Context, key, conversarionID string, msgs []*sdkws.MsgData) error
@@ -123,11 +161,12 @@ type CommonMsgDatabase interface {
 
 func NewCommonMsgDatabase(msgDocModel unrelationtb.MsgDocModelInterface, cacheModel cache.MsgModel) CommonMsgDatabase {
 	return &commonMsgDatabase{
-		msgDocDatabase:  msgDocModel,
-		cache:           cacheModel,
-		producer:        kafka.NewKafkaProducer(config.Config.Kafka.Addr, config.Config.Kafka.LatestMsgToRedis.Topic),
-		producerToMongo: kafka.NewKafkaProducer(config.Config.Kafka.Addr, config.Config.Kafka.MsgToMongo.Topic),
-		producerToPush:  kafka.NewKafkaProducer(config.Config.Kafka.Addr, config.Config.Kafka.MsgToPush.Topic),
+		msgDocDatabase:   msgDocModel,
+		cache:            cacheModel,
+		producer:         kafka.NewKafkaProducer(config.Config.Kafka.Addr, config.Config.Kafka.LatestMsgToRedis.Topic),
+		producerToMongo:  kafka.NewKafkaProducer(config.Config.Kafka.Addr, config.Config.Kafka.MsgToMongo.Topic),
+		producerToPush:   kafka.NewKafkaProducer(config.Config.Kafka.Addr, config.Config.Kafka.MsgToPush.Topic),
+		producerToModify: kafka.NewKafkaProducer(config.Config.Kafka.Addr, config.Config.Kafka.MsgToModify.Topic),
 	}
 }
 
@@ -329,6 +368,21 @@ func (db *commonMsgDatabase) RevokeMsg(ctx context.Context, conversationID strin
 	return db.BatchInsertBlock(ctx, conversationID, []any{revoke}, updateKeyRevoke, seq)
 }
 
+func (db *commonMsgDatabase) EditMsg(ctx context.Context, conversationID string, seq int64, content string, edit *unrelationtb.EditModel) error {
+	res, err := db.msgDocDatabase.EditMsg(ctx, db.msg.GetDocID(conversationID, seq), db.msg.GetMsgIndex(seq), content, edit)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errs.ErrRecordNotFound.Wrap("msg not persisted or modified concurrently")
+	}
+	// the translations are of the old content
+	if err := db.cache.DelMsgTranslation(ctx, conversationID, seq); err != nil {
+		return err
+	}
+	return db.cache.DeleteMessages(ctx, conversationID, []int64{seq})
+}
+
 func (db *commonMsgDatabase) MarkSingleChatMsgsAsRead(ctx context.Context, userID string, conversationID string, totalSeqs []int64) error {
 	for docID, seqs := range db.msg.GetDocIDSeqsMap(conversationID, totalSeqs) {
 		var indexes []int64
@@ -344,6 +398,73 @@ func (db *commonMsgDatabase) MarkSingleChatMsgsAsRead(ctx context.Context, userI
 	return nil
 }
 
+func (db *commonMsgDatabase) MarkGroupMsgsAsRead(ctx context.Context, userID string, conversationID string, totalSeqs []int64) ([]int64, error) {
+	var unpersisted []int64
+	for docID, seqs := range db.msg.GetDocIDSeqsMap(conversationID, totalSeqs) {
+		indexes := make([]int64, 0, len(seqs))
+		for _, seq := range seqs {
+			indexes = append(indexes, db.msg.GetMsgIndex(seq))
+		}
+		persisted, err := db.msgDocDatabase.GetPersistedMsgIndexes(ctx, docID, indexes)
+		if err != nil {
+			return nil, err
+		}
+		persistedSet := utils.SliceSet(persisted)
+		for _, seq := range seqs {
+			if _, ok := persistedSet[db.msg.GetMsgIndex(seq)]; !ok {
+				unpersisted = append(unpersisted, seq)
+			}
+		}
+		if err := db.msgDocDatabase.MarkGroupMsgsAsRead(ctx, userID, docID, persisted); err != nil {
+			log.ZError(ctx, "MarkGroupMsgsAsRead", err, "userID", userID, "docID", docID, "indexes", persisted)
+			return nil, err
+		}
+	}
+	return unpersisted, nil
+}
+
+func (db *commonMsgDatabase) GetGroupMsgReadList(ctx context.Context, conversationID string, seq int64) (string, []string, error) {
+	return db.msgDocDatabase.GetMsgReadList(ctx, db.msg.GetDocID(conversationID, seq), db.msg.GetMsgIndex(seq))
+}
+
+func (db *commonMsgDatabase) GetGroupMsgsReadCount(ctx context.Context, conversationID string, totalSeqs []int64) (map[int64]int64, error) {
+	counts := make(map[int64]int64, len(totalSeqs))
+	for docID, seqs := range db.msg.GetDocIDSeqsMap(conversationID, totalSeqs) {
+		indexes := make([]int64, 0, len(seqs))
+		for _, seq := range seqs {
+			indexes = append(indexes, db.msg.GetMsgIndex(seq))
+		}
+		indexCounts, err := db.msgDocDatabase.GetMsgsReadCount(ctx, docID, indexes)
+		if err != nil {
+			return nil, err
+		}
+		for _, seq := range seqs {
+			counts[seq] = indexCounts[db.msg.GetMsgIndex(seq)]
+		}
+	}
+	return counts, nil
+}
+
+func (db *commonMsgDatabase) AddGroupReadReceiptSeqs(ctx context.Context, conversationID, sendID string, seqs []int64, delay time.Duration) (bool, error) {
+	return db.cache.AddGroupReadReceiptSeqs(ctx, conversationID, sendID, seqs, delay)
+}
+
+func (db *commonMsgDatabase) PopGroupReadReceiptSeqs(ctx context.Context, conversationID, sendID string) ([]int64, error) {
+	return db.cache.PopGroupReadReceiptSeqs(ctx, conversationID, sendID)
+}
+
+func (db *commonMsgDatabase) GetMsgTranslation(ctx context.Context, conversationID string, seq int64, lang string) (string, error) {
+	return db.cache.GetMsgTranslation(ctx, conversationID, seq, lang)
+}
+
+func (db *commonMsgDatabase) SetMsgTranslation(ctx context.Context, conversationID string, seq int64, lang string, text string, expire time.Duration) error {
+	return db.cache.SetMsgTranslation(ctx, conversationID, seq, lang, text, expire)
+}
+
+func (db *commonMsgDatabase) GetMsgsTranslations(ctx context.Context, conversationID string, seqs []int64) (map[int64]map[string]string, error) {
+	return db.cache.GetMsgsTranslations(ctx, conversationID, seqs)
+}
+
 func (db *commonMsgDatabase) DeleteMessagesFromCache(ctx context.Context, conversationID string, seqs []int64) error {
 	return db.cache.DeleteMessages(ctx, conversationID, seqs)
 }
@@ -957,6 +1078,180 @@ func (db *commonMsgDatabase) SearchMessage(ctx context.Context, req *pbmsg.Searc
 	return total, totalMsgs, nil
 }
 
+// reactionElem is the content of the reaction modifier and deleter messages sent to the modify topic.
+type reactionElem struct {
+	ReactionType string `json:"reactionType"`
+}
+
+func (db *commonMsgDatabase) GetMsgReactions(ctx context.Context, conversationID string, seq int64, clientMsgID string, sessionType int32) (map[string][]string, error) {
+	exist, err := db.cache.JudgeMessageReactionExist(ctx, clientMsgID, sessionType)
+	if err != nil {
+		return nil, err
+	}
+	reactions := make(map[string][]string)
+	if exist {
+		values, err := db.cache.GetOneMessageAllReactionList(ctx, clientMsgID, sessionType)
+		if err != nil {
+			return nil, err
+		}
+		for reactionType, value := range values {
+			var userIDs []string
+			if err := json.Unmarshal([]byte(value), &userIDs); err != nil {
+				return nil, errs.Wrap(err)
+			}
+			reactions[reactionType] = userIDs
+		}
+		return reactions, nil
+	}
+	stored, err := db.msgDocDatabase.GetMsgReactions(ctx, db.msg.GetDocID(conversationID, seq), db.msg.GetMsgIndex(seq))
+	if err != nil {
+		return nil, err
+	}
+	for reactionType, userIDs := range stored {
+		if len(userIDs) == 0 {
+			continue
+		}
+		if err := db.cache.SetMessageTypeKeyValue(ctx, clientMsgID, sessionType, reactionType, utils.StructToJsonString(userIDs)); err != nil {
+			return nil, err
+		}
+		reactions[reactionType] = userIDs
+	}
+	if len(reactions) > 0 {
+		if _, err := db.cache.SetMessageReactionExpire(ctx, clientMsgID, sessionType, time.Duration(config.Config.MsgCacheTimeout)*time.Second); err != nil {
+			return nil, err
+		}
+	}
+	return reactions, nil
+}
+
+func (db *commonMsgDatabase) ModifyMsgReaction(
+	ctx context.Context,
+	conversationID string,
+	seq int64,
+	clientMsgID string,
+	sessionType int32,
+	reactionType string,
+	userID string,
+	isAdd bool,
+) ([]string, error) {
+	// loads the reactions persisted in mongo into the cache before changing them there
+	if _, err := db.GetMsgReactions(ctx, conversationID, seq, clientMsgID, sessionType); err != nil {
+		return nil, err
+	}
+	userIDs, changed, err := db.cache.ModifyMessageReaction(ctx, clientMsgID, sessionType, reactionType, userID, isAdd,
+		time.Duration(config.Config.MsgCacheTimeout)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return userIDs, nil
+	}
+	contentType := int32(constant.ReactionMessageModifier)
+	if !isAdd {
+		contentType = constant.ReactionMessageDeleter
+	}
+	msg := &sdkws.MsgData{
+		SendID:      userID,
+		ClientMsgID: clientMsgID,
+		SessionType: sessionType,
+		ContentType: contentType,
+		Seq:         seq,
+		Content:     []byte(utils.StructToJsonString(reactionElem{ReactionType: reactionType})),
+		SendTime:    time.Now().UnixMilli(),
+	}
+	if err := db.MsgToModifyMQ(ctx, conversationID, conversationID, []*sdkws.MsgData{msg}); err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+func (db *commonMsgDatabase) PersistMsgReactions(ctx context.Context, conversationID string, msgs []*sdkws.MsgData) error {
+	for _, msg := range msgs {
+		var elem reactionElem
+		if err := json.Unmarshal(msg.Content, &elem); err != nil || elem.ReactionType == "" || msg.Seq <= 0 {
+			log.ZWarn(ctx, "skip unknown reaction msg", err, "conversationID", conversationID, "msg", msg)
+			continue
+		}
+		docID := db.msg.GetDocID(conversationID, msg.Seq)
+		index := db.msg.GetMsgIndex(msg.Seq)
+		var (
+			res *mongo.UpdateResult
+			err error
+		)
+		switch msg.ContentType {
+		case constant.ReactionMessageModifier:
+			res, err = db.msgDocDatabase.AddMsgReaction(ctx, docID, index, elem.ReactionType, msg.SendID)
+		case constant.ReactionMessageDeleter:
+			res, err = db.msgDocDatabase.RemoveMsgReaction(ctx, docID, index, elem.ReactionType, msg.SendID)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount == 0 {
+			log.ZWarn(ctx, "reaction msg doc not found", nil, "conversationID", conversationID, "seq", msg.Seq)
+		}
+	}
+	return nil
+}
+
 func (db *commonMsgDatabase) ConvertMsgsDocLen(ctx context.Context, conversationIDs []string) {
 	db.msgDocDatabase.ConvertMsgsDocLen(ctx, conversationIDs)
 }
+
+func (db *commonMsgDatabase) InsertThreadReply(ctx context.Context, threadConversationID string, msg *sdkws.MsgData) error {
+	seq, err := db.cache.IncrMaxSeq(ctx, threadConversationID, 1)
+	if err != nil {
+		prome.Inc(prome.SeqSetFailedCounter)
+		return err
+	}
+	msg.Seq = seq
+	if failedNum, err := db.cache.SetMessageToCache(ctx, threadConversationID, []*sdkws.MsgData{msg}); err != nil {
+		prome.Add(prome.MsgInsertRedisFailedCounter, failedNum)
+		log.ZError(ctx, "setMessageToCache error", err, "threadConversationID", threadConversationID, "seq", seq)
+	}
+	if err := db.cache.SetHasReadSeq(ctx, msg.SendID, threadConversationID, seq); err != nil {
+		log.ZError(ctx, "SetHasReadSeq error", err, "threadConversationID", threadConversationID, "seq", seq)
+	}
+	return db.BatchInsertChat2DB(ctx, threadConversationID, []*sdkws.MsgData{msg}, seq-1)
+}
+
+func (db *commonMsgDatabase) UpdateMsgThread(ctx context.Context, conversationID string, rootSeq int64, thread *unrelationtb.ThreadModel) (*unrelationtb.ThreadModel, error) {
+	docID, index := db.msg.GetDocID(conversationID, rootSeq), db.msg.GetMsgIndex(rootSeq)
+	var summary *unrelationtb.ThreadModel
+	for i := 0; ; i++ {
+		var err error
+		summary, err = db.msgDocDatabase.IncrMsgThreadReply(ctx, docID, index, thread.ThreadConversationID)
+		if err != nil {
+			return nil, err
+		}
+		if summary != nil {
+			break
+		}
+		// the root msg is persisted by msg transfer, which may be behind
+		if i >= threadRootPersistRetry {
+			return nil, errs.ErrRecordNotFound.Wrap("thread root msg is not persisted")
+		}
+		log.ZDebug(ctx, "thread root msg not persisted yet", "conversationID", conversationID, "rootSeq", rootSeq, "retry", i)
+		select {
+		case <-ctx.Done():
+			return nil, errs.Wrap(ctx.Err())
+		case <-time.After(threadRootPersistInterval):
+		}
+	}
+	res, err := db.msgDocDatabase.UpdateMsgThreadLastReply(ctx, docID, index, thread)
+	if err != nil {
+		return nil, err
+	}
+	if res.ModifiedCount > 0 {
+		summary.LastReplySeq = thread.LastReplySeq
+		summary.LastReplySendID = thread.LastReplySendID
+		summary.LastReplyClientMsgID = thread.LastReplyClientMsgID
+		summary.LastReplyTime = thread.LastReplyTime
+	}
+	if err := db.cache.DeleteMessages(ctx, conversationID, []int64{rootSeq}); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
@@ -0,0 +1,62 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+
+	relationtb "github.com/openimsdk/open-im-server/v3/pkg/common/db/table/relation"
+)
+
+type PinnedMsgDatabase interface {
+	// PinMsg 置顶会话中的消息
+	PinMsg(ctx context.Context, pinnedMsg *relationtb.PinnedMsgModel) error
+	// UnpinMsg 取消置顶
+	UnpinMsg(ctx context.Context, conversationID string, seq int64) error
+	// TakePinnedMsg 获取会话中的一条置顶消息
+	TakePinnedMsg(ctx context.Context, conversationID string, seq int64) (*relationtb.PinnedMsgModel, error)
+	// CountPinnedMsgs 会话中置顶消息数量
+	CountPinnedMsgs(ctx context.Context, conversationID string) (int64, error)
+	// FindPinnedMsgs 获取会话中的置顶消息, 按置顶时间倒序
+	FindPinnedMsgs(ctx context.Context, conversationID string) ([]*relationtb.PinnedMsgModel, error)
+}
+
+func NewPinnedMsgDatabase(pinnedMsg relationtb.PinnedMsgModelInterface) PinnedMsgDatabase {
+	return &pinnedMsgDatabase{pinnedMsg: pinnedMsg}
+}
+
+type pinnedMsgDatabase struct {
+	pinnedMsg relationtb.PinnedMsgModelInterface
+}
+
+func (p *pinnedMsgDatabase) PinMsg(ctx context.Context, pinnedMsg *relationtb.PinnedMsgModel) error {
+	return p.pinnedMsg.Create(ctx, []*relationtb.PinnedMsgModel{pinnedMsg})
+}
+
+func (p *pinnedMsgDatabase) UnpinMsg(ctx context.Context, conversationID string, seq int64) error {
+	return p.pinnedMsg.Delete(ctx, conversationID, []int64{seq})
+}
+
+func (p *pinnedMsgDatabase) TakePinnedMsg(ctx context.Context, conversationID string, seq int64) (*relationtb.PinnedMsgModel, error) {
+	return p.pinnedMsg.Take(ctx, conversationID, seq)
+}
+
+func (p *pinnedMsgDatabase) CountPinnedMsgs(ctx context.Context, conversationID string) (int64, error) {
+	return p.pinnedMsg.Count(ctx, conversationID)
+}
+
+func (p *pinnedMsgDatabase) FindPinnedMsgs(ctx context.Context, conversationID string) ([]*relationtb.PinnedMsgModel, error) {
+	return p.pinnedMsg.FindByConversationID(ctx, conversationID)
+}
@@ -22,16 +22,31 @@ import (
 
 type PushDatabase interface {
 	DelFcmToken(ctx context.Context, userID string, platformID int) error
+	DelApnsToken(ctx context.Context, userID string, platformID int) error
+	DelPushDevices(ctx context.Context, userID string, platformID int32) error
 }
 
 type pushDataBase struct {
-	cache cache.MsgModel
+	cache      cache.MsgModel
+	pushDevice PushDeviceDatabase
 }
 
-func NewPushDatabase(cache cache.MsgModel) PushDatabase {
-	return &pushDataBase{cache: cache}
+func NewPushDatabase(cache cache.MsgModel, pushDevice PushDeviceDatabase) PushDatabase {
+	return &pushDataBase{cache: cache, pushDevice: pushDevice}
 }
 
 func (p *pushDataBase) DelFcmToken(ctx context.Context, userID string, platformID int) error {
 	return p.cache.DelFcmToken(ctx, userID, platformID)
 }
+
+func (p *pushDataBase) DelApnsToken(ctx context.Context, userID string, platformID int) error {
+	return p.cache.DelApnsToken(ctx, userID, platformID)
+}
+
+func (p *pushDataBase) DelPushDevices(ctx context.Context, userID string, platformID int32) error {
+	if p.pushDevice == nil {
+		// push rpc only opens mysql when it routes or localizes pushes
+		return nil
+	}
+	return p.pushDevice.DeleteUserPlatformDevices(ctx, userID, platformID)
+}
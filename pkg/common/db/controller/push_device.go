@@ -0,0 +1,62 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+
+	relationtb "github.com/openimsdk/open-im-server/v3/pkg/common/db/table/relation"
+)
+
+type PushDeviceDatabase interface {
+	// RegisterDevice 注册推送设备, token已存在时转移给当前用户
+	RegisterDevice(ctx context.Context, device *relationtb.PushDeviceModel) error
+	// UnregisterDevices 删除推送设备
+	UnregisterDevices(ctx context.Context, provider string, tokens []string) error
+	// DeleteUserPlatformDevices 删除用户某平台的推送设备, 用于登出
+	DeleteUserPlatformDevices(ctx context.Context, userID string, platformID int32) error
+	// FindUserDevices 获取用户的推送设备
+	FindUserDevices(ctx context.Context, userIDs []string) ([]*relationtb.PushDeviceModel, error)
+}
+
+func NewPushDeviceDatabase(device relationtb.PushDeviceModelInterface) PushDeviceDatabase {
+	return &pushDeviceDatabase{device: device}
+}
+
+type pushDeviceDatabase struct {
+	device relationtb.PushDeviceModelInterface
+}
+
+func (p *pushDeviceDatabase) RegisterDevice(ctx context.Context, device *relationtb.PushDeviceModel) error {
+	return p.device.Upsert(ctx, []*relationtb.PushDeviceModel{device})
+}
+
+func (p *pushDeviceDatabase) UnregisterDevices(ctx context.Context, provider string, tokens []string) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	return p.device.Delete(ctx, provider, tokens)
+}
+
+func (p *pushDeviceDatabase) DeleteUserPlatformDevices(ctx context.Context, userID string, platformID int32) error {
+	return p.device.DeleteByUserPlatform(ctx, userID, platformID)
+}
+
+func (p *pushDeviceDatabase) FindUserDevices(ctx context.Context, userIDs []string) ([]*relationtb.PushDeviceModel, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	return p.device.FindByUserIDs(ctx, userIDs)
+}
@@ -0,0 +1,66 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+
+	relationtb "github.com/openimsdk/open-im-server/v3/pkg/common/db/table/relation"
+)
+
+type PushTemplateDatabase interface {
+	// SetTemplates 新增或覆盖推送模板
+	SetTemplates(ctx context.Context, templates []*relationtb.PushTemplateModel) error
+	// DeleteTemplate 删除推送模板
+	DeleteTemplate(ctx context.Context, contentType int32, locale string) error
+	// FindAllTemplates 获取全部推送模板
+	FindAllTemplates(ctx context.Context) ([]*relationtb.PushTemplateModel, error)
+	// SetUserSetting 设置用户的推送语言和是否隐藏内容
+	SetUserSetting(ctx context.Context, setting *relationtb.PushUserSettingModel) error
+	// FindUserSettings 获取用户的推送设置, 未设置的用户不返回
+	FindUserSettings(ctx context.Context, userIDs []string) ([]*relationtb.PushUserSettingModel, error)
+}
+
+func NewPushTemplateDatabase(template relationtb.PushTemplateModelInterface, setting relationtb.PushUserSettingModelInterface) PushTemplateDatabase {
+	return &pushTemplateDatabase{template: template, setting: setting}
+}
+
+type pushTemplateDatabase struct {
+	template relationtb.PushTemplateModelInterface
+	setting  relationtb.PushUserSettingModelInterface
+}
+
+func (p *pushTemplateDatabase) SetTemplates(ctx context.Context, templates []*relationtb.PushTemplateModel) error {
+	return p.template.Upsert(ctx, templates)
+}
+
+func (p *pushTemplateDatabase) DeleteTemplate(ctx context.Context, contentType int32, locale string) error {
+	return p.template.Delete(ctx, contentType, locale)
+}
+
+func (p *pushTemplateDatabase) FindAllTemplates(ctx context.Context) ([]*relationtb.PushTemplateModel, error) {
+	return p.template.FindAll(ctx)
+}
+
+func (p *pushTemplateDatabase) SetUserSetting(ctx context.Context, setting *relationtb.PushUserSettingModel) error {
+	return p.setting.Upsert(ctx, []*relationtb.PushUserSettingModel{setting})
+}
+
+func (p *pushTemplateDatabase) FindUserSettings(ctx context.Context, userIDs []string) ([]*relationtb.PushUserSettingModel, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	return p.setting.FindByUserIDs(ctx, userIDs)
+}
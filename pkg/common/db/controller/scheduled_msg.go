@@ -0,0 +1,91 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/cache"
+	unrelationtb "github.com/openimsdk/open-im-server/v3/pkg/common/db/table/unrelation"
+)
+
+type ScheduledMsgDatabase interface {
+	// CreateScheduledMsg 保存待发送的定时消息
+	CreateScheduledMsg(ctx context.Context, msg *unrelationtb.ScheduledMsgModel) error
+	// TakeScheduledMsg 获取定时消息
+	TakeScheduledMsg(ctx context.Context, scheduleID string) (*unrelationtb.ScheduledMsgModel, error)
+	// PageScheduledMsgs 分页获取发送者的定时消息
+	PageScheduledMsgs(ctx context.Context, sendID string, status []int32, pageNumber, showNumber int32) (int64, []*unrelationtb.ScheduledMsgModel, error)
+	// CancelScheduledMsg 取消未发送的定时消息, 返回是否成功
+	CancelScheduledMsg(ctx context.Context, scheduleID string) (bool, error)
+	// RescheduleMsg 修改未发送的定时消息的发送时间, 返回是否成功
+	RescheduleMsg(ctx context.Context, scheduleID string, sendTime int64) (bool, error)
+	// ClaimDueScheduledMsg 领取一条已到发送时间或发送超时的消息, 没有则返回nil
+	ClaimDueScheduledMsg(ctx context.Context, now int64, staleBefore int64) (*unrelationtb.ScheduledMsgModel, error)
+	// StartScheduledMsgDelivery 标记消息开始投递, 已被之前的领取投递过则返回false
+	StartScheduledMsgDelivery(ctx context.Context, clientMsgID string) (bool, error)
+	// SetScheduledMsgStatus 设置本次领取的发送结果, 消息已被重新领取则返回false
+	SetScheduledMsgStatus(ctx context.Context, scheduled *unrelationtb.ScheduledMsgModel, status int32, ex string) (bool, error)
+}
+
+// scheduledMsgDeliveryExpire how long a delivered message is remembered, far longer than a claim can take.
+const scheduledMsgDeliveryExpire = time.Hour * 24
+
+func NewScheduledMsgDatabase(scheduledMsg unrelationtb.ScheduledMsgModelInterface, cache cache.ScheduledMsgCache) ScheduledMsgDatabase {
+	return &scheduledMsgDatabase{scheduledMsg: scheduledMsg, cache: cache}
+}
+
+type scheduledMsgDatabase struct {
+	scheduledMsg unrelationtb.ScheduledMsgModelInterface
+	cache        cache.ScheduledMsgCache
+}
+
+func (s *scheduledMsgDatabase) CreateScheduledMsg(ctx context.Context, msg *unrelationtb.ScheduledMsgModel) error {
+	return s.scheduledMsg.Create(ctx, msg)
+}
+
+func (s *scheduledMsgDatabase) TakeScheduledMsg(ctx context.Context, scheduleID string) (*unrelationtb.ScheduledMsgModel, error) {
+	return s.scheduledMsg.Take(ctx, scheduleID)
+}
+
+func (s *scheduledMsgDatabase) PageScheduledMsgs(
+	ctx context.Context,
+	sendID string,
+	status []int32,
+	pageNumber, showNumber int32,
+) (int64, []*unrelationtb.ScheduledMsgModel, error) {
+	return s.scheduledMsg.FindBySendID(ctx, sendID, status, pageNumber, showNumber)
+}
+
+func (s *scheduledMsgDatabase) CancelScheduledMsg(ctx context.Context, scheduleID string) (bool, error) {
+	return s.scheduledMsg.UpdatePending(ctx, scheduleID, map[string]any{"status": unrelationtb.ScheduledMsgStatusCanceled})
+}
+
+func (s *scheduledMsgDatabase) RescheduleMsg(ctx context.Context, scheduleID string, sendTime int64) (bool, error) {
+	return s.scheduledMsg.UpdatePending(ctx, scheduleID, map[string]any{"send_time": sendTime})
+}
+
+func (s *scheduledMsgDatabase) ClaimDueScheduledMsg(ctx context.Context, now int64, staleBefore int64) (*unrelationtb.ScheduledMsgModel, error) {
+	return s.scheduledMsg.ClaimDue(ctx, now, staleBefore)
+}
+
+func (s *scheduledMsgDatabase) StartScheduledMsgDelivery(ctx context.Context, clientMsgID string) (bool, error) {
+	return s.cache.StartScheduledMsgDelivery(ctx, clientMsgID, scheduledMsgDeliveryExpire)
+}
+
+func (s *scheduledMsgDatabase) SetScheduledMsgStatus(ctx context.Context, scheduled *unrelationtb.ScheduledMsgModel, status int32, ex string) (bool, error) {
+	return s.scheduledMsg.UpdateStatus(ctx, scheduled.ScheduleID, scheduled.UpdateTime, status, ex)
+}
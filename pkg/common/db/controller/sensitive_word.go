@@ -0,0 +1,75 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/cache"
+	unrelationtb "github.com/openimsdk/open-im-server/v3/pkg/common/db/table/unrelation"
+)
+
+type SensitiveWordDatabase interface {
+	// AddSensitiveWords 添加敏感词, 已存在的词更新分类
+	AddSensitiveWords(ctx context.Context, words []*unrelationtb.SensitiveWordModel) error
+	// DeleteSensitiveWords 删除敏感词
+	DeleteSensitiveWords(ctx context.Context, words []string) error
+	// SearchSensitiveWords 分页搜索敏感词
+	SearchSensitiveWords(ctx context.Context, keyword string, category string, pageNumber, showNumber int32) (int64, []*unrelationtb.SensitiveWordModel, error)
+	// FindAllSensitiveWords 获取全部敏感词
+	FindAllSensitiveWords(ctx context.Context) ([]*unrelationtb.SensitiveWordModel, error)
+	// GetSensitiveWordVersion 获取词库版本, 词库变更后版本递增
+	GetSensitiveWordVersion(ctx context.Context) (int64, error)
+}
+
+func NewSensitiveWordDatabase(sensitiveWord unrelationtb.SensitiveWordModelInterface, cache cache.SensitiveWordCache) SensitiveWordDatabase {
+	return &sensitiveWordDatabase{sensitiveWord: sensitiveWord, cache: cache}
+}
+
+type sensitiveWordDatabase struct {
+	sensitiveWord unrelationtb.SensitiveWordModelInterface
+	cache         cache.SensitiveWordCache
+}
+
+func (s *sensitiveWordDatabase) AddSensitiveWords(ctx context.Context, words []*unrelationtb.SensitiveWordModel) error {
+	if err := s.sensitiveWord.Upsert(ctx, words); err != nil {
+		return err
+	}
+	return s.cache.IncrSensitiveWordVersion(ctx)
+}
+
+func (s *sensitiveWordDatabase) DeleteSensitiveWords(ctx context.Context, words []string) error {
+	if err := s.sensitiveWord.Delete(ctx, words); err != nil {
+		return err
+	}
+	return s.cache.IncrSensitiveWordVersion(ctx)
+}
+
+func (s *sensitiveWordDatabase) SearchSensitiveWords(
+	ctx context.Context,
+	keyword string,
+	category string,
+	pageNumber, showNumber int32,
+) (int64, []*unrelationtb.SensitiveWordModel, error) {
+	return s.sensitiveWord.Search(ctx, keyword, category, pageNumber, showNumber)
+}
+
+func (s *sensitiveWordDatabase) FindAllSensitiveWords(ctx context.Context) ([]*unrelationtb.SensitiveWordModel, error) {
+	return s.sensitiveWord.FindAll(ctx)
+}
+
+func (s *sensitiveWordDatabase) GetSensitiveWordVersion(ctx context.Context) (int64, error) {
+	return s.cache.GetSensitiveWordVersion(ctx)
+}
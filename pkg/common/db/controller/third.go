@@ -22,6 +22,7 @@ import (
 
 type ThirdDatabase interface {
 	FcmUpdateToken(ctx context.Context, account string, platformID int, fcmToken string, expireTime int64) error
+	ApnsUpdateToken(ctx context.Context, account string, platformID int, apnsToken string, expireTime int64) error
 	SetAppBadge(ctx context.Context, userID string, value int) error
 }
 
@@ -43,6 +44,16 @@ func (t *thirdDatabase) FcmUpdateToken(
 	return t.cache.SetFcmToken(ctx, account, platformID, fcmToken, expireTime)
 }
 
+func (t *thirdDatabase) ApnsUpdateToken(
+	ctx context.Context,
+	account string,
+	platformID int,
+	apnsToken string,
+	expireTime int64,
+) error {
+	return t.cache.SetApnsToken(ctx, account, platformID, apnsToken, expireTime)
+}
+
 func (t *thirdDatabase) SetAppBadge(ctx context.Context, userID string, value int) error {
 	return t.cache.SetUserBadgeUnreadCountSum(ctx, userID, value)
 }
@@ -0,0 +1,62 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relation
+
+import (
+	"context"
+
+	"github.com/OpenIMSDK/tools/utils"
+	"gorm.io/gorm"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/table/relation"
+)
+
+type PinnedMsgGorm struct {
+	*MetaDB
+}
+
+func NewPinnedMsgGorm(db *gorm.DB) relation.PinnedMsgModelInterface {
+	return &PinnedMsgGorm{NewMetaDB(db, &relation.PinnedMsgModel{})}
+}
+
+func (p *PinnedMsgGorm) Create(ctx context.Context, pinnedMsgs []*relation.PinnedMsgModel) (err error) {
+	return utils.Wrap(p.db(ctx).Create(&pinnedMsgs).Error, "")
+}
+
+func (p *PinnedMsgGorm) Delete(ctx context.Context, conversationID string, seqs []int64) (err error) {
+	return utils.Wrap(
+		p.db(ctx).Where("conversation_id = ? and seq in ?", conversationID, seqs).Delete(&relation.PinnedMsgModel{}).Error,
+		"",
+	)
+}
+
+func (p *PinnedMsgGorm) Take(ctx context.Context, conversationID string, seq int64) (pinnedMsg *relation.PinnedMsgModel, err error) {
+	pinnedMsg = &relation.PinnedMsgModel{}
+	return pinnedMsg, utils.Wrap(
+		p.db(ctx).Where("conversation_id = ? and seq = ?", conversationID, seq).Take(pinnedMsg).Error,
+		"",
+	)
+}
+
+func (p *PinnedMsgGorm) Count(ctx context.Context, conversationID string) (count int64, err error) {
+	return count, utils.Wrap(p.db(ctx).Where("conversation_id = ?", conversationID).Count(&count).Error, "")
+}
+
+func (p *PinnedMsgGorm) FindByConversationID(ctx context.Context, conversationID string) (pinnedMsgs []*relation.PinnedMsgModel, err error) {
+	return pinnedMsgs, utils.Wrap(
+		p.db(ctx).Where("conversation_id = ?", conversationID).Order("pin_time desc").Find(&pinnedMsgs).Error,
+		"",
+	)
+}
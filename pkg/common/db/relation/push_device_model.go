@@ -0,0 +1,55 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relation
+
+import (
+	"context"
+
+	"github.com/OpenIMSDK/tools/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/table/relation"
+)
+
+type PushDeviceGorm struct {
+	*MetaDB
+}
+
+func NewPushDeviceGorm(db *gorm.DB) relation.PushDeviceModelInterface {
+	return &PushDeviceGorm{NewMetaDB(db, &relation.PushDeviceModel{})}
+}
+
+func (p *PushDeviceGorm) Upsert(ctx context.Context, devices []*relation.PushDeviceModel) (err error) {
+	return utils.Wrap(p.db(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&devices).Error, "")
+}
+
+func (p *PushDeviceGorm) Delete(ctx context.Context, provider string, tokens []string) (err error) {
+	return utils.Wrap(
+		p.db(ctx).Where("provider = ? and token in ?", provider, tokens).Delete(&relation.PushDeviceModel{}).Error,
+		"",
+	)
+}
+
+func (p *PushDeviceGorm) DeleteByUserPlatform(ctx context.Context, userID string, platformID int32) (err error) {
+	return utils.Wrap(
+		p.db(ctx).Where("user_id = ? and platform_id = ?", userID, platformID).Delete(&relation.PushDeviceModel{}).Error,
+		"",
+	)
+}
+
+func (p *PushDeviceGorm) FindByUserIDs(ctx context.Context, userIDs []string) (devices []*relation.PushDeviceModel, err error) {
+	return devices, utils.Wrap(p.db(ctx).Where("user_id in ?", userIDs).Find(&devices).Error, "")
+}
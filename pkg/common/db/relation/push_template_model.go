@@ -0,0 +1,64 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relation
+
+import (
+	"context"
+
+	"github.com/OpenIMSDK/tools/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/table/relation"
+)
+
+type PushTemplateGorm struct {
+	*MetaDB
+}
+
+func NewPushTemplateGorm(db *gorm.DB) relation.PushTemplateModelInterface {
+	return &PushTemplateGorm{NewMetaDB(db, &relation.PushTemplateModel{})}
+}
+
+func (p *PushTemplateGorm) Upsert(ctx context.Context, templates []*relation.PushTemplateModel) (err error) {
+	return utils.Wrap(p.db(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&templates).Error, "")
+}
+
+func (p *PushTemplateGorm) Delete(ctx context.Context, contentType int32, locale string) (err error) {
+	return utils.Wrap(
+		p.db(ctx).Where("content_type = ? and locale = ?", contentType, locale).Delete(&relation.PushTemplateModel{}).Error,
+		"",
+	)
+}
+
+func (p *PushTemplateGorm) FindAll(ctx context.Context) (templates []*relation.PushTemplateModel, err error) {
+	return templates, utils.Wrap(p.db(ctx).Find(&templates).Error, "")
+}
+
+type PushUserSettingGorm struct {
+	*MetaDB
+}
+
+func NewPushUserSettingGorm(db *gorm.DB) relation.PushUserSettingModelInterface {
+	return &PushUserSettingGorm{NewMetaDB(db, &relation.PushUserSettingModel{})}
+}
+
+func (p *PushUserSettingGorm) Upsert(ctx context.Context, settings []*relation.PushUserSettingModel) (err error) {
+	return utils.Wrap(p.db(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&settings).Error, "")
+}
+
+func (p *PushUserSettingGorm) FindByUserIDs(ctx context.Context, userIDs []string) (settings []*relation.PushUserSettingModel, err error) {
+	return settings, utils.Wrap(p.db(ctx).Where("user_id in ?", userIDs).Find(&settings).Error, "")
+}
@@ -0,0 +1,204 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package searchindex
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/OpenIMSDK/tools/errs"
+	"github.com/OpenIMSDK/tools/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// MongoCollection holds one document per indexed message.
+	MongoCollection = "msg_search"
+	defaultCount    = 20
+)
+
+type mongoDoc struct {
+	ConversationID string `bson:"conversation_id"`
+	Seq            int64  `bson:"seq"`
+	SendID         string `bson:"send_id"`
+	SessionType    int32  `bson:"session_type"`
+	ContentType    int32  `bson:"content_type"`
+	SendTime       int64  `bson:"send_time"`
+	// Terms are the tokens of the text joined by spaces, mongo cannot split CJK text itself.
+	Terms string `bson:"terms"`
+}
+
+type mongoHit struct {
+	ConversationID string  `bson:"conversation_id"`
+	Seq            int64   `bson:"seq"`
+	SendTime       int64   `bson:"send_time"`
+	Score          float64 `bson:"score"`
+}
+
+// cursor is the sort key of the last hit of a page. The text score of a message
+// only depends on the message and the keyword, so it does not move when other
+// messages are indexed and pages neither shift nor repeat.
+type cursor struct {
+	Score          float64 `json:"score"`
+	ConversationID string  `json:"conversationID"`
+	Seq            int64   `json:"seq"`
+}
+
+// mongoIndex searches the messages with a mongo text index, every msgtransfer and
+// msg rpc instance shares it through mongo.
+type mongoIndex struct {
+	collection *mongo.Collection
+}
+
+func NewMongoIndex(db *mongo.Database) (SearchIndex, error) {
+	collection := db.Collection(MongoCollection)
+	_, err := collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "conversation_id", Value: 1}, {Key: "seq", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "terms", Value: "text"}},
+			Options: options.Index().SetDefaultLanguage("none"),
+		},
+	})
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return &mongoIndex{collection: collection}, nil
+}
+
+func (m *mongoIndex) Index(ctx context.Context, docs []*Doc) error {
+	models := make([]mongo.WriteModel, 0, len(docs))
+	for _, doc := range docs {
+		terms := utils.Distinct(Tokenize(doc.Text))
+		if len(terms) == 0 {
+			continue
+		}
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"conversation_id": doc.ConversationID, "seq": doc.Seq}).
+			SetReplacement(&mongoDoc{
+				ConversationID: doc.ConversationID,
+				Seq:            doc.Seq,
+				SendID:         doc.SendID,
+				SessionType:    doc.SessionType,
+				ContentType:    doc.ContentType,
+				SendTime:       doc.SendTime,
+				Terms:          strings.Join(terms, " "),
+			}).
+			SetUpsert(true))
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	_, err := m.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return errs.Wrap(err)
+}
+
+func (m *mongoIndex) Delete(ctx context.Context, conversationID string, seqs []int64) error {
+	if len(seqs) == 0 {
+		return nil
+	}
+	_, err := m.collection.DeleteMany(ctx, bson.M{"conversation_id": conversationID, "seq": bson.M{"$in": seqs}})
+	return errs.Wrap(err)
+}
+
+func (m *mongoIndex) Search(ctx context.Context, query *Query) ([]*Hit, string, error) {
+	search := textSearch(query.Keyword)
+	if search == "" {
+		return nil, "", errs.ErrArgs.Wrap("keyword has no searchable term")
+	}
+	after, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	count := query.Count
+	if count <= 0 {
+		count = defaultCount
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"$text":           bson.M{"$search": search},
+			"conversation_id": bson.M{"$in": query.ConversationIDs},
+		}}},
+		{{Key: "$addFields", Value: bson.M{"score": bson.M{"$meta": "textScore"}}}},
+	}
+	if after != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"score": bson.M{"$lt": after.Score}},
+			bson.M{"score": after.Score, "conversation_id": bson.M{"$gt": after.ConversationID}},
+			bson.M{"score": after.Score, "conversation_id": after.ConversationID, "seq": bson.M{"$lt": after.Seq}},
+		}}}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "score", Value: -1}, {Key: "conversation_id", Value: 1}, {Key: "seq", Value: -1}}}},
+		bson.D{{Key: "$limit", Value: count + 1}},
+		bson.D{{Key: "$project", Value: bson.M{"conversation_id": 1, "seq": 1, "send_time": 1, "score": 1}}},
+	)
+	cur, err := m.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, "", errs.Wrap(err)
+	}
+	var docs []*mongoHit
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, "", errs.Wrap(err)
+	}
+	hits := make([]*Hit, 0, len(docs))
+	for _, doc := range docs {
+		hits = append(hits, &Hit{ConversationID: doc.ConversationID, Seq: doc.Seq, SendTime: doc.SendTime, Score: doc.Score})
+	}
+	if len(hits) <= count {
+		return hits, "", nil
+	}
+	hits = hits[:count]
+	return hits, encodeCursor(hits[count-1]), nil
+}
+
+func (m *mongoIndex) Close() error {
+	return nil
+}
+
+// textSearch quotes every term of keyword, so that a message matches only when it has all of them.
+func textSearch(keyword string) string {
+	terms := utils.Distinct(TokenizeQuery(keyword))
+	for i, term := range terms {
+		terms[i] = `"` + term + `"`
+	}
+	return strings.Join(terms, " ")
+}
+
+func encodeCursor(hit *Hit) string {
+	data, _ := json.Marshal(&cursor{Score: hit.Score, ConversationID: hit.ConversationID, Seq: hit.Seq})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (*cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errs.ErrArgs.Wrap("invalid cursor")
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, errs.ErrArgs.Wrap("invalid cursor")
+	}
+	return &c, nil
+}
@@ -0,0 +1,125 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package searchindex
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	"github.com/OpenIMSDK/protocol/sdkws"
+	"github.com/OpenIMSDK/tools/errs"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+)
+
+const (
+	TypeMongo = "mongo"
+)
+
+// Doc is the searchable part of a message.
+type Doc struct {
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+	SendID         string `json:"sendID"`
+	SessionType    int32  `json:"sessionType"`
+	ContentType    int32  `json:"contentType"`
+	SendTime       int64  `json:"sendTime"`
+	Text           string `json:"text"`
+}
+
+type Query struct {
+	Keyword string
+	// ConversationIDs limits the search to these conversations, it must not be empty.
+	ConversationIDs []string
+	// Cursor is the NextCursor of the previous page, empty for the first page.
+	Cursor string
+	Count  int
+}
+
+type Hit struct {
+	ConversationID string
+	Seq            int64
+	SendTime       int64
+	Score          float64
+}
+
+// SearchIndex is a full-text index over message text. Hits are ordered by
+// relevance, then by conversation and seq, newest first.
+type SearchIndex interface {
+	Index(ctx context.Context, docs []*Doc) error
+	Delete(ctx context.Context, conversationID string, seqs []int64) error
+	Search(ctx context.Context, query *Query) (hits []*Hit, nextCursor string, err error)
+	Close() error
+}
+
+// NewSearchIndex returns the index selected by config, or nil when search is disabled.
+func NewSearchIndex(db *mongo.Database) (SearchIndex, error) {
+	if !config.Config.SearchIndex.Enable {
+		return nil, nil
+	}
+	switch config.Config.SearchIndex.Type {
+	case TypeMongo, "":
+		return NewMongoIndex(db)
+	default:
+		return nil, errs.ErrArgs.Wrap("unknown search index type " + config.Config.SearchIndex.Type)
+	}
+}
+
+// NewDoc extracts the text of msg, it returns nil for messages that carry no searchable text.
+func NewDoc(conversationID string, msg *sdkws.MsgData) *Doc {
+	if msg == nil || msg.Seq == 0 {
+		return nil
+	}
+	text := ContentText(msg.ContentType, msg.Content)
+	if text == "" {
+		return nil
+	}
+	return &Doc{
+		ConversationID: conversationID,
+		Seq:            msg.Seq,
+		SendID:         msg.SendID,
+		SessionType:    msg.SessionType,
+		ContentType:    msg.ContentType,
+		SendTime:       msg.SendTime,
+		Text:           text,
+	}
+}
+
+// ContentText returns the human readable text of a message content.
+func ContentText(contentType int32, content []byte) string {
+	var field string
+	switch contentType {
+	case constant.Text:
+		field = "content"
+	case constant.AtText, constant.Quote, constant.AdvancedText:
+		field = "text"
+	case constant.File:
+		field = "fileName"
+	default:
+		return ""
+	}
+	var elem map[string]any
+	if err := json.Unmarshal(content, &elem); err != nil {
+		// text sent by the api is stored without the json element
+		if contentType == constant.Text {
+			return string(content)
+		}
+		return ""
+	}
+	text, _ := elem[field].(string)
+	return text
+}
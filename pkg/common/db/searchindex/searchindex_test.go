@@ -0,0 +1,86 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package searchindex
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	terms := Tokenize("Hello, World 2023 你好世界")
+	want := []string{"hello", "world", "2023", "你好", "好世", "世界", "你", "好", "世", "界"}
+	if !reflect.DeepEqual(terms, want) {
+		t.Fatalf("got %v, want %v", terms, want)
+	}
+	terms = TokenizeQuery("Hello, World 2023 你好世界")
+	want = []string{"hello", "world", "2023", "你好", "好世", "世界"}
+	if !reflect.DeepEqual(terms, want) {
+		t.Fatalf("got %v, want %v", terms, want)
+	}
+}
+
+// matches reports whether a message indexed with text has every term textSearch asks for.
+func matches(text, keyword string) bool {
+	indexed := make(map[string]bool)
+	for _, term := range Tokenize(text) {
+		indexed[term] = true
+	}
+	query := TokenizeQuery(keyword)
+	for _, term := range query {
+		if !indexed[term] {
+			return false
+		}
+	}
+	return len(query) > 0
+}
+
+func TestSingleCJKCharacter(t *testing.T) {
+	if !matches("我的猫很可爱", "猫") {
+		t.Fatal("a message must be found by one of its characters")
+	}
+	if !matches("王小明明天到", "王") {
+		t.Fatal("a message must be found by a surname")
+	}
+	if !matches("我的猫很可爱", "可爱") {
+		t.Fatal("a message must be found by a bigram")
+	}
+	if matches("我的猫很可爱", "狗") {
+		t.Fatal("a character not in the message must not match")
+	}
+}
+
+func TestTextSearch(t *testing.T) {
+	if got := textSearch("Open IM, open 你好"); got != `"open" "im" "你好"` {
+		t.Fatalf("got %s", got)
+	}
+	if got := textSearch(" ,. "); got != "" {
+		t.Fatalf("got %s", got)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	hit := &Hit{ConversationID: "si_a_b", Seq: 7, SendTime: 1, Score: 1.1 / 3}
+	c, err := decodeCursor(encodeCursor(hit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Score != hit.Score || c.ConversationID != hit.ConversationID || c.Seq != hit.Seq {
+		t.Fatalf("got %+v", c)
+	}
+	if _, err := decodeCursor("!"); err == nil {
+		t.Fatal("invalid cursor accepted")
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package searchindex
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenize splits text to be indexed into lower-case terms. Latin words and numbers
+// become one term each; CJK text has no separators, so it is split into bigrams and
+// every character is a term too, a one-character keyword has to match as well.
+func Tokenize(text string) []string {
+	return tokenize(text, true)
+}
+
+// TokenizeQuery splits a keyword into the terms a message must have. A CJK run
+// longer than one character only needs its bigrams, they imply the characters.
+func TokenizeQuery(keyword string) []string {
+	return tokenize(keyword, false)
+}
+
+func tokenize(text string, unigrams bool) []string {
+	var (
+		terms []string
+		word  []rune
+		cjk   []rune
+	)
+	flushWord := func() {
+		if len(word) > 0 {
+			terms = append(terms, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		switch len(cjk) {
+		case 0:
+		case 1:
+			terms = append(terms, string(cjk))
+		default:
+			for i := 0; i+1 < len(cjk); i++ {
+				terms = append(terms, string(cjk[i:i+2]))
+			}
+			if unigrams {
+				for _, r := range cjk {
+					terms = append(terms, string(r))
+				}
+			}
+		}
+		cjk = cjk[:0]
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return terms
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
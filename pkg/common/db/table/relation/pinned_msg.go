@@ -0,0 +1,48 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relation
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	PinnedMsgModelTableName = "pinned_msgs"
+)
+
+// PinnedMsgModel a message pinned inside a conversation, shared by all of its members.
+type PinnedMsgModel struct {
+	ConversationID string    `gorm:"column:conversation_id;primary_key;type:char(128)" json:"conversationID"`
+	Seq            int64     `gorm:"column:seq;primary_key"                            json:"seq"`
+	ClientMsgID    string    `gorm:"column:client_msg_id;type:char(64)"                json:"clientMsgID"`
+	SendID         string    `gorm:"column:send_id;type:char(64)"                      json:"sendID"`
+	SessionType    int32     `gorm:"column:session_type"                               json:"sessionType"`
+	PinnerUserID   string    `gorm:"column:pinner_user_id;type:char(64)"               json:"pinnerUserID"`
+	PinTime        time.Time `gorm:"column:pin_time"                                   json:"pinTime"`
+	Ex             string    `gorm:"column:ex;type:varchar(1024)"                      json:"ex"`
+}
+
+func (PinnedMsgModel) TableName() string {
+	return PinnedMsgModelTableName
+}
+
+type PinnedMsgModelInterface interface {
+	Create(ctx context.Context, pinnedMsgs []*PinnedMsgModel) (err error)
+	Delete(ctx context.Context, conversationID string, seqs []int64) (err error)
+	Take(ctx context.Context, conversationID string, seq int64) (pinnedMsg *PinnedMsgModel, err error)
+	Count(ctx context.Context, conversationID string) (count int64, err error)
+	FindByConversationID(ctx context.Context, conversationID string) (pinnedMsgs []*PinnedMsgModel, err error)
+}
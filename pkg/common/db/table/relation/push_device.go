@@ -0,0 +1,46 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relation
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	PushDeviceModelTableName = "push_devices"
+)
+
+// PushDeviceModel a device registered for offline push, a token belongs to one user at a time.
+type PushDeviceModel struct {
+	Provider   string    `gorm:"column:provider;primary_key;type:varchar(32)"               json:"provider"`
+	Token      string    `gorm:"column:token;primary_key;type:varchar(255)"                 json:"token"`
+	UserID     string    `gorm:"column:user_id;type:char(64);index:idx_push_device_user_id" json:"userID"`
+	PlatformID int32     `gorm:"column:platform_id"                                         json:"platformID"`
+	AppVersion string    `gorm:"column:app_version;type:varchar(32)"                        json:"appVersion"`
+	Locale     string    `gorm:"column:locale;type:varchar(32)"                             json:"locale"`
+	UpdateTime time.Time `gorm:"column:update_time"                                         json:"updateTime"`
+}
+
+func (PushDeviceModel) TableName() string {
+	return PushDeviceModelTableName
+}
+
+type PushDeviceModelInterface interface {
+	Upsert(ctx context.Context, devices []*PushDeviceModel) (err error)
+	Delete(ctx context.Context, provider string, tokens []string) (err error)
+	DeleteByUserPlatform(ctx context.Context, userID string, platformID int32) (err error)
+	FindByUserIDs(ctx context.Context, userIDs []string) (devices []*PushDeviceModel, err error)
+}
@@ -0,0 +1,65 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relation
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	PushTemplateModelTableName    = "push_templates"
+	PushUserSettingModelTableName = "push_user_settings"
+)
+
+// PushTemplateHidden is the content type of the template pushed to the users hiding the content.
+const PushTemplateHidden = 0
+
+// PushTemplateModel the offline push title and content of a content type in a locale,
+// {senderNickname}, {groupName} and {preview} are replaced with the message values.
+type PushTemplateModel struct {
+	ContentType int32     `gorm:"column:content_type;primary_key"            json:"contentType"`
+	Locale      string    `gorm:"column:locale;primary_key;type:varchar(32)" json:"locale"`
+	Title       string    `gorm:"column:title;type:varchar(255)"             json:"title"`
+	Content     string    `gorm:"column:content;type:varchar(1024)"          json:"content"`
+	UpdateTime  time.Time `gorm:"column:update_time"                         json:"updateTime"`
+}
+
+func (PushTemplateModel) TableName() string {
+	return PushTemplateModelTableName
+}
+
+type PushTemplateModelInterface interface {
+	Upsert(ctx context.Context, templates []*PushTemplateModel) (err error)
+	Delete(ctx context.Context, contentType int32, locale string) (err error)
+	FindAll(ctx context.Context) (templates []*PushTemplateModel, err error)
+}
+
+// PushUserSettingModel how the offline pushes of a user are rendered.
+type PushUserSettingModel struct {
+	UserID      string    `gorm:"column:user_id;primary_key;type:char(64)" json:"userID"`
+	Locale      string    `gorm:"column:locale;type:varchar(32)"           json:"locale"`
+	HideContent bool      `gorm:"column:hide_content"                      json:"hideContent"`
+	UpdateTime  time.Time `gorm:"column:update_time"                       json:"updateTime"`
+}
+
+func (PushUserSettingModel) TableName() string {
+	return PushUserSettingModelTableName
+}
+
+type PushUserSettingModelInterface interface {
+	Upsert(ctx context.Context, settings []*PushUserSettingModel) (err error)
+	FindByUserIDs(ctx context.Context, userIDs []string) (settings []*PushUserSettingModel, err error)
+}
@@ -46,6 +46,15 @@ type RevokeModel struct {
 	Time     int64  `bson:"time"`
 }
 
+// EditModel keeps the content a message had before an edit replaced it.
+type EditModel struct {
+	UserID      string `bson:"user_id"`
+	Nickname    string `bson:"nickname"`
+	ContentType int32  `bson:"content_type"`
+	Content     string `bson:"content"`
+	Time        int64  `bson:"time"`
+}
+
 type OfflinePushModel struct {
 	Title         string `bson:"title"`
 	Desc          string `bson:"desc"`
@@ -80,10 +89,26 @@ type MsgDataModel struct {
 }
 
 type MsgInfoModel struct {
-	Msg     *MsgDataModel `bson:"msg"`
-	Revoke  *RevokeModel  `bson:"revoke"`
-	DelList []string      `bson:"del_list"`
-	IsRead  bool          `bson:"is_read"`
+	Msg    *MsgDataModel `bson:"msg"`
+	Revoke *RevokeModel  `bson:"revoke"`
+	Edits  []*EditModel  `bson:"edits"`
+	// Reactions maps a reaction type to the users who reacted with it.
+	Reactions map[string][]string `bson:"reactions"`
+	Thread    *ThreadModel        `bson:"thread"`
+	DelList   []string            `bson:"del_list"`
+	IsRead    bool                `bson:"is_read"`
+	// ReadList group members who have read the message, used by group read receipts.
+	ReadList []string `bson:"read_list"`
+}
+
+// ThreadModel summary of the replies to a thread root message.
+type ThreadModel struct {
+	ThreadConversationID string `bson:"thread_conversation_id" json:"threadConversationID"`
+	ReplyCount           int64  `bson:"reply_count"            json:"replyCount"`
+	LastReplySeq         int64  `bson:"last_reply_seq"         json:"lastReplySeq"`
+	LastReplySendID      string `bson:"last_reply_send_id"     json:"lastReplySendID"`
+	LastReplyClientMsgID string `bson:"last_reply_client_msg_id" json:"lastReplyClientMsgID"`
+	LastReplyTime        int64  `bson:"last_reply_time"        json:"lastReplyTime"`
 }
 
 type UserCount struct {
@@ -102,6 +127,12 @@ type MsgDocModelInterface interface {
 	UpdateMsg(ctx context.Context, docID string, index int64, key string, value any) (*mongo.UpdateResult, error)
 	PushUnique(ctx context.Context, docID string, index int64, key string, value any) (*mongo.UpdateResult, error)
 	UpdateMsgContent(ctx context.Context, docID string, index int64, msg []byte) error
+	EditMsg(ctx context.Context, docID string, index int64, content string, edit *EditModel) (*mongo.UpdateResult, error)
+	GetMsgReactions(ctx context.Context, docID string, index int64) (map[string][]string, error)
+	AddMsgReaction(ctx context.Context, docID string, index int64, reactionType string, userID string) (*mongo.UpdateResult, error)
+	RemoveMsgReaction(ctx context.Context, docID string, index int64, reactionType string, userID string) (*mongo.UpdateResult, error)
+	IncrMsgThreadReply(ctx context.Context, docID string, index int64, threadConversationID string) (*ThreadModel, error)
+	UpdateMsgThreadLastReply(ctx context.Context, docID string, index int64, thread *ThreadModel) (*mongo.UpdateResult, error)
 	IsExistDocID(ctx context.Context, docID string) (bool, error)
 	FindOneByDocID(ctx context.Context, docID string) (*MsgDocModel, error)
 	GetMsgBySeqIndexIn1Doc(ctx context.Context, userID, docID string, seqs []int64) ([]*MsgInfoModel, error)
@@ -111,6 +142,10 @@ type MsgDocModelInterface interface {
 	GetMsgDocModelByIndex(ctx context.Context, conversationID string, index, sort int64) (*MsgDocModel, error)
 	DeleteMsgsInOneDocByIndex(ctx context.Context, docID string, indexes []int) error
 	MarkSingleChatMsgsAsRead(ctx context.Context, userID string, docID string, indexes []int64) error
+	MarkGroupMsgsAsRead(ctx context.Context, userID string, docID string, indexes []int64) error
+	GetPersistedMsgIndexes(ctx context.Context, docID string, indexes []int64) ([]int64, error)
+	GetMsgReadList(ctx context.Context, docID string, index int64) (sendID string, readList []string, err error)
+	GetMsgsReadCount(ctx context.Context, docID string, indexes []int64) (map[int64]int64, error)
 	SearchMessage(ctx context.Context, req *msg.SearchMessageReq) (int32, []*MsgInfoModel, error)
 	RangeUserSendCount(
 		ctx context.Context,
@@ -0,0 +1,69 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unrelation
+
+import "context"
+
+const (
+	ScheduledMsg = "scheduled_msg"
+)
+
+// Status of a scheduled message.
+const (
+	ScheduledMsgStatusPending  = 0
+	ScheduledMsgStatusSending  = 1
+	ScheduledMsgStatusSent     = 2
+	ScheduledMsgStatusCanceled = 3
+	ScheduledMsgStatusFailed   = 4
+)
+
+// ScheduledMsgModel a message waiting to be delivered at SendTime.
+type ScheduledMsgModel struct {
+	ScheduleID  string `bson:"schedule_id"`
+	SendID      string `bson:"send_id"`
+	RecvID      string `bson:"recv_id"`
+	GroupID     string `bson:"group_id"`
+	SessionType int32  `bson:"session_type"`
+	ClientMsgID string `bson:"client_msg_id"`
+	SendTime    int64  `bson:"send_time"`
+	Status      int32  `bson:"status"`
+	MsgData     []byte `bson:"msg_data"`
+	Ex          string `bson:"ex"`
+	CreateTime  int64  `bson:"create_time"`
+	UpdateTime  int64  `bson:"update_time"`
+}
+
+func (ScheduledMsgModel) TableName() string {
+	return ScheduledMsg
+}
+
+// ScheduledMsgModelInterface Operation interface of scheduled message mongodb.
+type ScheduledMsgModelInterface interface {
+	// Create save a pending scheduled message.
+	Create(ctx context.Context, msg *ScheduledMsgModel) error
+	// Take get a scheduled message by id.
+	Take(ctx context.Context, scheduleID string) (*ScheduledMsgModel, error)
+	// FindBySendID page through the scheduled messages of a sender, an empty status list means all.
+	FindBySendID(ctx context.Context, sendID string, status []int32, pageNumber, showNumber int32) (total int64, msgs []*ScheduledMsgModel, err error)
+	// UpdatePending update a message only if it is still pending, returns whether it matched.
+	UpdatePending(ctx context.Context, scheduleID string, update map[string]any) (bool, error)
+	// ClaimDue mark the earliest pending message whose send time has passed, or a message left
+	// sending since before staleBefore, as sending, nil if there is none. The update time of the
+	// returned message identifies the claim.
+	ClaimDue(ctx context.Context, now int64, staleBefore int64) (*ScheduledMsgModel, error)
+	// UpdateStatus set the final status of a claimed message, only if claimTime is still its latest
+	// claim, returns whether it matched.
+	UpdateStatus(ctx context.Context, scheduleID string, claimTime int64, status int32, ex string) (bool, error)
+}
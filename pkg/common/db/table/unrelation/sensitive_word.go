@@ -0,0 +1,46 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unrelation
+
+import "context"
+
+const (
+	SensitiveWord = "sensitive_word"
+)
+
+// SensitiveWordModel a word filtered by the sensitive word interceptor, the
+// action applied to it is configured per category.
+type SensitiveWordModel struct {
+	Word       string `bson:"word"`
+	Category   string `bson:"category"`
+	CreateTime int64  `bson:"create_time"`
+	Ex         string `bson:"ex"`
+}
+
+func (SensitiveWordModel) TableName() string {
+	return SensitiveWord
+}
+
+// SensitiveWordModelInterface Operation interface of sensitive word mongodb.
+type SensitiveWordModelInterface interface {
+	// Upsert add words, the category of existing words is replaced.
+	Upsert(ctx context.Context, words []*SensitiveWordModel) error
+	// Delete remove words.
+	Delete(ctx context.Context, words []string) error
+	// Search page through words containing keyword, an empty category means all.
+	Search(ctx context.Context, keyword string, category string, pageNumber, showNumber int32) (total int64, words []*SensitiveWordModel, err error)
+	// FindAll get the whole dictionary.
+	FindAll(ctx context.Context) ([]*SensitiveWordModel, error)
+}
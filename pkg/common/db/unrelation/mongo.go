@@ -109,6 +109,23 @@ func (m *Mongo) CreateSuperGroupIndex() error {
 	return nil
 }
 
+func (m *Mongo) CreateScheduledMsgIndex() error {
+	if err := m.createMongoIndex(unrelation.ScheduledMsg, true, "schedule_id"); err != nil {
+		return err
+	}
+	if err := m.createMongoIndex(unrelation.ScheduledMsg, false, "status", "send_time"); err != nil {
+		return err
+	}
+	if err := m.createMongoIndex(unrelation.ScheduledMsg, false, "send_id", "send_time"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *Mongo) CreateSensitiveWordIndex() error {
+	return m.createMongoIndex(unrelation.SensitiveWord, true, "word")
+}
+
 func (m *Mongo) createMongoIndex(collection string, isUnique bool, keys ...string) error {
 	db := m.db.Database(config.Config.Mongo.Database).Collection(collection)
 	opts := options.CreateIndexes().SetMaxTime(10 * time.Second)
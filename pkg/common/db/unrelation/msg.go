@@ -123,6 +123,137 @@ func (m *MsgMongoDriver) UpdateMsgContent(ctx context.Context, docID string, ind
 	return nil
 }
 
+// EditMsg replaces the content of a persisted msg and appends its previous revision to edits.
+func (m *MsgMongoDriver) EditMsg(
+	ctx context.Context,
+	docID string,
+	index int64,
+	content string,
+	edit *table.EditModel,
+) (*mongo.UpdateResult, error) {
+	filter := bson.M{
+		"doc_id":                          docID,
+		fmt.Sprintf("msgs.%d.msg", index): bson.M{"$ne": nil},
+		fmt.Sprintf("msgs.%d.msg.content", index): edit.Content,
+	}
+	update := bson.M{
+		"$set":  bson.M{fmt.Sprintf("msgs.%d.msg.content", index): content},
+		"$push": bson.M{fmt.Sprintf("msgs.%d.edits", index): edit},
+	}
+	res, err := m.MsgCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return nil, utils.Wrap(err, "")
+	}
+	return res, nil
+}
+
+// IncrMsgThreadReply counts one more reply to the root message at index and returns the
+// updated thread summary, nil if the root message is not in the doc.
+func (m *MsgMongoDriver) IncrMsgThreadReply(ctx context.Context, docID string, index int64, threadConversationID string) (*table.ThreadModel, error) {
+	msgKey := fmt.Sprintf("msgs.%d.msg", index)
+	threadKey := fmt.Sprintf("msgs.%d.thread", index)
+	// $inc can not create a field inside a null thread
+	_, err := m.MsgCollection.UpdateOne(ctx,
+		bson.M{"doc_id": docID, msgKey: bson.M{"$ne": nil}, threadKey: nil},
+		bson.M{"$set": bson.M{threadKey: &table.ThreadModel{ThreadConversationID: threadConversationID}}},
+	)
+	if err != nil {
+		return nil, utils.Wrap(err, "")
+	}
+	opts := options.FindOneAndUpdate().
+		SetProjection(bson.M{"msgs": bson.M{"$slice": bson.A{index, 1}}}).
+		SetReturnDocument(options.After)
+	var doc table.MsgDocModel
+	err = m.MsgCollection.FindOneAndUpdate(ctx,
+		bson.M{"doc_id": docID, msgKey: bson.M{"$ne": nil}},
+		bson.M{"$inc": bson.M{threadKey + ".reply_count": 1}},
+		opts,
+	).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, utils.Wrap(err, "")
+	}
+	if len(doc.Msg) == 0 || doc.Msg[0] == nil || doc.Msg[0].Thread == nil {
+		return nil, errs.ErrInternalServer.Wrap("thread summary is missing")
+	}
+	return doc.Msg[0].Thread, nil
+}
+
+// UpdateMsgThreadLastReply records the last reply of a thread unless a newer reply has already been recorded.
+func (m *MsgMongoDriver) UpdateMsgThreadLastReply(ctx context.Context, docID string, index int64, thread *table.ThreadModel) (*mongo.UpdateResult, error) {
+	threadKey := fmt.Sprintf("msgs.%d.thread", index)
+	filter := bson.M{
+		"doc_id":                      docID,
+		threadKey + ".last_reply_seq": bson.M{"$not": bson.M{"$gte": thread.LastReplySeq}},
+	}
+	update := bson.M{"$set": bson.M{
+		threadKey + ".last_reply_seq":           thread.LastReplySeq,
+		threadKey + ".last_reply_send_id":       thread.LastReplySendID,
+		threadKey + ".last_reply_client_msg_id": thread.LastReplyClientMsgID,
+		threadKey + ".last_reply_time":          thread.LastReplyTime,
+	}}
+	res, err := m.MsgCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return nil, utils.Wrap(err, "")
+	}
+	return res, nil
+}
+
+func (m *MsgMongoDriver) GetMsgReactions(ctx context.Context, docID string, index int64) (map[string][]string, error) {
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.M{"doc_id": docID}}},
+		{{"$project", bson.M{"_id": 0, "msg": bson.M{"$arrayElemAt": bson.A{"$msgs", index}}}}},
+		{{"$project", bson.M{"reactions": "$msg.reactions"}}},
+	}
+	cur, err := m.MsgCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	defer cur.Close(ctx)
+	var res []struct {
+		Reactions map[string][]string `bson:"reactions"`
+	}
+	if err := cur.All(ctx, &res); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	if len(res) == 0 {
+		return nil, nil
+	}
+	return res[0].Reactions, nil
+}
+
+func (m *MsgMongoDriver) AddMsgReaction(
+	ctx context.Context,
+	docID string,
+	index int64,
+	reactionType string,
+	userID string,
+) (*mongo.UpdateResult, error) {
+	update := bson.M{"$addToSet": bson.M{fmt.Sprintf("msgs.%d.reactions.%s", index, reactionType): userID}}
+	res, err := m.MsgCollection.UpdateOne(ctx, bson.M{"doc_id": docID}, update)
+	if err != nil {
+		return nil, utils.Wrap(err, "")
+	}
+	return res, nil
+}
+
+func (m *MsgMongoDriver) RemoveMsgReaction(
+	ctx context.Context,
+	docID string,
+	index int64,
+	reactionType string,
+	userID string,
+) (*mongo.UpdateResult, error) {
+	update := bson.M{"$pull": bson.M{fmt.Sprintf("msgs.%d.reactions.%s", index, reactionType): userID}}
+	res, err := m.MsgCollection.UpdateOne(ctx, bson.M{"doc_id": docID}, update)
+	if err != nil {
+		return nil, utils.Wrap(err, "")
+	}
+	return res, nil
+}
+
 func (m *MsgMongoDriver) UpdateMsgStatusByIndexInOneDoc(
 	ctx context.Context,
 	docID string,
@@ -285,6 +416,7 @@ func (m *MsgMongoDriver) GetMsgBySeqIndexIn1Doc(
 		{
 			{"$project", bson.D{
 				{"msgs.del_list", 0},
+				{"msgs.read_list", 0},
 			}},
 		},
 	}
@@ -333,6 +465,8 @@ func (m *MsgMongoDriver) GetMsgBySeqIndexIn1Doc(
 			}
 			msg.Msg.ContentType = constant.MsgRevokeNotification
 			msg.Msg.Content = string(content)
+		} else if msg.Thread != nil {
+			msg.Msg.AttachedInfo = attachThreadSummary(msg.Msg.AttachedInfo, msg.Thread)
 		}
 		msgs = append(msgs, msg)
 	}
@@ -375,6 +509,139 @@ func (m *MsgMongoDriver) MarkSingleChatMsgsAsRead(
 	return err
 }
 
+// MarkGroupMsgsAsRead adds userID to the read list of the messages not sent by userID.
+func (m *MsgMongoDriver) MarkGroupMsgsAsRead(
+	ctx context.Context,
+	userID string,
+	docID string,
+	indexes []int64,
+) error {
+	updates := make([]mongo.WriteModel, 0, len(indexes))
+	for _, index := range indexes {
+		filter := bson.M{
+			"doc_id":                          docID,
+			fmt.Sprintf("msgs.%d.msg", index): bson.M{"$ne": nil},
+			fmt.Sprintf("msgs.%d.msg.send_id", index): bson.M{
+				"$ne": userID,
+			},
+		}
+		update := bson.M{
+			"$addToSet": bson.M{
+				fmt.Sprintf("msgs.%d.read_list", index): userID,
+			},
+		}
+		updates = append(updates, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update))
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	_, err := m.MsgCollection.BulkWrite(ctx, updates, options.BulkWrite().SetOrdered(false))
+	return errs.Wrap(err)
+}
+
+func (m *MsgMongoDriver) GetMsgReadList(ctx context.Context, docID string, index int64) (string, []string, error) {
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.M{"doc_id": docID}}},
+		{{"$project", bson.M{"_id": 0, "msg": bson.M{"$arrayElemAt": bson.A{"$msgs", index}}}}},
+		{{"$project", bson.M{"send_id": "$msg.msg.send_id", "read_list": "$msg.read_list"}}},
+	}
+	cur, err := m.MsgCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return "", nil, errs.Wrap(err)
+	}
+	defer cur.Close(ctx)
+	var res []struct {
+		SendID   string   `bson:"send_id"`
+		ReadList []string `bson:"read_list"`
+	}
+	if err := cur.All(ctx, &res); err != nil {
+		return "", nil, errs.Wrap(err)
+	}
+	if len(res) == 0 {
+		return "", nil, errs.Wrap(mongo.ErrNoDocuments)
+	}
+	return res[0].SendID, res[0].ReadList, nil
+}
+
+// GetPersistedMsgIndexes returns the indexes whose message has been written to the doc.
+func (m *MsgMongoDriver) GetPersistedMsgIndexes(ctx context.Context, docID string, indexes []int64) ([]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.M{"doc_id": docID}}},
+		{{"$project", bson.M{
+			"_id": 0,
+			"persisted": bson.M{"$map": bson.M{
+				"input": indexes,
+				"as":    "index",
+				"in": bson.M{"$let": bson.M{
+					"vars": bson.M{"currentMsg": bson.M{"$arrayElemAt": bson.A{"$msgs", "$$index"}}},
+					// objects sort after null and missing values
+					"in": bson.M{"$gt": bson.A{"$$currentMsg.msg", nil}},
+				}},
+			}},
+		}}},
+	}
+	cur, err := m.MsgCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	defer cur.Close(ctx)
+	var res []struct {
+		Persisted []bool `bson:"persisted"`
+	}
+	if err := cur.All(ctx, &res); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	if len(res) == 0 {
+		return nil, nil
+	}
+	persisted := make([]int64, 0, len(indexes))
+	for i, ok := range res[0].Persisted {
+		if ok && i < len(indexes) {
+			persisted = append(persisted, indexes[i])
+		}
+	}
+	return persisted, nil
+}
+
+// GetMsgsReadCount returns the read list size of each index.
+func (m *MsgMongoDriver) GetMsgsReadCount(ctx context.Context, docID string, indexes []int64) (map[int64]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.M{"doc_id": docID}}},
+		{{"$project", bson.M{
+			"_id": 0,
+			"counts": bson.M{"$map": bson.M{
+				"input": indexes,
+				"as":    "index",
+				"in": bson.M{"$let": bson.M{
+					"vars": bson.M{"currentMsg": bson.M{"$arrayElemAt": bson.A{"$msgs", "$$index"}}},
+					"in":   bson.M{"$size": bson.M{"$ifNull": bson.A{"$$currentMsg.read_list", bson.A{}}}},
+				}},
+			}},
+		}}},
+	}
+	cur, err := m.MsgCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	defer cur.Close(ctx)
+	var res []struct {
+		Counts []int64 `bson:"counts"`
+	}
+	if err := cur.All(ctx, &res); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	counts := make(map[int64]int64, len(indexes))
+	if len(res) == 0 {
+		return counts, nil
+	}
+	for i, count := range res[0].Counts {
+		if i < len(indexes) {
+			counts[indexes[i]] = count
+		}
+	}
+	return counts, nil
+}
+
 // RangeUserSendCount
 // db.msg.aggregate([
 //
@@ -1216,3 +1483,27 @@ func (m *MsgMongoDriver) searchMessage(ctx context.Context, req *msg.SearchMessa
 	}
 	return n, msgs, nil
 }
+
+// attachThreadSummary adds the thread summary to the attached info json of a root message,
+// leaving attached info that is not a json object untouched.
+func attachThreadSummary(attachedInfo string, thread *table.ThreadModel) string {
+	info := make(map[string]json.RawMessage)
+	if attachedInfo != "" {
+		if err := json.Unmarshal([]byte(attachedInfo), &info); err != nil {
+			return attachedInfo
+		}
+		if info == nil {
+			info = make(map[string]json.RawMessage)
+		}
+	}
+	summary, err := json.Marshal(thread)
+	if err != nil {
+		return attachedInfo
+	}
+	info["threadSummary"] = summary
+	data, err := json.Marshal(info)
+	if err != nil {
+		return attachedInfo
+	}
+	return string(data)
+}
@@ -0,0 +1,131 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unrelation
+
+import (
+	"context"
+	"time"
+
+	"github.com/OpenIMSDK/tools/errs"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/table/unrelation"
+)
+
+func NewScheduledMsgMongoDriver(database *mongo.Database) unrelation.ScheduledMsgModelInterface {
+	return &ScheduledMsgMongoDriver{
+		collection: database.Collection(unrelation.ScheduledMsg),
+	}
+}
+
+type ScheduledMsgMongoDriver struct {
+	collection *mongo.Collection
+}
+
+func (s *ScheduledMsgMongoDriver) Create(ctx context.Context, msg *unrelation.ScheduledMsgModel) error {
+	_, err := s.collection.InsertOne(ctx, msg)
+	return errs.Wrap(err)
+}
+
+func (s *ScheduledMsgMongoDriver) Take(ctx context.Context, scheduleID string) (*unrelation.ScheduledMsgModel, error) {
+	var msg unrelation.ScheduledMsgModel
+	if err := s.collection.FindOne(ctx, bson.M{"schedule_id": scheduleID}).Decode(&msg); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return &msg, nil
+}
+
+func (s *ScheduledMsgMongoDriver) FindBySendID(
+	ctx context.Context,
+	sendID string,
+	status []int32,
+	pageNumber, showNumber int32,
+) (int64, []*unrelation.ScheduledMsgModel, error) {
+	filter := bson.M{"send_id": sendID}
+	if len(status) > 0 {
+		filter["status"] = bson.M{"$in": status}
+	}
+	total, err := s.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, nil, errs.Wrap(err)
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "send_time", Value: 1}})
+	if pageNumber > 0 && showNumber > 0 {
+		opts.SetSkip(int64(pageNumber-1) * int64(showNumber)).SetLimit(int64(showNumber))
+	}
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, nil, errs.Wrap(err)
+	}
+	var msgs []*unrelation.ScheduledMsgModel
+	if err := cursor.All(ctx, &msgs); err != nil {
+		return 0, nil, errs.Wrap(err)
+	}
+	return total, msgs, nil
+}
+
+func (s *ScheduledMsgMongoDriver) UpdatePending(ctx context.Context, scheduleID string, update map[string]any) (bool, error) {
+	set := bson.M{"update_time": time.Now().UnixMilli()}
+	for k, v := range update {
+		set[k] = v
+	}
+	res, err := s.collection.UpdateOne(
+		ctx,
+		bson.M{"schedule_id": scheduleID, "status": unrelation.ScheduledMsgStatusPending},
+		bson.M{"$set": set},
+	)
+	if err != nil {
+		return false, errs.Wrap(err)
+	}
+	return res.MatchedCount > 0, nil
+}
+
+func (s *ScheduledMsgMongoDriver) ClaimDue(ctx context.Context, now int64, staleBefore int64) (*unrelation.ScheduledMsgModel, error) {
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "send_time", Value: 1}}).
+		SetReturnDocument(options.After)
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": unrelation.ScheduledMsgStatusPending, "send_time": bson.M{"$lte": now}},
+		bson.M{"status": unrelation.ScheduledMsgStatusSending, "update_time": bson.M{"$lt": staleBefore}},
+	}}
+	var msg unrelation.ScheduledMsgModel
+	err := s.collection.FindOneAndUpdate(
+		ctx,
+		filter,
+		bson.M{"$set": bson.M{"status": unrelation.ScheduledMsgStatusSending, "update_time": time.Now().UnixMilli()}},
+		opts,
+	).Decode(&msg)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return &msg, nil
+}
+
+func (s *ScheduledMsgMongoDriver) UpdateStatus(ctx context.Context, scheduleID string, claimTime int64, status int32, ex string) (bool, error) {
+	res, err := s.collection.UpdateOne(
+		ctx,
+		bson.M{"schedule_id": scheduleID, "status": unrelation.ScheduledMsgStatusSending, "update_time": claimTime},
+		bson.M{"$set": bson.M{"status": status, "ex": ex, "update_time": time.Now().UnixMilli()}},
+	)
+	if err != nil {
+		return false, errs.Wrap(err)
+	}
+	return res.MatchedCount > 0, nil
+}
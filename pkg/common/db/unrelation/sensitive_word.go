@@ -0,0 +1,107 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unrelation
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/OpenIMSDK/tools/errs"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/table/unrelation"
+)
+
+func NewSensitiveWordMongoDriver(database *mongo.Database) unrelation.SensitiveWordModelInterface {
+	return &SensitiveWordMongoDriver{
+		collection: database.Collection(unrelation.SensitiveWord),
+	}
+}
+
+type SensitiveWordMongoDriver struct {
+	collection *mongo.Collection
+}
+
+func (s *SensitiveWordMongoDriver) Upsert(ctx context.Context, words []*unrelation.SensitiveWordModel) error {
+	if len(words) == 0 {
+		return nil
+	}
+	models := make([]mongo.WriteModel, 0, len(words))
+	for _, word := range words {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"word": word.Word}).
+			SetUpdate(bson.M{
+				"$set":         bson.M{"category": word.Category, "ex": word.Ex},
+				"$setOnInsert": bson.M{"create_time": word.CreateTime},
+			}).
+			SetUpsert(true))
+	}
+	_, err := s.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return errs.Wrap(err)
+}
+
+func (s *SensitiveWordMongoDriver) Delete(ctx context.Context, words []string) error {
+	if len(words) == 0 {
+		return nil
+	}
+	_, err := s.collection.DeleteMany(ctx, bson.M{"word": bson.M{"$in": words}})
+	return errs.Wrap(err)
+}
+
+func (s *SensitiveWordMongoDriver) Search(
+	ctx context.Context,
+	keyword string,
+	category string,
+	pageNumber, showNumber int32,
+) (int64, []*unrelation.SensitiveWordModel, error) {
+	filter := bson.M{}
+	if keyword != "" {
+		filter["word"] = bson.M{"$regex": regexp.QuoteMeta(keyword), "$options": "i"}
+	}
+	if category != "" {
+		filter["category"] = category
+	}
+	total, err := s.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, nil, errs.Wrap(err)
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "create_time", Value: -1}})
+	if pageNumber > 0 && showNumber > 0 {
+		opts.SetSkip(int64(pageNumber-1) * int64(showNumber)).SetLimit(int64(showNumber))
+	}
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, nil, errs.Wrap(err)
+	}
+	var words []*unrelation.SensitiveWordModel
+	if err := cursor.All(ctx, &words); err != nil {
+		return 0, nil, errs.Wrap(err)
+	}
+	return total, words, nil
+}
+
+func (s *SensitiveWordMongoDriver) FindAll(ctx context.Context) ([]*unrelation.SensitiveWordModel, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	var words []*unrelation.SensitiveWordModel
+	if err := cursor.All(ctx, &words); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return words, nil
+}
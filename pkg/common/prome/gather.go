@@ -49,6 +49,11 @@ var (
 	GroupChatMsgRecvSuccessCounter          prometheus.Counter
 	WorkSuperGroupChatMsgRecvSuccessCounter prometheus.Counter
 	OnlineUserGauge                         prometheus.Gauge
+	MsgGatewayConnGauge                     prometheus.Gauge
+	MsgGatewayPingCounter                   prometheus.Counter
+	MsgGatewayReapedConnCounter             prometheus.Counter
+	MsgGatewayRateLimitedCounter            prometheus.Counter
+	MsgGatewayAbuserDisconnectCounter       prometheus.Counter
 
 	// msg-msg.
 	SingleChatMsgProcessSuccessCounter         prometheus.Counter
@@ -59,9 +64,11 @@ var (
 	WorkSuperGroupChatMsgProcessFailedCounter  prometheus.Counter
 
 	// msg-push.
-	MsgOnlinePushSuccessCounter  prometheus.Counter
-	MsgOfflinePushSuccessCounter prometheus.Counter
-	MsgOfflinePushFailedCounter  prometheus.Counter
+	MsgOnlinePushSuccessCounter     prometheus.Counter
+	MsgOfflinePushSuccessCounter    prometheus.Counter
+	MsgOfflinePushFailedCounter     prometheus.Counter
+	MsgOfflinePushRetryCounter      prometheus.Counter
+	MsgOfflinePushDeadLetterCounter prometheus.Counter
 	// api.
 	ApiRequestCounter        prometheus.Counter
 	ApiRequestSuccessCounter prometheus.Counter
@@ -359,6 +366,56 @@ func NewOnlineUserGauges() {
 	})
 }
 
+func NewMsgGatewayConnGauge() {
+	if MsgGatewayConnGauge != nil {
+		return
+	}
+	MsgGatewayConnGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "msg_gateway_conn_num",
+		Help: "The number of msg gateway connections",
+	})
+}
+
+func NewMsgGatewayPingCounter() {
+	if MsgGatewayPingCounter != nil {
+		return
+	}
+	MsgGatewayPingCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "msg_gateway_ping",
+		Help: "The number of pings sent by msg gateway",
+	})
+}
+
+func NewMsgGatewayReapedConnCounter() {
+	if MsgGatewayReapedConnCounter != nil {
+		return
+	}
+	MsgGatewayReapedConnCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "msg_gateway_reaped_conn",
+		Help: "The number of stale msg gateway connections closed by the reaper",
+	})
+}
+
+func NewMsgGatewayRateLimitedCounter() {
+	if MsgGatewayRateLimitedCounter != nil {
+		return
+	}
+	MsgGatewayRateLimitedCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "msg_gateway_rate_limited",
+		Help: "The number of msg gateway requests rejected by rate limiting",
+	})
+}
+
+func NewMsgGatewayAbuserDisconnectCounter() {
+	if MsgGatewayAbuserDisconnectCounter != nil {
+		return
+	}
+	MsgGatewayAbuserDisconnectCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "msg_gateway_abuser_disconnect",
+		Help: "The number of msg gateway connections closed for exceeding rate limits",
+	})
+}
+
 func NewSingleChatMsgProcessSuccessCounter() {
 	if SingleChatMsgProcessSuccessCounter != nil {
 		return
@@ -449,6 +506,26 @@ func NewMsgOfflinePushFailedCounter() {
 	})
 }
 
+func NewMsgOfflinePushRetryCounter() {
+	if MsgOfflinePushRetryCounter != nil {
+		return
+	}
+	MsgOfflinePushRetryCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "msg_offline_push_retry",
+		Help: "The number of msg offline push retries",
+	})
+}
+
+func NewMsgOfflinePushDeadLetterCounter() {
+	if MsgOfflinePushDeadLetterCounter != nil {
+		return
+	}
+	MsgOfflinePushDeadLetterCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "msg_offline_push_dead_letter",
+		Help: "The number of msg offline pushes given up after every retry failed",
+	})
+}
+
 func NewConversationCreateSuccessCounter() {
 	if ConversationCreateSuccessCounter != nil {
 		return
@@ -0,0 +1,120 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sensitive
+
+import "github.com/OpenIMSDK/tools/errs"
+
+// ErrSensitiveWord is returned for messages containing a word whose action is block.
+var ErrSensitiveWord = errs.NewCodeError(1410, "MsgContainsSensitiveWord")
+
+type Action string
+
+const (
+	ActionBlock Action = "block"
+	ActionMask  Action = "mask"
+	ActionFlag  Action = "flag"
+)
+
+// severity orders actions, the most severe action of all hits applies to the text.
+func (a Action) severity() int {
+	switch a {
+	case ActionBlock:
+		return 3
+	case ActionMask:
+		return 2
+	case ActionFlag:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (a Action) Valid() bool {
+	return a.severity() > 0
+}
+
+type Word struct {
+	Word     string
+	Category string
+}
+
+type Result struct {
+	// Action the most severe action of the hits, empty when nothing matched.
+	Action Action
+	// Text the input with the words of mask categories replaced by '*'.
+	Text string
+	Hits []*Word
+}
+
+// Filter applies the action of each word category to the dictionary words found in text.
+type Filter struct {
+	matcher       *Matcher
+	words         []*Word
+	actions       map[string]Action
+	defaultAction Action
+}
+
+// NewFilter builds a filter, words of categories missing in actions use defaultAction.
+func NewFilter(words []*Word, actions map[string]Action, defaultAction Action) *Filter {
+	dict := make([]string, 0, len(words))
+	for _, word := range words {
+		dict = append(dict, word.Word)
+	}
+	if !defaultAction.Valid() {
+		defaultAction = ActionBlock
+	}
+	return &Filter{
+		matcher:       NewMatcher(dict),
+		words:         words,
+		actions:       actions,
+		defaultAction: defaultAction,
+	}
+}
+
+func (f *Filter) action(category string) Action {
+	if action, ok := f.actions[category]; ok && action.Valid() {
+		return action
+	}
+	return f.defaultAction
+}
+
+func (f *Filter) Check(text string) *Result {
+	res := &Result{Text: text}
+	hits := f.matcher.Match(text)
+	if len(hits) == 0 {
+		return res
+	}
+	var runes []rune
+	for _, hit := range hits {
+		word := f.words[hit.Word]
+		action := f.action(word.Category)
+		if action.severity() > res.Action.severity() {
+			res.Action = action
+		}
+		res.Hits = append(res.Hits, word)
+		if action == ActionMask {
+			if runes == nil {
+				runes = []rune(text)
+			}
+			for i := hit.Start; i < hit.End; i++ {
+				runes[i] = '*'
+			}
+		}
+	}
+	if runes != nil {
+		res.Text = string(runes)
+	}
+	return res
+}
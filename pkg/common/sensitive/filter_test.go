@@ -0,0 +1,51 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sensitive
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatcher(t *testing.T) {
+	m := NewMatcher([]string{"he", "she", "his", "hers"})
+	var got [][2]int
+	for _, hit := range m.Match("uSHErs") {
+		got = append(got, [2]int{hit.Start, hit.Word})
+	}
+	want := [][2]int{{1, 1}, {2, 0}, {2, 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	f := NewFilter([]*Word{
+		{Word: "坏蛋", Category: "abuse"},
+		{Word: "spam", Category: "ad"},
+		{Word: "bomb", Category: "danger"},
+	}, map[string]Action{"abuse": ActionMask, "ad": ActionFlag}, ActionBlock)
+
+	res := f.Check("你这个坏蛋 buy SPAM")
+	if res.Action != ActionMask || res.Text != "你这个** buy SPAM" || len(res.Hits) != 2 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if res := f.Check("a bomb"); res.Action != ActionBlock {
+		t.Fatalf("expected block, got %+v", res)
+	}
+	if res := f.Check("hello"); res.Action != "" || res.Text != "hello" {
+		t.Fatalf("expected no hit, got %+v", res)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sensitive
+
+import "unicode"
+
+type node struct {
+	next map[rune]int32
+	fail int32
+	// word index of the dictionary entry ending here, -1 if none
+	word int32
+	// nearest node on the fail chain that ends a word
+	out int32
+	// rune length of the path from the root
+	depth int32
+}
+
+// Hit a dictionary word found in text, Start and End are rune offsets.
+type Hit struct {
+	Start int
+	End   int
+	Word  int
+}
+
+// Matcher is an Aho-Corasick automaton over a fixed dictionary, matching is case-insensitive.
+type Matcher struct {
+	nodes []node
+}
+
+// NewMatcher builds the automaton, the Word of a Hit is the index into words.
+func NewMatcher(words []string) *Matcher {
+	m := &Matcher{nodes: []node{{word: -1}}}
+	for i, word := range words {
+		if word == "" {
+			continue
+		}
+		cur := int32(0)
+		for _, r := range word {
+			r = unicode.ToLower(r)
+			next, ok := m.nodes[cur].next[r]
+			if !ok {
+				next = int32(len(m.nodes))
+				m.nodes = append(m.nodes, node{word: -1, depth: m.nodes[cur].depth + 1})
+				if m.nodes[cur].next == nil {
+					m.nodes[cur].next = make(map[rune]int32)
+				}
+				m.nodes[cur].next[r] = next
+			}
+			cur = next
+		}
+		if m.nodes[cur].word < 0 {
+			m.nodes[cur].word = int32(i)
+		}
+	}
+	m.build()
+	return m
+}
+
+func (m *Matcher) build() {
+	queue := make([]int32, 0, len(m.nodes))
+	for _, child := range m.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range m.nodes[cur].next {
+			fail := m.nodes[cur].fail
+			for fail != 0 {
+				if _, ok := m.nodes[fail].next[r]; ok {
+					break
+				}
+				fail = m.nodes[fail].fail
+			}
+			if next, ok := m.nodes[fail].next[r]; ok && next != child {
+				m.nodes[child].fail = next
+			}
+			if f := m.nodes[child].fail; m.nodes[f].word >= 0 {
+				m.nodes[child].out = f
+			} else {
+				m.nodes[child].out = m.nodes[f].out
+			}
+			queue = append(queue, child)
+		}
+	}
+}
+
+// Match returns every occurrence of the dictionary words in text, overlapping ones included.
+func (m *Matcher) Match(text string) []*Hit {
+	var hits []*Hit
+	cur := int32(0)
+	pos := 0
+	for _, r := range text {
+		r = unicode.ToLower(r)
+		for cur != 0 {
+			if _, ok := m.nodes[cur].next[r]; ok {
+				break
+			}
+			cur = m.nodes[cur].fail
+		}
+		if next, ok := m.nodes[cur].next[r]; ok {
+			cur = next
+		}
+		pos++
+		for n := cur; n != 0; n = m.nodes[n].out {
+			if w := m.nodes[n].word; w >= 0 {
+				hits = append(hits, &Hit{Start: pos - int(m.nodes[n].depth), End: pos, Word: int(w)})
+			}
+		}
+	}
+	return hits
+}
@@ -0,0 +1,28 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translator
+
+import "context"
+
+// Stub tags the text with the target language instead of translating it, for tests.
+type Stub struct{}
+
+func NewStub() *Stub {
+	return &Stub{}
+}
+
+func (Stub) Translate(_ context.Context, text string, targetLang string) (*Result, error) {
+	return &Result{Text: "[" + targetLang + "] " + text}, nil
+}
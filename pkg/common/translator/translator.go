@@ -0,0 +1,120 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/OpenIMSDK/protocol/sdkws"
+	"github.com/OpenIMSDK/tools/errs"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/db/searchindex"
+)
+
+const (
+	TypeWebhook = "webhook"
+	TypeStub    = "stub"
+)
+
+type Result struct {
+	Text       string `json:"text"`
+	SourceLang string `json:"sourceLang"`
+}
+
+// Translator translates plain text into targetLang.
+type Translator interface {
+	Translate(ctx context.Context, text string, targetLang string) (*Result, error)
+}
+
+// Cache stores translations per message and language.
+type Cache interface {
+	GetMsgTranslation(ctx context.Context, conversationID string, seq int64, lang string) (string, error)
+	SetMsgTranslation(ctx context.Context, conversationID string, seq int64, lang string, text string, expire time.Duration) error
+}
+
+// NewTranslator returns the translator selected by config, or nil when translation is disabled.
+func NewTranslator() (Translator, error) {
+	if !config.Config.Translation.Enable {
+		return nil, nil
+	}
+	switch config.Config.Translation.Type {
+	case TypeWebhook, "":
+		if config.Config.Translation.Webhook.URL == "" {
+			return nil, errs.ErrArgs.Wrap("translation webhook url is empty")
+		}
+		return NewWebhook(config.Config.Translation.Webhook.URL, config.Config.Translation.Webhook.Timeout), nil
+	case TypeStub:
+		return NewStub(), nil
+	default:
+		return nil, errs.ErrArgs.Wrap("unknown translator type " + config.Config.Translation.Type)
+	}
+}
+
+// MsgText returns the text of msg that can be translated, empty when there is none.
+func MsgText(msg *sdkws.MsgData) string {
+	return searchindex.ContentText(msg.ContentType, msg.Content)
+}
+
+// TranslateMsg translates msg into lang, using the cached translation when there is one.
+func TranslateMsg(ctx context.Context, t Translator, cache Cache, conversationID string, msg *sdkws.MsgData, lang string) (string, error) {
+	if text, err := cache.GetMsgTranslation(ctx, conversationID, msg.Seq, lang); err == nil {
+		return text, nil
+	}
+	text := MsgText(msg)
+	if text == "" {
+		return "", errs.ErrArgs.Wrap("msg has no text to translate")
+	}
+	res, err := t.Translate(ctx, text, lang)
+	if err != nil {
+		return "", err
+	}
+	if err := cache.SetMsgTranslation(ctx, conversationID, msg.Seq, lang, res.Text, cacheExpire()); err != nil {
+		return "", err
+	}
+	return res.Text, nil
+}
+
+func cacheExpire() time.Duration {
+	if config.Config.Translation.CacheExpire <= 0 {
+		return time.Hour * 24 * 7
+	}
+	return time.Second * time.Duration(config.Config.Translation.CacheExpire)
+}
+
+// AttachTranslations merges translations, lang -> text, into attachedInfo under "translations".
+func AttachTranslations(attachedInfo string, translations map[string]string) string {
+	info := make(map[string]json.RawMessage)
+	if attachedInfo != "" {
+		if err := json.Unmarshal([]byte(attachedInfo), &info); err != nil {
+			return attachedInfo
+		}
+		if info == nil {
+			info = make(map[string]json.RawMessage)
+		}
+	}
+	data, err := json.Marshal(translations)
+	if err != nil {
+		return attachedInfo
+	}
+	info["translations"] = data
+	data, err = json.Marshal(info)
+	if err != nil {
+		return attachedInfo
+	}
+	return string(data)
+}
@@ -0,0 +1,85 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	"github.com/OpenIMSDK/protocol/sdkws"
+)
+
+type mapCache map[string]string
+
+func (c mapCache) GetMsgTranslation(_ context.Context, conversationID string, seq int64, lang string) (string, error) {
+	text, ok := c[conversationID+lang]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return text, nil
+}
+
+func (c mapCache) SetMsgTranslation(_ context.Context, conversationID string, seq int64, lang string, text string, _ time.Duration) error {
+	c[conversationID+lang] = text
+	return nil
+}
+
+func TestWebhook(t *testing.T) {
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		var req webhookReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		_ = json.NewEncoder(w).Encode(&Result{Text: req.TargetLang + ":" + req.Text, SourceLang: "zh"})
+	}))
+	defer srv.Close()
+	res, err := NewWebhook(srv.URL, 5).Translate(context.Background(), "你好", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Text != "en:你好" || res.SourceLang != "zh" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
+
+func TestTranslateMsg(t *testing.T) {
+	cache := make(mapCache)
+	msg := &sdkws.MsgData{Seq: 1, ContentType: constant.Text, Content: []byte(`{"content":"hello"}`)}
+	text, err := TranslateMsg(context.Background(), NewStub(), cache, "sg_1", msg, "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "[fr] hello" || cache["sg_1fr"] != text {
+		t.Fatalf("unexpected translation %q, cache %v", text, cache)
+	}
+	msg.ContentType = constant.Picture
+	if _, err := TranslateMsg(context.Background(), NewStub(), cache, "sg_1", msg, "de"); err == nil {
+		t.Fatal("expected error for msg without text")
+	}
+}
+
+func TestAttachTranslations(t *testing.T) {
+	got := AttachTranslations(`{"groupHasReadInfo":1}`, map[string]string{"en": "hi"})
+	want := `{"groupHasReadInfo":1,"translations":{"en":"hi"}}`
+	if got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translator
+
+import (
+	"context"
+
+	"github.com/OpenIMSDK/tools/errs"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/http"
+)
+
+type webhookReq struct {
+	Text       string `json:"text"`
+	TargetLang string `json:"targetLang"`
+}
+
+// Webhook delegates translation to an HTTP service.
+type Webhook struct {
+	url     string
+	timeout int
+}
+
+func NewWebhook(url string, timeout int) *Webhook {
+	return &Webhook{url: url, timeout: timeout}
+}
+
+func (w *Webhook) Translate(ctx context.Context, text string, targetLang string) (*Result, error) {
+	var res Result
+	if err := http.PostReturn(ctx, w.url, nil, &webhookReq{Text: text, TargetLang: targetLang}, &res, w.timeout); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	if res.Text == "" {
+		return nil, errs.ErrInternalServer.Wrap("translation webhook returned empty text")
+	}
+	return &res, nil
+}
@@ -16,6 +16,7 @@ package msgprocessor
 
 import (
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/OpenIMSDK/protocol/constant"
@@ -138,6 +139,15 @@ func GetNotificationConversationID(sessionType int, ids ...string) string {
 	return ""
 }
 
+// GetThreadConversationID the derived conversation holding the replies to the message rootSeq of conversationID.
+func GetThreadConversationID(conversationID string, rootSeq int64) string {
+	return "th_" + conversationID + "_" + strconv.FormatInt(rootSeq, 10)
+}
+
+func IsThreadConversation(conversationID string) bool {
+	return strings.HasPrefix(conversationID, "th_")
+}
+
 func IsNotification(conversationID string) bool {
 	return strings.HasPrefix(conversationID, "n_")
 }
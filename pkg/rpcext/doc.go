@@ -0,0 +1,18 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rpcext holds the grpc services that are defined in this repository
+// rather than in github.com/OpenIMSDK/protocol. Their messages are plain Go
+// structs carried by the json codec registered here.
+package rpcext // import "github.com/openimsdk/open-im-server/v3/pkg/rpcext"
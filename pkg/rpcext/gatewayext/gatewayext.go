@@ -0,0 +1,105 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayext
+
+import "errors"
+
+type DrainReq struct {
+	// Node is the grpc target of the msg gateway to drain, as listed by the registry.
+	Node string `json:"node"`
+}
+
+func (x *DrainReq) Check() error {
+	if x.Node == "" {
+		return errors.New("node is empty")
+	}
+	return nil
+}
+
+type DrainResp struct {
+	// ConnNum is the number of connections that are asked to reconnect elsewhere.
+	ConnNum int64 `json:"connNum"`
+}
+
+// PushEphemeralReq relays a transient signal, such as typing, to the conns of UserIDs on one node.
+type PushEphemeralReq struct {
+	UserIDs []string `json:"userIDs"`
+	// Data is the encoded signal pushed to the clients as is.
+	Data []byte `json:"data"`
+}
+
+type PushEphemeralResp struct{}
+
+type GetUserConnsReq struct {
+	UserIDs []string `json:"userIDs"`
+}
+
+func (x *GetUserConnsReq) Check() error {
+	if len(x.UserIDs) == 0 {
+		return errors.New("userIDs is empty")
+	}
+	return nil
+}
+
+type ConnInfo struct {
+	UserID     string `json:"userID"`
+	PlatformID int32  `json:"platformID"`
+	Platform   string `json:"platform"`
+	RemoteAddr string `json:"remoteAddr"`
+	ConnID     string `json:"connID"`
+	// ConnectTime unix milli of the connect.
+	ConnectTime  int64  `json:"connectTime"`
+	IsBackground bool   `json:"isBackground"`
+	Compression  string `json:"compression"`
+	Encoding     string `json:"encoding"`
+	// Detached the conn dropped and the session waits for a resume.
+	Detached bool   `json:"detached"`
+	Node     string `json:"node"`
+}
+
+type GetUserConnsResp struct {
+	Conns []*ConnInfo `json:"conns"`
+}
+
+type GetConnCountReq struct{}
+
+type GetConnCountResp struct {
+	Node    string `json:"node"`
+	UserNum int64  `json:"userNum"`
+	ConnNum int64  `json:"connNum"`
+}
+
+type CloseConnReq struct {
+	ConnID string `json:"connID"`
+}
+
+func (x *CloseConnReq) Check() error {
+	if x.ConnID == "" {
+		return errors.New("connID is empty")
+	}
+	return nil
+}
+
+type CloseConnResp struct {
+	Node string `json:"node"`
+}
+
+// GetClusterConnCountResp sums the counts of all nodes, a user connected to
+// several nodes is counted once per node.
+type GetClusterConnCountResp struct {
+	UserNum int64               `json:"userNum"`
+	ConnNum int64               `json:"connNum"`
+	Nodes   []*GetConnCountResp `json:"nodes"`
+}
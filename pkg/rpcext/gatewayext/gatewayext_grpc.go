@@ -0,0 +1,240 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayext
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext"
+)
+
+const serviceName = "OpenIMServer.gatewayext.gatewayext"
+
+type GatewayExtClient interface {
+	Drain(ctx context.Context, in *DrainReq, opts ...grpc.CallOption) (*DrainResp, error)
+	PushEphemeral(ctx context.Context, in *PushEphemeralReq, opts ...grpc.CallOption) (*PushEphemeralResp, error)
+	GetUserConns(ctx context.Context, in *GetUserConnsReq, opts ...grpc.CallOption) (*GetUserConnsResp, error)
+	GetConnCount(ctx context.Context, in *GetConnCountReq, opts ...grpc.CallOption) (*GetConnCountResp, error)
+	CloseConn(ctx context.Context, in *CloseConnReq, opts ...grpc.CallOption) (*CloseConnResp, error)
+}
+
+type gatewayExtClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGatewayExtClient(cc grpc.ClientConnInterface) GatewayExtClient {
+	return &gatewayExtClient{cc}
+}
+
+func (c *gatewayExtClient) Drain(ctx context.Context, in *DrainReq, opts ...grpc.CallOption) (*DrainResp, error) {
+	out := new(DrainResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/Drain", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayExtClient) PushEphemeral(ctx context.Context, in *PushEphemeralReq, opts ...grpc.CallOption) (*PushEphemeralResp, error) {
+	out := new(PushEphemeralResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/PushEphemeral", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayExtClient) GetUserConns(ctx context.Context, in *GetUserConnsReq, opts ...grpc.CallOption) (*GetUserConnsResp, error) {
+	out := new(GetUserConnsResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/GetUserConns", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayExtClient) GetConnCount(ctx context.Context, in *GetConnCountReq, opts ...grpc.CallOption) (*GetConnCountResp, error) {
+	out := new(GetConnCountResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/GetConnCount", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayExtClient) CloseConn(ctx context.Context, in *CloseConnReq, opts ...grpc.CallOption) (*CloseConnResp, error) {
+	out := new(CloseConnResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/CloseConn", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type GatewayExtServer interface {
+	Drain(context.Context, *DrainReq) (*DrainResp, error)
+	PushEphemeral(context.Context, *PushEphemeralReq) (*PushEphemeralResp, error)
+	GetUserConns(context.Context, *GetUserConnsReq) (*GetUserConnsResp, error)
+	GetConnCount(context.Context, *GetConnCountReq) (*GetConnCountResp, error)
+	CloseConn(context.Context, *CloseConnReq) (*CloseConnResp, error)
+}
+
+type UnimplementedGatewayExtServer struct{}
+
+func (*UnimplementedGatewayExtServer) Drain(context.Context, *DrainReq) (*DrainResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Drain not implemented")
+}
+
+func (*UnimplementedGatewayExtServer) PushEphemeral(context.Context, *PushEphemeralReq) (*PushEphemeralResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PushEphemeral not implemented")
+}
+
+func (*UnimplementedGatewayExtServer) GetUserConns(context.Context, *GetUserConnsReq) (*GetUserConnsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserConns not implemented")
+}
+
+func (*UnimplementedGatewayExtServer) GetConnCount(context.Context, *GetConnCountReq) (*GetConnCountResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConnCount not implemented")
+}
+
+func (*UnimplementedGatewayExtServer) CloseConn(context.Context, *CloseConnReq) (*CloseConnResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloseConn not implemented")
+}
+
+func RegisterGatewayExtServer(s *grpc.Server, srv GatewayExtServer) {
+	s.RegisterService(&_GatewayExt_serviceDesc, srv)
+}
+
+func _GatewayExt_Drain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DrainReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayExtServer).Drain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/Drain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayExtServer).Drain(ctx, req.(*DrainReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayExt_PushEphemeral_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushEphemeralReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayExtServer).PushEphemeral(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/PushEphemeral",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayExtServer).PushEphemeral(ctx, req.(*PushEphemeralReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayExt_GetUserConns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserConnsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayExtServer).GetUserConns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/GetUserConns",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayExtServer).GetUserConns(ctx, req.(*GetUserConnsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayExt_GetConnCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConnCountReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayExtServer).GetConnCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/GetConnCount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayExtServer).GetConnCount(ctx, req.(*GetConnCountReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayExt_CloseConn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloseConnReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayExtServer).CloseConn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/CloseConn",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayExtServer).CloseConn(ctx, req.(*CloseConnReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _GatewayExt_serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*GatewayExtServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Drain",
+			Handler:    _GatewayExt_Drain_Handler,
+		},
+		{
+			MethodName: "PushEphemeral",
+			Handler:    _GatewayExt_PushEphemeral_Handler,
+		},
+		{
+			MethodName: "GetUserConns",
+			Handler:    _GatewayExt_GetUserConns_Handler,
+		},
+		{
+			MethodName: "GetConnCount",
+			Handler:    _GatewayExt_GetConnCount_Handler,
+		},
+		{
+			MethodName: "CloseConn",
+			Handler:    _GatewayExt_CloseConn_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
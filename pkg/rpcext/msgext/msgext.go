@@ -0,0 +1,772 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/OpenIMSDK/protocol/constant"
+	"github.com/OpenIMSDK/protocol/sdkws"
+)
+
+const (
+	MsgEditNotification            = 2110
+	MsgReactionChangedNotification = 2111
+	MsgThreadReplyNotification     = 2112
+	MsgPinnedChangedNotification   = 2113
+	GroupMsgReadCountNotification  = 2114
+)
+
+// ScheduledSendOption is the MsgData option that makes SendMsg schedule the
+// message for its SendTime instead of sending it now.
+const ScheduledSendOption = "scheduledSend"
+
+type EditMsgReq struct {
+	UserID         string `json:"userID"`
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+	Content        string `json:"content"`
+}
+
+type EditMsgResp struct{}
+
+// EditMsgTips is the notification detail sent to the conversation after a
+// message has been edited.
+type EditMsgTips struct {
+	EditorUserID   string `json:"editorUserID"`
+	ClientMsgID    string `json:"clientMsgID"`
+	ConversationID string `json:"conversationID"`
+	SessionType    int32  `json:"sessionType"`
+	Seq            int64  `json:"seq"`
+	Content        string `json:"content"`
+	EditTime       int64  `json:"editTime"`
+}
+
+type SearchMsgReq struct {
+	UserID  string `json:"userID"`
+	Keyword string `json:"keyword"`
+	// ConversationIDs optionally narrows the search to some of the user's conversations.
+	ConversationIDs []string `json:"conversationIDs"`
+	Cursor          string   `json:"cursor"`
+	Count           int32    `json:"count"`
+}
+
+type SearchedMsg struct {
+	ConversationID string         `json:"conversationID"`
+	Score          float64        `json:"score"`
+	Msg            *sdkws.MsgData `json:"msg"`
+}
+
+type SearchMsgResp struct {
+	Msgs []*SearchedMsg `json:"msgs"`
+	// NextCursor is empty on the last page.
+	NextCursor string `json:"nextCursor"`
+}
+
+type AddMsgReactionReq struct {
+	UserID         string `json:"userID"`
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+	ReactionType   string `json:"reactionType"`
+}
+
+type AddMsgReactionResp struct {
+	Reaction *Reaction `json:"reaction"`
+}
+
+type DeleteMsgReactionReq struct {
+	UserID         string `json:"userID"`
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+	ReactionType   string `json:"reactionType"`
+}
+
+type DeleteMsgReactionResp struct {
+	Reaction *Reaction `json:"reaction"`
+}
+
+type GetMsgReactionsReq struct {
+	UserID         string  `json:"userID"`
+	ConversationID string  `json:"conversationID"`
+	Seqs           []int64 `json:"seqs"`
+}
+
+type GetMsgReactionsResp struct {
+	Msgs []*MsgReactions `json:"msgs"`
+}
+
+type Reaction struct {
+	ReactionType string   `json:"reactionType"`
+	UserIDs      []string `json:"userIDs"`
+}
+
+type MsgReactions struct {
+	Seq         int64       `json:"seq"`
+	ClientMsgID string      `json:"clientMsgID"`
+	Reactions   []*Reaction `json:"reactions"`
+}
+
+// MsgReactionChangedTips is the notification detail sent to the conversation
+// when a user adds or removes a reaction.
+type MsgReactionChangedTips struct {
+	OperatorUserID string    `json:"operatorUserID"`
+	ConversationID string    `json:"conversationID"`
+	SessionType    int32     `json:"sessionType"`
+	Seq            int64     `json:"seq"`
+	ClientMsgID    string    `json:"clientMsgID"`
+	IsAdd          bool      `json:"isAdd"`
+	Reaction       *Reaction `json:"reaction"`
+}
+
+type ScheduleMsgReq struct {
+	SendTime int64          `json:"sendTime"`
+	MsgData  *sdkws.MsgData `json:"msgData"`
+}
+
+type ScheduleMsgResp struct {
+	ScheduleID  string `json:"scheduleID"`
+	ClientMsgID string `json:"clientMsgID"`
+	SendTime    int64  `json:"sendTime"`
+}
+
+type ScheduledMsg struct {
+	ScheduleID string         `json:"scheduleID"`
+	SendTime   int64          `json:"sendTime"`
+	Status     int32          `json:"status"`
+	Ex         string         `json:"ex"`
+	CreateTime int64          `json:"createTime"`
+	UpdateTime int64          `json:"updateTime"`
+	MsgData    *sdkws.MsgData `json:"msgData"`
+}
+
+type GetScheduledMsgsReq struct {
+	UserID     string                   `json:"userID"`
+	Status     []int32                  `json:"status"`
+	Pagination *sdkws.RequestPagination `json:"pagination"`
+}
+
+type GetScheduledMsgsResp struct {
+	Total int64           `json:"total"`
+	Msgs  []*ScheduledMsg `json:"msgs"`
+}
+
+type CancelScheduledMsgReq struct {
+	UserID     string `json:"userID"`
+	ScheduleID string `json:"scheduleID"`
+}
+
+type CancelScheduledMsgResp struct{}
+
+type RescheduleMsgReq struct {
+	UserID     string `json:"userID"`
+	ScheduleID string `json:"scheduleID"`
+	SendTime   int64  `json:"sendTime"`
+}
+
+type RescheduleMsgResp struct{}
+
+type SendThreadReplyReq struct {
+	ConversationID string         `json:"conversationID"`
+	RootSeq        int64          `json:"rootSeq"`
+	MsgData        *sdkws.MsgData `json:"msgData"`
+}
+
+type SendThreadReplyResp struct {
+	ThreadConversationID string `json:"threadConversationID"`
+	Seq                  int64  `json:"seq"`
+	ServerMsgID          string `json:"serverMsgID"`
+	ClientMsgID          string `json:"clientMsgID"`
+	SendTime             int64  `json:"sendTime"`
+}
+
+type PullThreadMsgsReq struct {
+	UserID         string  `json:"userID"`
+	ConversationID string  `json:"conversationID"`
+	RootSeq        int64   `json:"rootSeq"`
+	Seqs           []int64 `json:"seqs"`
+}
+
+type PullThreadMsgsResp struct {
+	ThreadConversationID string           `json:"threadConversationID"`
+	MinSeq               int64            `json:"minSeq"`
+	MaxSeq               int64            `json:"maxSeq"`
+	Msgs                 []*sdkws.MsgData `json:"msgs"`
+}
+
+type GetThreadSummariesReq struct {
+	UserID         string  `json:"userID"`
+	ConversationID string  `json:"conversationID"`
+	Seqs           []int64 `json:"seqs"`
+}
+
+type GetThreadSummariesResp struct {
+	Summaries []*ThreadSummary `json:"summaries"`
+}
+
+// ThreadSummary the reply count and last reply of a thread root message.
+type ThreadSummary struct {
+	RootSeq              int64  `json:"rootSeq"`
+	RootClientMsgID      string `json:"rootClientMsgID"`
+	ThreadConversationID string `json:"threadConversationID"`
+	ReplyCount           int64  `json:"replyCount"`
+	LastReplySeq         int64  `json:"lastReplySeq"`
+	LastReplySendID      string `json:"lastReplySendID"`
+	LastReplyClientMsgID string `json:"lastReplyClientMsgID"`
+	LastReplyTime        int64  `json:"lastReplyTime"`
+}
+
+// ThreadReplyTips is the notification detail sent to the root conversation
+// when a reply is added to a thread.
+type ThreadReplyTips struct {
+	ConversationID string         `json:"conversationID"`
+	Summary        *ThreadSummary `json:"summary"`
+	Reply          *sdkws.MsgData `json:"reply"`
+}
+
+type PinMsgReq struct {
+	UserID         string `json:"userID"`
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+}
+
+type PinMsgResp struct{}
+
+type UnpinMsgReq struct {
+	UserID         string `json:"userID"`
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+}
+
+type UnpinMsgResp struct{}
+
+type GetPinnedMsgsReq struct {
+	UserID         string `json:"userID"`
+	ConversationID string `json:"conversationID"`
+}
+
+type GetPinnedMsgsResp struct {
+	Msgs []*PinnedMsg `json:"msgs"`
+}
+
+type PinnedMsg struct {
+	Seq          int64          `json:"seq"`
+	ClientMsgID  string         `json:"clientMsgID"`
+	PinnerUserID string         `json:"pinnerUserID"`
+	PinTime      int64          `json:"pinTime"`
+	MsgData      *sdkws.MsgData `json:"msgData"`
+}
+
+// MsgPinnedChangedTips is the notification detail sent to the conversation
+// when a message is pinned or unpinned.
+type MsgPinnedChangedTips struct {
+	OperatorUserID string `json:"operatorUserID"`
+	ConversationID string `json:"conversationID"`
+	SessionType    int32  `json:"sessionType"`
+	Seq            int64  `json:"seq"`
+	ClientMsgID    string `json:"clientMsgID"`
+	IsPinned       bool   `json:"isPinned"`
+	OperateTime    int64  `json:"operateTime"`
+}
+
+type GetMsgReadersReq struct {
+	UserID         string `json:"userID"`
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+}
+
+type GetMsgReadersResp struct {
+	UserIDs []string `json:"userIDs"`
+}
+
+type GetMsgUnreadMembersReq struct {
+	UserID         string `json:"userID"`
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+}
+
+type GetMsgUnreadMembersResp struct {
+	UserIDs []string `json:"userIDs"`
+}
+
+type GroupMsgReadCount struct {
+	Seq       int64 `json:"seq"`
+	ReadCount int64 `json:"readCount"`
+}
+
+// GroupMsgReadCountTips is the notification detail sent to the sender of group
+// messages, aggregating the read count changes of a short period.
+type GroupMsgReadCountTips struct {
+	ConversationID   string               `json:"conversationID"`
+	GroupID          string               `json:"groupID"`
+	GroupMemberCount uint32               `json:"groupMemberCount"`
+	Reads            []*GroupMsgReadCount `json:"reads"`
+}
+
+type ForwardTarget struct {
+	RecvID      string `json:"recvID"`
+	GroupID     string `json:"groupID"`
+	SessionType int32  `json:"sessionType"`
+}
+
+type ForwardMsgsReq struct {
+	UserID           string           `json:"userID"`
+	ConversationID   string           `json:"conversationID"`
+	Seqs             []int64          `json:"seqs"`
+	Targets          []*ForwardTarget `json:"targets"`
+	IsMerge          bool             `json:"isMerge"`
+	Title            string           `json:"title"`
+	SenderPlatformID int32            `json:"senderPlatformID"`
+}
+
+type ForwardMsgsResp struct {
+	Results []*ForwardResult `json:"results"`
+}
+
+type ForwardResult struct {
+	Target      *ForwardTarget `json:"target"`
+	SourceSeq   int64          `json:"sourceSeq"`
+	ServerMsgID string         `json:"serverMsgID"`
+	ClientMsgID string         `json:"clientMsgID"`
+	SendTime    int64          `json:"sendTime"`
+	ErrCode     int32          `json:"errCode"`
+	ErrMsg      string         `json:"errMsg"`
+}
+
+// ForwardInfo original sender metadata of an individually forwarded message,
+// attached to the attachedInfo of the forwarded copy under "forwardInfo".
+type ForwardInfo struct {
+	SourceConversationID string `json:"sourceConversationID"`
+	SourceSeq            int64  `json:"sourceSeq"`
+	SourceClientMsgID    string `json:"sourceClientMsgID"`
+	SourceSendID         string `json:"sourceSendID"`
+	SourceSenderNickname string `json:"sourceSenderNickname"`
+	SourceSendTime       int64  `json:"sourceSendTime"`
+}
+
+// MergeElem content of a server built constant.Merger message.
+type MergeElem struct {
+	Title        string       `json:"title"`
+	AbstractList []string     `json:"abstractList"`
+	MultiMessage []*MergedMsg `json:"multiMessage"`
+}
+
+type MergedMsg struct {
+	ClientMsgID      string `json:"clientMsgID"`
+	ServerMsgID      string `json:"serverMsgID"`
+	SendID           string `json:"sendID"`
+	RecvID           string `json:"recvID"`
+	GroupID          string `json:"groupID"`
+	SenderPlatformID int32  `json:"senderPlatformID"`
+	SenderNickname   string `json:"senderNickname"`
+	SenderFaceURL    string `json:"senderFaceUrl"`
+	SessionType      int32  `json:"sessionType"`
+	MsgFrom          int32  `json:"msgFrom"`
+	ContentType      int32  `json:"contentType"`
+	Content          string `json:"content"`
+	Seq              int64  `json:"seq"`
+	SendTime         int64  `json:"sendTime"`
+	CreateTime       int64  `json:"createTime"`
+	Ex               string `json:"ex"`
+}
+
+type TranslateMsgReq struct {
+	UserID         string `json:"userID"`
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+	TargetLang     string `json:"targetLang"`
+}
+
+type TranslateMsgResp struct {
+	Seq        int64  `json:"seq"`
+	TargetLang string `json:"targetLang"`
+	Text       string `json:"text"`
+}
+
+type SensitiveWord struct {
+	Word       string `json:"word"`
+	Category   string `json:"category"`
+	CreateTime int64  `json:"createTime"`
+	Ex         string `json:"ex"`
+}
+
+type AddSensitiveWordsReq struct {
+	Words []*SensitiveWord `json:"words"`
+}
+
+type AddSensitiveWordsResp struct{}
+
+type DeleteSensitiveWordsReq struct {
+	Words []string `json:"words"`
+}
+
+type DeleteSensitiveWordsResp struct{}
+
+type SearchSensitiveWordsReq struct {
+	Keyword    string                   `json:"keyword"`
+	Category   string                   `json:"category"`
+	Pagination *sdkws.RequestPagination `json:"pagination"`
+}
+
+type SearchSensitiveWordsResp struct {
+	Total int64            `json:"total"`
+	Words []*SensitiveWord `json:"words"`
+}
+
+func (x *EditMsgReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.ConversationID == "" {
+		return errors.New("conversationID is empty")
+	}
+	if x.Seq <= 0 {
+		return errors.New("seq is invalid")
+	}
+	if x.Content == "" {
+		return errors.New("content is empty")
+	}
+	return nil
+}
+
+func (x *SearchMsgReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.Keyword == "" {
+		return errors.New("keyword is empty")
+	}
+	if x.Count < 0 || x.Count > 100 {
+		return errors.New("count is invalid")
+	}
+	return nil
+}
+
+func checkReactionType(reactionType string) error {
+	if reactionType == "" {
+		return errors.New("reactionType is empty")
+	}
+	if len(reactionType) > 64 {
+		return errors.New("reactionType is too long")
+	}
+	// reaction types are used as mongo field names
+	if strings.Contains(reactionType, ".") || strings.HasPrefix(reactionType, "$") {
+		return errors.New("reactionType is invalid")
+	}
+	return nil
+}
+
+func (x *AddMsgReactionReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.ConversationID == "" {
+		return errors.New("conversationID is empty")
+	}
+	if x.Seq <= 0 {
+		return errors.New("seq is invalid")
+	}
+	return checkReactionType(x.ReactionType)
+}
+
+func (x *DeleteMsgReactionReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.ConversationID == "" {
+		return errors.New("conversationID is empty")
+	}
+	if x.Seq <= 0 {
+		return errors.New("seq is invalid")
+	}
+	return checkReactionType(x.ReactionType)
+}
+
+func (x *GetMsgReactionsReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.ConversationID == "" {
+		return errors.New("conversationID is empty")
+	}
+	if len(x.Seqs) == 0 || len(x.Seqs) > 100 {
+		return errors.New("seqs is invalid")
+	}
+	return nil
+}
+
+func (x *ScheduleMsgReq) Check() error {
+	if x.MsgData == nil {
+		return errors.New("msgData is nil")
+	}
+	if x.MsgData.SendID == "" {
+		return errors.New("sendID is empty")
+	}
+	if x.SendTime <= 0 {
+		return errors.New("sendTime is invalid")
+	}
+	return nil
+}
+
+func (x *GetScheduledMsgsReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.Pagination == nil {
+		return errors.New("pagination is empty")
+	}
+	if x.Pagination.PageNumber < 1 {
+		return errors.New("pageNumber is invalid")
+	}
+	if x.Pagination.ShowNumber < 1 || x.Pagination.ShowNumber > 100 {
+		return errors.New("showNumber is invalid")
+	}
+	return nil
+}
+
+func (x *CancelScheduledMsgReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.ScheduleID == "" {
+		return errors.New("scheduleID is empty")
+	}
+	return nil
+}
+
+func (x *RescheduleMsgReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.ScheduleID == "" {
+		return errors.New("scheduleID is empty")
+	}
+	if x.SendTime <= 0 {
+		return errors.New("sendTime is invalid")
+	}
+	return nil
+}
+
+func (x *SendThreadReplyReq) Check() error {
+	if x.ConversationID == "" {
+		return errors.New("conversationID is empty")
+	}
+	if x.RootSeq <= 0 {
+		return errors.New("rootSeq is invalid")
+	}
+	if x.MsgData == nil {
+		return errors.New("msgData is nil")
+	}
+	if x.MsgData.SendID == "" {
+		return errors.New("sendID is empty")
+	}
+	return nil
+}
+
+func (x *PullThreadMsgsReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.ConversationID == "" {
+		return errors.New("conversationID is empty")
+	}
+	if x.RootSeq <= 0 {
+		return errors.New("rootSeq is invalid")
+	}
+	if len(x.Seqs) == 0 || len(x.Seqs) > 100 {
+		return errors.New("seqs is invalid")
+	}
+	return nil
+}
+
+func (x *GetThreadSummariesReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.ConversationID == "" {
+		return errors.New("conversationID is empty")
+	}
+	if len(x.Seqs) == 0 || len(x.Seqs) > 100 {
+		return errors.New("seqs is invalid")
+	}
+	return nil
+}
+
+func (x *PinMsgReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.ConversationID == "" {
+		return errors.New("conversationID is empty")
+	}
+	if x.Seq <= 0 {
+		return errors.New("seq is invalid")
+	}
+	return nil
+}
+
+func (x *UnpinMsgReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.ConversationID == "" {
+		return errors.New("conversationID is empty")
+	}
+	if x.Seq <= 0 {
+		return errors.New("seq is invalid")
+	}
+	return nil
+}
+
+func (x *GetPinnedMsgsReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.ConversationID == "" {
+		return errors.New("conversationID is empty")
+	}
+	return nil
+}
+
+func (x *GetMsgReadersReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.ConversationID == "" {
+		return errors.New("conversationID is empty")
+	}
+	if x.Seq <= 0 {
+		return errors.New("seq is invalid")
+	}
+	return nil
+}
+
+func (x *GetMsgUnreadMembersReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.ConversationID == "" {
+		return errors.New("conversationID is empty")
+	}
+	if x.Seq <= 0 {
+		return errors.New("seq is invalid")
+	}
+	return nil
+}
+
+func (x *ForwardMsgsReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.ConversationID == "" {
+		return errors.New("conversationID is empty")
+	}
+	if len(x.Seqs) == 0 || len(x.Seqs) > 100 {
+		return errors.New("seqs is invalid")
+	}
+	if len(x.Targets) == 0 || len(x.Targets) > 20 {
+		return errors.New("targets is invalid")
+	}
+	for _, target := range x.Targets {
+		if target == nil {
+			return errors.New("target is nil")
+		}
+		switch target.SessionType {
+		case constant.SingleChatType:
+			if target.RecvID == "" {
+				return errors.New("target recvID is empty")
+			}
+		case constant.SuperGroupChatType:
+			if target.GroupID == "" {
+				return errors.New("target groupID is empty")
+			}
+		default:
+			return errors.New("target sessionType is invalid")
+		}
+	}
+	return nil
+}
+
+func (x *TranslateMsgReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.ConversationID == "" {
+		return errors.New("conversationID is empty")
+	}
+	if x.Seq <= 0 {
+		return errors.New("seq is invalid")
+	}
+	if x.TargetLang == "" || len(x.TargetLang) > 16 {
+		return errors.New("targetLang is invalid")
+	}
+	for _, c := range x.TargetLang {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-' || c == '_') {
+			return errors.New("targetLang is invalid")
+		}
+	}
+	return nil
+}
+
+func checkSensitiveWord(word string) error {
+	if word == "" {
+		return errors.New("word is empty")
+	}
+	if utf8.RuneCountInString(word) > 64 {
+		return errors.New("word is too long")
+	}
+	return nil
+}
+
+func (x *AddSensitiveWordsReq) Check() error {
+	if len(x.Words) == 0 || len(x.Words) > 1000 {
+		return errors.New("words is invalid")
+	}
+	for _, word := range x.Words {
+		if word == nil {
+			return errors.New("word is nil")
+		}
+		if err := checkSensitiveWord(word.Word); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *DeleteSensitiveWordsReq) Check() error {
+	if len(x.Words) == 0 || len(x.Words) > 1000 {
+		return errors.New("words is invalid")
+	}
+	for _, word := range x.Words {
+		if err := checkSensitiveWord(word); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *SearchSensitiveWordsReq) Check() error {
+	if x.Pagination == nil {
+		return errors.New("pagination is empty")
+	}
+	if x.Pagination.PageNumber < 1 {
+		return errors.New("pageNumber is invalid")
+	}
+	if x.Pagination.ShowNumber < 1 || x.Pagination.ShowNumber > 100 {
+		return errors.New("showNumber is invalid")
+	}
+	return nil
+}
@@ -0,0 +1,869 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext"
+)
+
+const serviceName = "OpenIMServer.msgext.msgext"
+
+type MsgExtClient interface {
+	EditMsg(ctx context.Context, in *EditMsgReq, opts ...grpc.CallOption) (*EditMsgResp, error)
+	SearchMsg(ctx context.Context, in *SearchMsgReq, opts ...grpc.CallOption) (*SearchMsgResp, error)
+	AddMsgReaction(ctx context.Context, in *AddMsgReactionReq, opts ...grpc.CallOption) (*AddMsgReactionResp, error)
+	DeleteMsgReaction(ctx context.Context, in *DeleteMsgReactionReq, opts ...grpc.CallOption) (*DeleteMsgReactionResp, error)
+	GetMsgReactions(ctx context.Context, in *GetMsgReactionsReq, opts ...grpc.CallOption) (*GetMsgReactionsResp, error)
+	ScheduleMsg(ctx context.Context, in *ScheduleMsgReq, opts ...grpc.CallOption) (*ScheduleMsgResp, error)
+	GetScheduledMsgs(ctx context.Context, in *GetScheduledMsgsReq, opts ...grpc.CallOption) (*GetScheduledMsgsResp, error)
+	CancelScheduledMsg(ctx context.Context, in *CancelScheduledMsgReq, opts ...grpc.CallOption) (*CancelScheduledMsgResp, error)
+	RescheduleMsg(ctx context.Context, in *RescheduleMsgReq, opts ...grpc.CallOption) (*RescheduleMsgResp, error)
+	SendThreadReply(ctx context.Context, in *SendThreadReplyReq, opts ...grpc.CallOption) (*SendThreadReplyResp, error)
+	PullThreadMsgs(ctx context.Context, in *PullThreadMsgsReq, opts ...grpc.CallOption) (*PullThreadMsgsResp, error)
+	GetThreadSummaries(ctx context.Context, in *GetThreadSummariesReq, opts ...grpc.CallOption) (*GetThreadSummariesResp, error)
+	PinMsg(ctx context.Context, in *PinMsgReq, opts ...grpc.CallOption) (*PinMsgResp, error)
+	UnpinMsg(ctx context.Context, in *UnpinMsgReq, opts ...grpc.CallOption) (*UnpinMsgResp, error)
+	GetPinnedMsgs(ctx context.Context, in *GetPinnedMsgsReq, opts ...grpc.CallOption) (*GetPinnedMsgsResp, error)
+	GetMsgReaders(ctx context.Context, in *GetMsgReadersReq, opts ...grpc.CallOption) (*GetMsgReadersResp, error)
+	GetMsgUnreadMembers(ctx context.Context, in *GetMsgUnreadMembersReq, opts ...grpc.CallOption) (*GetMsgUnreadMembersResp, error)
+	ForwardMsgs(ctx context.Context, in *ForwardMsgsReq, opts ...grpc.CallOption) (*ForwardMsgsResp, error)
+	TranslateMsg(ctx context.Context, in *TranslateMsgReq, opts ...grpc.CallOption) (*TranslateMsgResp, error)
+	AddSensitiveWords(ctx context.Context, in *AddSensitiveWordsReq, opts ...grpc.CallOption) (*AddSensitiveWordsResp, error)
+	DeleteSensitiveWords(ctx context.Context, in *DeleteSensitiveWordsReq, opts ...grpc.CallOption) (*DeleteSensitiveWordsResp, error)
+	SearchSensitiveWords(ctx context.Context, in *SearchSensitiveWordsReq, opts ...grpc.CallOption) (*SearchSensitiveWordsResp, error)
+}
+
+type msgExtClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMsgExtClient(cc grpc.ClientConnInterface) MsgExtClient {
+	return &msgExtClient{cc}
+}
+
+func (c *msgExtClient) EditMsg(ctx context.Context, in *EditMsgReq, opts ...grpc.CallOption) (*EditMsgResp, error) {
+	out := new(EditMsgResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/EditMsg", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) SearchMsg(ctx context.Context, in *SearchMsgReq, opts ...grpc.CallOption) (*SearchMsgResp, error) {
+	out := new(SearchMsgResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/SearchMsg", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) AddMsgReaction(ctx context.Context, in *AddMsgReactionReq, opts ...grpc.CallOption) (*AddMsgReactionResp, error) {
+	out := new(AddMsgReactionResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/AddMsgReaction", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) DeleteMsgReaction(ctx context.Context, in *DeleteMsgReactionReq, opts ...grpc.CallOption) (*DeleteMsgReactionResp, error) {
+	out := new(DeleteMsgReactionResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/DeleteMsgReaction", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) GetMsgReactions(ctx context.Context, in *GetMsgReactionsReq, opts ...grpc.CallOption) (*GetMsgReactionsResp, error) {
+	out := new(GetMsgReactionsResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/GetMsgReactions", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) ScheduleMsg(ctx context.Context, in *ScheduleMsgReq, opts ...grpc.CallOption) (*ScheduleMsgResp, error) {
+	out := new(ScheduleMsgResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/ScheduleMsg", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) GetScheduledMsgs(ctx context.Context, in *GetScheduledMsgsReq, opts ...grpc.CallOption) (*GetScheduledMsgsResp, error) {
+	out := new(GetScheduledMsgsResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/GetScheduledMsgs", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) CancelScheduledMsg(ctx context.Context, in *CancelScheduledMsgReq, opts ...grpc.CallOption) (*CancelScheduledMsgResp, error) {
+	out := new(CancelScheduledMsgResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/CancelScheduledMsg", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) RescheduleMsg(ctx context.Context, in *RescheduleMsgReq, opts ...grpc.CallOption) (*RescheduleMsgResp, error) {
+	out := new(RescheduleMsgResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/RescheduleMsg", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) SendThreadReply(ctx context.Context, in *SendThreadReplyReq, opts ...grpc.CallOption) (*SendThreadReplyResp, error) {
+	out := new(SendThreadReplyResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/SendThreadReply", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) PullThreadMsgs(ctx context.Context, in *PullThreadMsgsReq, opts ...grpc.CallOption) (*PullThreadMsgsResp, error) {
+	out := new(PullThreadMsgsResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/PullThreadMsgs", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) GetThreadSummaries(ctx context.Context, in *GetThreadSummariesReq, opts ...grpc.CallOption) (*GetThreadSummariesResp, error) {
+	out := new(GetThreadSummariesResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/GetThreadSummaries", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) PinMsg(ctx context.Context, in *PinMsgReq, opts ...grpc.CallOption) (*PinMsgResp, error) {
+	out := new(PinMsgResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/PinMsg", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) UnpinMsg(ctx context.Context, in *UnpinMsgReq, opts ...grpc.CallOption) (*UnpinMsgResp, error) {
+	out := new(UnpinMsgResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/UnpinMsg", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) GetPinnedMsgs(ctx context.Context, in *GetPinnedMsgsReq, opts ...grpc.CallOption) (*GetPinnedMsgsResp, error) {
+	out := new(GetPinnedMsgsResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/GetPinnedMsgs", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) GetMsgReaders(ctx context.Context, in *GetMsgReadersReq, opts ...grpc.CallOption) (*GetMsgReadersResp, error) {
+	out := new(GetMsgReadersResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/GetMsgReaders", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) GetMsgUnreadMembers(ctx context.Context, in *GetMsgUnreadMembersReq, opts ...grpc.CallOption) (*GetMsgUnreadMembersResp, error) {
+	out := new(GetMsgUnreadMembersResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/GetMsgUnreadMembers", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) ForwardMsgs(ctx context.Context, in *ForwardMsgsReq, opts ...grpc.CallOption) (*ForwardMsgsResp, error) {
+	out := new(ForwardMsgsResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/ForwardMsgs", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) TranslateMsg(ctx context.Context, in *TranslateMsgReq, opts ...grpc.CallOption) (*TranslateMsgResp, error) {
+	out := new(TranslateMsgResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/TranslateMsg", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) AddSensitiveWords(ctx context.Context, in *AddSensitiveWordsReq, opts ...grpc.CallOption) (*AddSensitiveWordsResp, error) {
+	out := new(AddSensitiveWordsResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/AddSensitiveWords", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) DeleteSensitiveWords(ctx context.Context, in *DeleteSensitiveWordsReq, opts ...grpc.CallOption) (*DeleteSensitiveWordsResp, error) {
+	out := new(DeleteSensitiveWordsResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/DeleteSensitiveWords", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) SearchSensitiveWords(ctx context.Context, in *SearchSensitiveWordsReq, opts ...grpc.CallOption) (*SearchSensitiveWordsResp, error) {
+	out := new(SearchSensitiveWordsResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/SearchSensitiveWords", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type MsgExtServer interface {
+	EditMsg(context.Context, *EditMsgReq) (*EditMsgResp, error)
+	SearchMsg(context.Context, *SearchMsgReq) (*SearchMsgResp, error)
+	AddMsgReaction(context.Context, *AddMsgReactionReq) (*AddMsgReactionResp, error)
+	DeleteMsgReaction(context.Context, *DeleteMsgReactionReq) (*DeleteMsgReactionResp, error)
+	GetMsgReactions(context.Context, *GetMsgReactionsReq) (*GetMsgReactionsResp, error)
+	ScheduleMsg(context.Context, *ScheduleMsgReq) (*ScheduleMsgResp, error)
+	GetScheduledMsgs(context.Context, *GetScheduledMsgsReq) (*GetScheduledMsgsResp, error)
+	CancelScheduledMsg(context.Context, *CancelScheduledMsgReq) (*CancelScheduledMsgResp, error)
+	RescheduleMsg(context.Context, *RescheduleMsgReq) (*RescheduleMsgResp, error)
+	SendThreadReply(context.Context, *SendThreadReplyReq) (*SendThreadReplyResp, error)
+	PullThreadMsgs(context.Context, *PullThreadMsgsReq) (*PullThreadMsgsResp, error)
+	GetThreadSummaries(context.Context, *GetThreadSummariesReq) (*GetThreadSummariesResp, error)
+	PinMsg(context.Context, *PinMsgReq) (*PinMsgResp, error)
+	UnpinMsg(context.Context, *UnpinMsgReq) (*UnpinMsgResp, error)
+	GetPinnedMsgs(context.Context, *GetPinnedMsgsReq) (*GetPinnedMsgsResp, error)
+	GetMsgReaders(context.Context, *GetMsgReadersReq) (*GetMsgReadersResp, error)
+	GetMsgUnreadMembers(context.Context, *GetMsgUnreadMembersReq) (*GetMsgUnreadMembersResp, error)
+	ForwardMsgs(context.Context, *ForwardMsgsReq) (*ForwardMsgsResp, error)
+	TranslateMsg(context.Context, *TranslateMsgReq) (*TranslateMsgResp, error)
+	AddSensitiveWords(context.Context, *AddSensitiveWordsReq) (*AddSensitiveWordsResp, error)
+	DeleteSensitiveWords(context.Context, *DeleteSensitiveWordsReq) (*DeleteSensitiveWordsResp, error)
+	SearchSensitiveWords(context.Context, *SearchSensitiveWordsReq) (*SearchSensitiveWordsResp, error)
+}
+
+type UnimplementedMsgExtServer struct{}
+
+func (*UnimplementedMsgExtServer) EditMsg(context.Context, *EditMsgReq) (*EditMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditMsg not implemented")
+}
+
+func (*UnimplementedMsgExtServer) SearchMsg(context.Context, *SearchMsgReq) (*SearchMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchMsg not implemented")
+}
+
+func (*UnimplementedMsgExtServer) AddMsgReaction(context.Context, *AddMsgReactionReq) (*AddMsgReactionResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddMsgReaction not implemented")
+}
+
+func (*UnimplementedMsgExtServer) DeleteMsgReaction(context.Context, *DeleteMsgReactionReq) (*DeleteMsgReactionResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteMsgReaction not implemented")
+}
+
+func (*UnimplementedMsgExtServer) GetMsgReactions(context.Context, *GetMsgReactionsReq) (*GetMsgReactionsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMsgReactions not implemented")
+}
+
+func (*UnimplementedMsgExtServer) ScheduleMsg(context.Context, *ScheduleMsgReq) (*ScheduleMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ScheduleMsg not implemented")
+}
+
+func (*UnimplementedMsgExtServer) GetScheduledMsgs(context.Context, *GetScheduledMsgsReq) (*GetScheduledMsgsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetScheduledMsgs not implemented")
+}
+
+func (*UnimplementedMsgExtServer) CancelScheduledMsg(context.Context, *CancelScheduledMsgReq) (*CancelScheduledMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelScheduledMsg not implemented")
+}
+
+func (*UnimplementedMsgExtServer) RescheduleMsg(context.Context, *RescheduleMsgReq) (*RescheduleMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RescheduleMsg not implemented")
+}
+
+func (*UnimplementedMsgExtServer) SendThreadReply(context.Context, *SendThreadReplyReq) (*SendThreadReplyResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendThreadReply not implemented")
+}
+
+func (*UnimplementedMsgExtServer) PullThreadMsgs(context.Context, *PullThreadMsgsReq) (*PullThreadMsgsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PullThreadMsgs not implemented")
+}
+
+func (*UnimplementedMsgExtServer) GetThreadSummaries(context.Context, *GetThreadSummariesReq) (*GetThreadSummariesResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetThreadSummaries not implemented")
+}
+
+func (*UnimplementedMsgExtServer) PinMsg(context.Context, *PinMsgReq) (*PinMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PinMsg not implemented")
+}
+
+func (*UnimplementedMsgExtServer) UnpinMsg(context.Context, *UnpinMsgReq) (*UnpinMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnpinMsg not implemented")
+}
+
+func (*UnimplementedMsgExtServer) GetPinnedMsgs(context.Context, *GetPinnedMsgsReq) (*GetPinnedMsgsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPinnedMsgs not implemented")
+}
+
+func (*UnimplementedMsgExtServer) GetMsgReaders(context.Context, *GetMsgReadersReq) (*GetMsgReadersResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMsgReaders not implemented")
+}
+
+func (*UnimplementedMsgExtServer) GetMsgUnreadMembers(context.Context, *GetMsgUnreadMembersReq) (*GetMsgUnreadMembersResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMsgUnreadMembers not implemented")
+}
+
+func (*UnimplementedMsgExtServer) ForwardMsgs(context.Context, *ForwardMsgsReq) (*ForwardMsgsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ForwardMsgs not implemented")
+}
+
+func (*UnimplementedMsgExtServer) TranslateMsg(context.Context, *TranslateMsgReq) (*TranslateMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TranslateMsg not implemented")
+}
+
+func (*UnimplementedMsgExtServer) AddSensitiveWords(context.Context, *AddSensitiveWordsReq) (*AddSensitiveWordsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddSensitiveWords not implemented")
+}
+
+func (*UnimplementedMsgExtServer) DeleteSensitiveWords(context.Context, *DeleteSensitiveWordsReq) (*DeleteSensitiveWordsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSensitiveWords not implemented")
+}
+
+func (*UnimplementedMsgExtServer) SearchSensitiveWords(context.Context, *SearchSensitiveWordsReq) (*SearchSensitiveWordsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchSensitiveWords not implemented")
+}
+
+func RegisterMsgExtServer(s *grpc.Server, srv MsgExtServer) {
+	s.RegisterService(&_MsgExt_serviceDesc, srv)
+}
+
+func _MsgExt_EditMsg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).EditMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/EditMsg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).EditMsg(ctx, req.(*EditMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_SearchMsg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).SearchMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/SearchMsg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).SearchMsg(ctx, req.(*SearchMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_AddMsgReaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddMsgReactionReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).AddMsgReaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/AddMsgReaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).AddMsgReaction(ctx, req.(*AddMsgReactionReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_DeleteMsgReaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteMsgReactionReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).DeleteMsgReaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/DeleteMsgReaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).DeleteMsgReaction(ctx, req.(*DeleteMsgReactionReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_GetMsgReactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMsgReactionsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).GetMsgReactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/GetMsgReactions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).GetMsgReactions(ctx, req.(*GetMsgReactionsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_ScheduleMsg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScheduleMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).ScheduleMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/ScheduleMsg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).ScheduleMsg(ctx, req.(*ScheduleMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_GetScheduledMsgs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetScheduledMsgsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).GetScheduledMsgs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/GetScheduledMsgs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).GetScheduledMsgs(ctx, req.(*GetScheduledMsgsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_CancelScheduledMsg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelScheduledMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).CancelScheduledMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/CancelScheduledMsg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).CancelScheduledMsg(ctx, req.(*CancelScheduledMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_RescheduleMsg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RescheduleMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).RescheduleMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/RescheduleMsg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).RescheduleMsg(ctx, req.(*RescheduleMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_SendThreadReply_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendThreadReplyReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).SendThreadReply(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/SendThreadReply",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).SendThreadReply(ctx, req.(*SendThreadReplyReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_PullThreadMsgs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PullThreadMsgsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).PullThreadMsgs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/PullThreadMsgs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).PullThreadMsgs(ctx, req.(*PullThreadMsgsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_GetThreadSummaries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetThreadSummariesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).GetThreadSummaries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/GetThreadSummaries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).GetThreadSummaries(ctx, req.(*GetThreadSummariesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_PinMsg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PinMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).PinMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/PinMsg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).PinMsg(ctx, req.(*PinMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_UnpinMsg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnpinMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).UnpinMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/UnpinMsg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).UnpinMsg(ctx, req.(*UnpinMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_GetPinnedMsgs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPinnedMsgsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).GetPinnedMsgs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/GetPinnedMsgs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).GetPinnedMsgs(ctx, req.(*GetPinnedMsgsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_GetMsgReaders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMsgReadersReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).GetMsgReaders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/GetMsgReaders",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).GetMsgReaders(ctx, req.(*GetMsgReadersReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_GetMsgUnreadMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMsgUnreadMembersReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).GetMsgUnreadMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/GetMsgUnreadMembers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).GetMsgUnreadMembers(ctx, req.(*GetMsgUnreadMembersReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_ForwardMsgs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForwardMsgsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).ForwardMsgs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/ForwardMsgs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).ForwardMsgs(ctx, req.(*ForwardMsgsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_TranslateMsg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TranslateMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).TranslateMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/TranslateMsg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).TranslateMsg(ctx, req.(*TranslateMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_AddSensitiveWords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddSensitiveWordsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).AddSensitiveWords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/AddSensitiveWords",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).AddSensitiveWords(ctx, req.(*AddSensitiveWordsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_DeleteSensitiveWords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSensitiveWordsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).DeleteSensitiveWords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/DeleteSensitiveWords",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).DeleteSensitiveWords(ctx, req.(*DeleteSensitiveWordsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_SearchSensitiveWords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchSensitiveWordsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).SearchSensitiveWords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/SearchSensitiveWords",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsgExtServer).SearchSensitiveWords(ctx, req.(*SearchSensitiveWordsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _MsgExt_serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*MsgExtServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "EditMsg",
+			Handler:    _MsgExt_EditMsg_Handler,
+		},
+		{
+			MethodName: "SearchMsg",
+			Handler:    _MsgExt_SearchMsg_Handler,
+		},
+		{
+			MethodName: "AddMsgReaction",
+			Handler:    _MsgExt_AddMsgReaction_Handler,
+		},
+		{
+			MethodName: "DeleteMsgReaction",
+			Handler:    _MsgExt_DeleteMsgReaction_Handler,
+		},
+		{
+			MethodName: "GetMsgReactions",
+			Handler:    _MsgExt_GetMsgReactions_Handler,
+		},
+		{
+			MethodName: "ScheduleMsg",
+			Handler:    _MsgExt_ScheduleMsg_Handler,
+		},
+		{
+			MethodName: "GetScheduledMsgs",
+			Handler:    _MsgExt_GetScheduledMsgs_Handler,
+		},
+		{
+			MethodName: "CancelScheduledMsg",
+			Handler:    _MsgExt_CancelScheduledMsg_Handler,
+		},
+		{
+			MethodName: "RescheduleMsg",
+			Handler:    _MsgExt_RescheduleMsg_Handler,
+		},
+		{
+			MethodName: "SendThreadReply",
+			Handler:    _MsgExt_SendThreadReply_Handler,
+		},
+		{
+			MethodName: "PullThreadMsgs",
+			Handler:    _MsgExt_PullThreadMsgs_Handler,
+		},
+		{
+			MethodName: "GetThreadSummaries",
+			Handler:    _MsgExt_GetThreadSummaries_Handler,
+		},
+		{
+			MethodName: "PinMsg",
+			Handler:    _MsgExt_PinMsg_Handler,
+		},
+		{
+			MethodName: "UnpinMsg",
+			Handler:    _MsgExt_UnpinMsg_Handler,
+		},
+		{
+			MethodName: "GetPinnedMsgs",
+			Handler:    _MsgExt_GetPinnedMsgs_Handler,
+		},
+		{
+			MethodName: "GetMsgReaders",
+			Handler:    _MsgExt_GetMsgReaders_Handler,
+		},
+		{
+			MethodName: "GetMsgUnreadMembers",
+			Handler:    _MsgExt_GetMsgUnreadMembers_Handler,
+		},
+		{
+			MethodName: "ForwardMsgs",
+			Handler:    _MsgExt_ForwardMsgs_Handler,
+		},
+		{
+			MethodName: "TranslateMsg",
+			Handler:    _MsgExt_TranslateMsg_Handler,
+		},
+		{
+			MethodName: "AddSensitiveWords",
+			Handler:    _MsgExt_AddSensitiveWords_Handler,
+		},
+		{
+			MethodName: "DeleteSensitiveWords",
+			Handler:    _MsgExt_DeleteSensitiveWords_Handler,
+		},
+		{
+			MethodName: "SearchSensitiveWords",
+			Handler:    _MsgExt_SearchSensitiveWords_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
@@ -0,0 +1,201 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pushext
+
+import (
+	"errors"
+
+	"github.com/OpenIMSDK/protocol/sdkws"
+)
+
+// PushDeadLetter is an offline push given up after every retry failed.
+type PushDeadLetter struct {
+	TaskID         string   `json:"taskID"`
+	ConversationID string   `json:"conversationID"`
+	ClientMsgID    string   `json:"clientMsgID"`
+	UserIDs        []string `json:"userIDs"`
+	Title          string   `json:"title"`
+	Content        string   `json:"content"`
+	Attempts       int32    `json:"attempts"`
+	LastErr        string   `json:"lastErr"`
+	// CreateTime is when the first push failed and FailTime when it was given up, in milliseconds.
+	CreateTime int64 `json:"createTime"`
+	FailTime   int64 `json:"failTime"`
+}
+
+type GetPushDeadLettersReq struct {
+	Pagination *sdkws.RequestPagination `json:"pagination"`
+}
+
+func (x *GetPushDeadLettersReq) Check() error {
+	if x.Pagination == nil {
+		return errors.New("pagination is empty")
+	}
+	if x.Pagination.PageNumber < 1 {
+		return errors.New("pageNumber is invalid")
+	}
+	if x.Pagination.ShowNumber < 1 || x.Pagination.ShowNumber > 100 {
+		return errors.New("showNumber is invalid")
+	}
+	return nil
+}
+
+type GetPushDeadLettersResp struct {
+	Total       int64             `json:"total"`
+	DeadLetters []*PushDeadLetter `json:"deadLetters"`
+}
+
+// ReplayPushDeadLettersReq queues the dead letters for another round of retries.
+type ReplayPushDeadLettersReq struct {
+	TaskIDs []string `json:"taskIDs"`
+}
+
+func (x *ReplayPushDeadLettersReq) Check() error {
+	if len(x.TaskIDs) == 0 || len(x.TaskIDs) > 100 {
+		return errors.New("taskIDs is empty or more than 100")
+	}
+	return nil
+}
+
+type ReplayPushDeadLettersResp struct {
+	// TaskIDs that were found and queued.
+	TaskIDs []string `json:"taskIDs"`
+}
+
+type DeletePushDeadLettersReq struct {
+	TaskIDs []string `json:"taskIDs"`
+}
+
+func (x *DeletePushDeadLettersReq) Check() error {
+	if len(x.TaskIDs) == 0 || len(x.TaskIDs) > 100 {
+		return errors.New("taskIDs is empty or more than 100")
+	}
+	return nil
+}
+
+type DeletePushDeadLettersResp struct{}
+
+// PushTemplate is the offline push of ContentType in Locale, ContentType 0 is the push
+// of the users hiding the content. {senderNickname}, {groupName} and {preview} in Title
+// and Content are replaced with the message values.
+type PushTemplate struct {
+	ContentType int32  `json:"contentType"`
+	Locale      string `json:"locale"`
+	Title       string `json:"title"`
+	Content     string `json:"content"`
+	UpdateTime  int64  `json:"updateTime"`
+}
+
+type SetPushTemplatesReq struct {
+	Templates []*PushTemplate `json:"templates"`
+}
+
+func (x *SetPushTemplatesReq) Check() error {
+	if len(x.Templates) == 0 || len(x.Templates) > 100 {
+		return errors.New("templates is empty or more than 100")
+	}
+	for _, template := range x.Templates {
+		if template == nil {
+			return errors.New("template is nil")
+		}
+		if template.ContentType < 0 {
+			return errors.New("contentType is invalid")
+		}
+		if template.Locale == "" || len(template.Locale) > 32 {
+			return errors.New("locale is empty or longer than 32")
+		}
+		if template.Title == "" || len(template.Title) > 255 {
+			return errors.New("title is empty or longer than 255")
+		}
+		if len(template.Content) > 1024 {
+			return errors.New("content is longer than 1024")
+		}
+	}
+	return nil
+}
+
+type SetPushTemplatesResp struct{}
+
+type GetPushTemplatesReq struct{}
+
+type GetPushTemplatesResp struct {
+	Templates []*PushTemplate `json:"templates"`
+}
+
+type PushTemplateKey struct {
+	ContentType int32  `json:"contentType"`
+	Locale      string `json:"locale"`
+}
+
+type DeletePushTemplatesReq struct {
+	Keys []*PushTemplateKey `json:"keys"`
+}
+
+func (x *DeletePushTemplatesReq) Check() error {
+	if len(x.Keys) == 0 || len(x.Keys) > 100 {
+		return errors.New("keys is empty or more than 100")
+	}
+	for _, key := range x.Keys {
+		if key == nil || key.Locale == "" {
+			return errors.New("locale is empty")
+		}
+	}
+	return nil
+}
+
+type DeletePushTemplatesResp struct{}
+
+// UserPushSetting is how the offline pushes of a user are rendered.
+type UserPushSetting struct {
+	UserID string `json:"userID"`
+	// Locale such as zh-CN, empty for the locale of the latest registered device.
+	Locale string `json:"locale"`
+	// HideContent pushes the hidden template instead of the message.
+	HideContent bool `json:"hideContent"`
+}
+
+type SetUserPushSettingReq struct {
+	Setting *UserPushSetting `json:"setting"`
+}
+
+func (x *SetUserPushSettingReq) Check() error {
+	if x.Setting == nil {
+		return errors.New("setting is empty")
+	}
+	if x.Setting.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if len(x.Setting.Locale) > 32 {
+		return errors.New("locale is longer than 32")
+	}
+	return nil
+}
+
+type SetUserPushSettingResp struct{}
+
+type GetUserPushSettingReq struct {
+	UserID string `json:"userID"`
+}
+
+func (x *GetUserPushSettingReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	return nil
+}
+
+type GetUserPushSettingResp struct {
+	Setting *UserPushSetting `json:"setting"`
+}
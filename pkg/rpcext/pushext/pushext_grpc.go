@@ -0,0 +1,351 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pushext
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext"
+)
+
+const serviceName = "OpenIMServer.pushext.pushext"
+
+type PushExtClient interface {
+	GetPushDeadLetters(ctx context.Context, in *GetPushDeadLettersReq, opts ...grpc.CallOption) (*GetPushDeadLettersResp, error)
+	ReplayPushDeadLetters(ctx context.Context, in *ReplayPushDeadLettersReq, opts ...grpc.CallOption) (*ReplayPushDeadLettersResp, error)
+	DeletePushDeadLetters(ctx context.Context, in *DeletePushDeadLettersReq, opts ...grpc.CallOption) (*DeletePushDeadLettersResp, error)
+	SetPushTemplates(ctx context.Context, in *SetPushTemplatesReq, opts ...grpc.CallOption) (*SetPushTemplatesResp, error)
+	GetPushTemplates(ctx context.Context, in *GetPushTemplatesReq, opts ...grpc.CallOption) (*GetPushTemplatesResp, error)
+	DeletePushTemplates(ctx context.Context, in *DeletePushTemplatesReq, opts ...grpc.CallOption) (*DeletePushTemplatesResp, error)
+	SetUserPushSetting(ctx context.Context, in *SetUserPushSettingReq, opts ...grpc.CallOption) (*SetUserPushSettingResp, error)
+	GetUserPushSetting(ctx context.Context, in *GetUserPushSettingReq, opts ...grpc.CallOption) (*GetUserPushSettingResp, error)
+}
+
+type pushExtClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPushExtClient(cc grpc.ClientConnInterface) PushExtClient {
+	return &pushExtClient{cc}
+}
+
+func (c *pushExtClient) GetPushDeadLetters(ctx context.Context, in *GetPushDeadLettersReq, opts ...grpc.CallOption) (*GetPushDeadLettersResp, error) {
+	out := new(GetPushDeadLettersResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/GetPushDeadLetters", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushExtClient) ReplayPushDeadLetters(ctx context.Context, in *ReplayPushDeadLettersReq, opts ...grpc.CallOption) (*ReplayPushDeadLettersResp, error) {
+	out := new(ReplayPushDeadLettersResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/ReplayPushDeadLetters", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushExtClient) DeletePushDeadLetters(ctx context.Context, in *DeletePushDeadLettersReq, opts ...grpc.CallOption) (*DeletePushDeadLettersResp, error) {
+	out := new(DeletePushDeadLettersResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/DeletePushDeadLetters", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushExtClient) SetPushTemplates(ctx context.Context, in *SetPushTemplatesReq, opts ...grpc.CallOption) (*SetPushTemplatesResp, error) {
+	out := new(SetPushTemplatesResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/SetPushTemplates", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushExtClient) GetPushTemplates(ctx context.Context, in *GetPushTemplatesReq, opts ...grpc.CallOption) (*GetPushTemplatesResp, error) {
+	out := new(GetPushTemplatesResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/GetPushTemplates", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushExtClient) DeletePushTemplates(ctx context.Context, in *DeletePushTemplatesReq, opts ...grpc.CallOption) (*DeletePushTemplatesResp, error) {
+	out := new(DeletePushTemplatesResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/DeletePushTemplates", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushExtClient) SetUserPushSetting(ctx context.Context, in *SetUserPushSettingReq, opts ...grpc.CallOption) (*SetUserPushSettingResp, error) {
+	out := new(SetUserPushSettingResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/SetUserPushSetting", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushExtClient) GetUserPushSetting(ctx context.Context, in *GetUserPushSettingReq, opts ...grpc.CallOption) (*GetUserPushSettingResp, error) {
+	out := new(GetUserPushSettingResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/GetUserPushSetting", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type PushExtServer interface {
+	GetPushDeadLetters(context.Context, *GetPushDeadLettersReq) (*GetPushDeadLettersResp, error)
+	ReplayPushDeadLetters(context.Context, *ReplayPushDeadLettersReq) (*ReplayPushDeadLettersResp, error)
+	DeletePushDeadLetters(context.Context, *DeletePushDeadLettersReq) (*DeletePushDeadLettersResp, error)
+	SetPushTemplates(context.Context, *SetPushTemplatesReq) (*SetPushTemplatesResp, error)
+	GetPushTemplates(context.Context, *GetPushTemplatesReq) (*GetPushTemplatesResp, error)
+	DeletePushTemplates(context.Context, *DeletePushTemplatesReq) (*DeletePushTemplatesResp, error)
+	SetUserPushSetting(context.Context, *SetUserPushSettingReq) (*SetUserPushSettingResp, error)
+	GetUserPushSetting(context.Context, *GetUserPushSettingReq) (*GetUserPushSettingResp, error)
+}
+
+type UnimplementedPushExtServer struct{}
+
+func (*UnimplementedPushExtServer) GetPushDeadLetters(context.Context, *GetPushDeadLettersReq) (*GetPushDeadLettersResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPushDeadLetters not implemented")
+}
+
+func (*UnimplementedPushExtServer) ReplayPushDeadLetters(context.Context, *ReplayPushDeadLettersReq) (*ReplayPushDeadLettersResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplayPushDeadLetters not implemented")
+}
+
+func (*UnimplementedPushExtServer) DeletePushDeadLetters(context.Context, *DeletePushDeadLettersReq) (*DeletePushDeadLettersResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePushDeadLetters not implemented")
+}
+
+func (*UnimplementedPushExtServer) SetPushTemplates(context.Context, *SetPushTemplatesReq) (*SetPushTemplatesResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPushTemplates not implemented")
+}
+
+func (*UnimplementedPushExtServer) GetPushTemplates(context.Context, *GetPushTemplatesReq) (*GetPushTemplatesResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPushTemplates not implemented")
+}
+
+func (*UnimplementedPushExtServer) DeletePushTemplates(context.Context, *DeletePushTemplatesReq) (*DeletePushTemplatesResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePushTemplates not implemented")
+}
+
+func (*UnimplementedPushExtServer) SetUserPushSetting(context.Context, *SetUserPushSettingReq) (*SetUserPushSettingResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetUserPushSetting not implemented")
+}
+
+func (*UnimplementedPushExtServer) GetUserPushSetting(context.Context, *GetUserPushSettingReq) (*GetUserPushSettingResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserPushSetting not implemented")
+}
+
+func RegisterPushExtServer(s *grpc.Server, srv PushExtServer) {
+	s.RegisterService(&_PushExt_serviceDesc, srv)
+}
+
+func _PushExt_GetPushDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPushDeadLettersReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushExtServer).GetPushDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/GetPushDeadLetters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushExtServer).GetPushDeadLetters(ctx, req.(*GetPushDeadLettersReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushExt_ReplayPushDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayPushDeadLettersReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushExtServer).ReplayPushDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/ReplayPushDeadLetters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushExtServer).ReplayPushDeadLetters(ctx, req.(*ReplayPushDeadLettersReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushExt_DeletePushDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePushDeadLettersReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushExtServer).DeletePushDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/DeletePushDeadLetters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushExtServer).DeletePushDeadLetters(ctx, req.(*DeletePushDeadLettersReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushExt_SetPushTemplates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPushTemplatesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushExtServer).SetPushTemplates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/SetPushTemplates",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushExtServer).SetPushTemplates(ctx, req.(*SetPushTemplatesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushExt_GetPushTemplates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPushTemplatesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushExtServer).GetPushTemplates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/GetPushTemplates",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushExtServer).GetPushTemplates(ctx, req.(*GetPushTemplatesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushExt_DeletePushTemplates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePushTemplatesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushExtServer).DeletePushTemplates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/DeletePushTemplates",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushExtServer).DeletePushTemplates(ctx, req.(*DeletePushTemplatesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushExt_SetUserPushSetting_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetUserPushSettingReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushExtServer).SetUserPushSetting(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/SetUserPushSetting",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushExtServer).SetUserPushSetting(ctx, req.(*SetUserPushSettingReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushExt_GetUserPushSetting_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserPushSettingReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushExtServer).GetUserPushSetting(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/GetUserPushSetting",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushExtServer).GetUserPushSetting(ctx, req.(*GetUserPushSettingReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _PushExt_serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*PushExtServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPushDeadLetters",
+			Handler:    _PushExt_GetPushDeadLetters_Handler,
+		},
+		{
+			MethodName: "ReplayPushDeadLetters",
+			Handler:    _PushExt_ReplayPushDeadLetters_Handler,
+		},
+		{
+			MethodName: "DeletePushDeadLetters",
+			Handler:    _PushExt_DeletePushDeadLetters_Handler,
+		},
+		{
+			MethodName: "SetPushTemplates",
+			Handler:    _PushExt_SetPushTemplates_Handler,
+		},
+		{
+			MethodName: "GetPushTemplates",
+			Handler:    _PushExt_GetPushTemplates_Handler,
+		},
+		{
+			MethodName: "DeletePushTemplates",
+			Handler:    _PushExt_DeletePushTemplates_Handler,
+		},
+		{
+			MethodName: "SetUserPushSetting",
+			Handler:    _PushExt_SetUserPushSetting_Handler,
+		},
+		{
+			MethodName: "GetUserPushSetting",
+			Handler:    _PushExt_GetUserPushSetting_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
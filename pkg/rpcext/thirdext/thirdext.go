@@ -0,0 +1,84 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package thirdext
+
+import "errors"
+
+// ApnsUpdateTokenReq stores the APNs device token of an iOS client, like FcmUpdateToken does for FCM.
+type ApnsUpdateTokenReq struct {
+	PlatformID int32  `json:"platformID"`
+	ApnsToken  string `json:"apnsToken"`
+	Account    string `json:"account"`
+	// ExpireTime seconds the token is kept.
+	ExpireTime int64 `json:"expireTime"`
+}
+
+func (x *ApnsUpdateTokenReq) Check() error {
+	if x.Account == "" {
+		return errors.New("account is empty")
+	}
+	if x.ApnsToken == "" {
+		return errors.New("apnsToken is empty")
+	}
+	return nil
+}
+
+type ApnsUpdateTokenResp struct{}
+
+// RegisterPushDeviceReq registers a device for offline push through Provider,
+// the token moves to UserID if another user registered it before.
+type RegisterPushDeviceReq struct {
+	UserID     string `json:"userID"`
+	PlatformID int32  `json:"platformID"`
+	// Provider is apns, fcm, getui, jpush, webhook or the name of a vendor channel bridged by the webhook.
+	Provider   string `json:"provider"`
+	Token      string `json:"token"`
+	AppVersion string `json:"appVersion"`
+	// Locale such as zh-CN, used to localize the pushes of the device.
+	Locale string `json:"locale"`
+}
+
+func (x *RegisterPushDeviceReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.Provider == "" || len(x.Provider) > 32 {
+		return errors.New("provider is empty or longer than 32")
+	}
+	if x.Token == "" || len(x.Token) > 255 {
+		return errors.New("token is empty or longer than 255")
+	}
+	return nil
+}
+
+type RegisterPushDeviceResp struct{}
+
+type UnregisterPushDeviceReq struct {
+	UserID   string `json:"userID"`
+	Provider string `json:"provider"`
+	Token    string `json:"token"`
+}
+
+func (x *UnregisterPushDeviceReq) Check() error {
+	if x.UserID == "" {
+		return errors.New("userID is empty")
+	}
+	if x.Provider == "" || x.Token == "" {
+		return errors.New("provider or token is empty")
+	}
+	return nil
+}
+
+type UnregisterPushDeviceResp struct{}
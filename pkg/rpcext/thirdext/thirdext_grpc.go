@@ -0,0 +1,166 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package thirdext
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext"
+)
+
+const serviceName = "OpenIMServer.thirdext.thirdext"
+
+type ThirdExtClient interface {
+	ApnsUpdateToken(ctx context.Context, in *ApnsUpdateTokenReq, opts ...grpc.CallOption) (*ApnsUpdateTokenResp, error)
+	RegisterPushDevice(ctx context.Context, in *RegisterPushDeviceReq, opts ...grpc.CallOption) (*RegisterPushDeviceResp, error)
+	UnregisterPushDevice(ctx context.Context, in *UnregisterPushDeviceReq, opts ...grpc.CallOption) (*UnregisterPushDeviceResp, error)
+}
+
+type thirdExtClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewThirdExtClient(cc grpc.ClientConnInterface) ThirdExtClient {
+	return &thirdExtClient{cc}
+}
+
+func (c *thirdExtClient) ApnsUpdateToken(ctx context.Context, in *ApnsUpdateTokenReq, opts ...grpc.CallOption) (*ApnsUpdateTokenResp, error) {
+	out := new(ApnsUpdateTokenResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/ApnsUpdateToken", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thirdExtClient) RegisterPushDevice(ctx context.Context, in *RegisterPushDeviceReq, opts ...grpc.CallOption) (*RegisterPushDeviceResp, error) {
+	out := new(RegisterPushDeviceResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/RegisterPushDevice", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thirdExtClient) UnregisterPushDevice(ctx context.Context, in *UnregisterPushDeviceReq, opts ...grpc.CallOption) (*UnregisterPushDeviceResp, error) {
+	out := new(UnregisterPushDeviceResp)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/UnregisterPushDevice", in, out, rpcext.CallOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type ThirdExtServer interface {
+	ApnsUpdateToken(context.Context, *ApnsUpdateTokenReq) (*ApnsUpdateTokenResp, error)
+	RegisterPushDevice(context.Context, *RegisterPushDeviceReq) (*RegisterPushDeviceResp, error)
+	UnregisterPushDevice(context.Context, *UnregisterPushDeviceReq) (*UnregisterPushDeviceResp, error)
+}
+
+type UnimplementedThirdExtServer struct{}
+
+func (*UnimplementedThirdExtServer) ApnsUpdateToken(context.Context, *ApnsUpdateTokenReq) (*ApnsUpdateTokenResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApnsUpdateToken not implemented")
+}
+
+func (*UnimplementedThirdExtServer) RegisterPushDevice(context.Context, *RegisterPushDeviceReq) (*RegisterPushDeviceResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterPushDevice not implemented")
+}
+
+func (*UnimplementedThirdExtServer) UnregisterPushDevice(context.Context, *UnregisterPushDeviceReq) (*UnregisterPushDeviceResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnregisterPushDevice not implemented")
+}
+
+func RegisterThirdExtServer(s *grpc.Server, srv ThirdExtServer) {
+	s.RegisterService(&_ThirdExt_serviceDesc, srv)
+}
+
+func _ThirdExt_ApnsUpdateToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApnsUpdateTokenReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThirdExtServer).ApnsUpdateToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/ApnsUpdateToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThirdExtServer).ApnsUpdateToken(ctx, req.(*ApnsUpdateTokenReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThirdExt_RegisterPushDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterPushDeviceReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThirdExtServer).RegisterPushDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/RegisterPushDevice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThirdExtServer).RegisterPushDevice(ctx, req.(*RegisterPushDeviceReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThirdExt_UnregisterPushDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnregisterPushDeviceReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThirdExtServer).UnregisterPushDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/UnregisterPushDevice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThirdExtServer).UnregisterPushDevice(ctx, req.(*UnregisterPushDeviceReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _ThirdExt_serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*ThirdExtServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ApnsUpdateToken",
+			Handler:    _ThirdExt_ApnsUpdateToken_Handler,
+		},
+		{
+			MethodName: "RegisterPushDevice",
+			Handler:    _ThirdExt_RegisterPushDevice_Handler,
+		},
+		{
+			MethodName: "UnregisterPushDevice",
+			Handler:    _ThirdExt_UnregisterPushDevice_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
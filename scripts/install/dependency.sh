@@ -78,7 +78,7 @@ docker run -d \
     -e TZ=Asia/Shanghai \
     -e KAFKA_BROKER_ID=0 \
     -e KAFKA_ZOOKEEPER_CONNECT=zookeeper:2181 \
-    -e KAFKA_CREATE_TOPICS="latestMsgToRedis:8:1,msgToPush:8:1,offlineMsgToMongoMysql:8:1" \
+    -e KAFKA_CREATE_TOPICS="latestMsgToRedis:8:1,msgToPush:8:1,offlineMsgToMongoMysql:8:1,msgToModify:8:1" \
     -e KAFKA_ADVERTISED_LISTENERS="INSIDE://127.0.0.1:9092,OUTSIDE://103.116.45.174:9092" \
     -e KAFKA_LISTENERS="INSIDE://:9092,OUTSIDE://:9093" \
     -e KAFKA_LISTENER_SECURITY_PROTOCOL_MAP="INSIDE:PLAINTEXT,OUTSIDE:PLAINTEXT" \
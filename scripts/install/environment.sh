@@ -140,6 +140,8 @@ def "OPENIM_CHAT_NAME" "chat"                      # openim-chat chat用户名
 
 # TODO 注意： 一般的配置都可以使用 def 函数来定义，如果是包含特殊字符，比如说:
 # TODO readonly MSG_DESTRUCT_TIME=${MSG_DESTRUCT_TIME:-'0 2 * * *'}
+# 定时消息投递时间
+readonly SCHEDULED_MSG_DISPATCH_TIME=${SCHEDULED_MSG_DISPATCH_TIME:-'@every 10s'}
 # TODO 使用 readonly 来定义合适，负责无法正常解析, 并且 yaml 模板需要加 "" 来包裹
 
 ###################### Zookeeper 配置信息 ######################
@@ -212,10 +214,12 @@ def "KAFKA_ADDRESS" "${DOCKER_BRIDGE_GATEWAY}"              # `Kafka` 的地址
 def "KAFKA_LATESTMSG_REDIS_TOPIC" "latestMsgToRedis"        # `Kafka` 的最新消息到Redis的主题
 def "KAFKA_OFFLINEMSG_MONGO_TOPIC" "offlineMsgToMongoMysql" # `Kafka` 的离线消息到Mongo的主题
 def "KAFKA_MSG_PUSH_TOPIC" "msgToPush"                      # `Kafka` 的消息到推送的主题
+def "KAFKA_MSG_MODIFY_TOPIC" "msgToModify"                  # `Kafka` 的消息修改(回应)的主题
 def "KAFKA_CONSUMERGROUPID_REDIS" "redis"                   # `Kafka` 的消费组ID到Redis
 def "KAFKA_CONSUMERGROUPID_MONGO" "mongo"                   # `Kafka` 的消费组ID到Mongo
 def "KAFKA_CONSUMERGROUPID_MYSQL" "mysql"                   # `Kafka` 的消费组ID到MySql
 def "KAFKA_CONSUMERGROUPID_PUSH" "push"                     # `Kafka` 的消费组ID到推送
+def "KAFKA_CONSUMERGROUPID_MODIFY" "modify"                 # `Kafka` 的消费组ID到消息修改
 
 ###################### openim-web 配置信息 ######################
 def "OPENIM_WEB_PORT" "11001"                       # openim-web的端口
@@ -282,6 +286,18 @@ def "LOG_WITH_STACK" "false"                        # 日志是否带有堆栈
 def "WEBSOCKET_MAX_CONN_NUM" "100000" # Websocket最大连接数
 def "WEBSOCKET_MAX_MSG_LEN" "4096"    # Websocket最大消息长度
 def "WEBSOCKET_TIMEOUT" "10"          # Websocket超时
+def "WEBSOCKET_COMPRESS_THRESHOLD" "256" # 小于该长度的消息不压缩
+def "WEBSOCKET_PER_MESSAGE_DEFLATE" "false" # 是否开启permessage-deflate
+def "WEBSOCKET_RESUME_GRACE_PERIOD" "10" # 断线重连会话保留时间(秒)
+def "WEBSOCKET_RESUME_BUFFER_SIZE" "128" # 断线期间缓存的推送条数
+def "WEBSOCKET_PING_INTERVAL" "25" # 服务端ping间隔(秒)
+def "WEBSOCKET_DRAIN_BATCH_SIZE" "500" # 下线时每批迁移的连接数
+def "WEBSOCKET_DRAIN_BATCH_INTERVAL" "1000" # 下线时每批迁移的间隔(毫秒)
+def "WEBSOCKET_DRAIN_TIMEOUT" "60" # 下线等待超时(秒)
+def "WEBSOCKET_USER_ROUTE_TTL" "60" # 用户所在节点路由表过期时间(秒)，0为广播推送
+def "WEBSOCKET_RATE_LIMIT_ENABLE" "false" # 是否开启长连接请求限流
+def "WEBSOCKET_EPHEMERAL_RATE" "2" # 每个会话每秒临时信令数
+def "WEBSOCKET_EPHEMERAL_BURST" "5" # 临时信令突发数
 def "PUSH_ENABLE" "getui"             # 推送是否启用
 # GeTui推送URL
 readonly GETUI_PUSH_URL=${GETUI_PUSH_URL:-'https://restapi.getui.com/v2/$appId'}
@@ -290,6 +306,27 @@ def "JPNS_APP_KEY"                    # JPNS应用密钥
 def "JPNS_MASTER_SECRET"              # JPNS主密钥
 def "JPNS_PUSH_URL"                   # JPNS推送URL
 def "JPNS_PUSH_INTENT"                # JPNS推送意图
+def "PUSH_ROUTING_PROVIDERS" "apns, fcm" # 按设备路由时启用的推送渠道
+def "PUSH_ROUTING_FALLBACK"           # 未注册设备的用户使用的推送渠道
+def "PUSH_WEBHOOK_URL"                # 推送webhook地址
+def "PUSH_WEBHOOK_SECRET"             # 推送webhook签名密钥
+def "PUSH_WEBHOOK_TIMEOUT" "5"        # 推送webhook超时(秒)
+def "PUSH_WEBHOOK_BATCH_SIZE" "500"   # 推送webhook每批用户数
+def "PUSH_WEBHOOK_RETRY_TIMES" "3"    # 推送webhook重试次数
+def "PUSH_WEBHOOK_RETRY_INTERVAL" "500" # 推送webhook重试间隔(毫秒)
+def "PUSH_RETRY_ENABLE" "true"        # 是否重试失败的离线推送
+def "PUSH_RETRY_MAX_ATTEMPTS" "5"     # 离线推送最大尝试次数，超过后进入死信
+def "PUSH_RETRY_BACKOFF" "5"          # 离线推送首次重试间隔(秒)
+def "PUSH_RETRY_MAX_BACKOFF" "300"    # 离线推送最大重试间隔(秒)
+def "PUSH_RETRY_DEAD_LETTER_MAX" "10000" # 保留的离线推送死信数量上限
+def "PUSH_RETRY_DEAD_LETTER_EXPIRE" "604800" # 离线推送死信保留时间(秒)
+def "PUSH_TEMPLATE_ENABLE" "false"    # 是否使用推送模板和用户语言
+def "PUSH_TEMPLATE_DEFAULT_LOCALE" "en" # 推送模板默认语言
+def "PUSH_TEMPLATE_RELOAD_INTERVAL" "10" # 推送模板重新加载间隔(秒)
+def "APNS_KEY_FILE" "AuthKey.p8"      # APNs签名密钥文件(.p8)
+def "APNS_KEY_ID"                     # APNs密钥ID
+def "APNS_TEAM_ID"                    # APNs团队ID
+def "APNS_BUNDLE_ID"                  # APNs应用Bundle ID
 def "MANAGER_USERID_1" "openIM123456" # 管理员ID 1
 def "MANAGER_USERID_2" "openIM654321" # 管理员ID 2
 def "MANAGER_USERID_3" "openIMAdmin"  # 管理员ID 3